@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/jsonschemastub"
+)
+
+// arraySize is an --array-size value, pattern=min:max.
+type arraySize struct {
+	pattern  string
+	min, max int
+}
+
+// arraySizeFlag collects --array-size values, rejecting malformed ones as the
+// flags are parsed. It implements pflag.SliceValue.
+type arraySizeFlag struct {
+	sizes []arraySize
+}
+
+var arraySizes arraySizeFlag
+
+func parseArraySize(s string) (arraySize, error) {
+	pattern, bounds, ok := strings.Cut(s, "=")
+	lo, hi, hasMax := strings.Cut(bounds, ":")
+	min, minErr := strconv.Atoi(lo)
+	max, maxErr := strconv.Atoi(hi)
+	if !ok || pattern == "" || !hasMax || minErr != nil || maxErr != nil {
+		return arraySize{}, fmt.Errorf("invalid array size %q (want pattern=min:max)", s)
+	}
+	return arraySize{pattern: pattern, min: min, max: max}, nil
+}
+
+// String is empty without sizes, so --help shows no default.
+func (f *arraySizeFlag) String() string {
+	if len(f.sizes) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(f.GetSlice(), ",") + "]"
+}
+
+func (f *arraySizeFlag) Set(s string) error {
+	return f.Append(s)
+}
+
+func (f *arraySizeFlag) Type() string {
+	return "stringArray"
+}
+
+func (f *arraySizeFlag) Append(s string) error {
+	size, err := parseArraySize(s)
+	if err != nil {
+		return err
+	}
+	f.sizes = append(f.sizes, size)
+	return nil
+}
+
+func (f *arraySizeFlag) Replace(values []string) error {
+	sizes := make([]arraySize, 0, len(values))
+	for _, s := range values {
+		size, err := parseArraySize(s)
+		if err != nil {
+			return err
+		}
+		sizes = append(sizes, size)
+	}
+	f.sizes = sizes
+	return nil
+}
+
+func (f *arraySizeFlag) GetSlice() []string {
+	values := make([]string, len(f.sizes))
+	for i, size := range f.sizes {
+		values[i] = fmt.Sprintf("%s=%d:%d", size.pattern, size.min, size.max)
+	}
+	return values
+}
+
+// options returns a WithSemanticArraySizes option holding every size, or
+// none when there are none.
+func (f *arraySizeFlag) options() []jsonschemastub.GenOption {
+	if len(f.sizes) == 0 {
+		return nil
+	}
+	rules := make(map[string][2]int, len(f.sizes))
+	for _, size := range f.sizes {
+		rules[size.pattern] = [2]int{size.min, size.max}
+	}
+	return []jsonschemastub.GenOption{jsonschemastub.WithSemanticArraySizes(rules)}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/jsonschemastub"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/stubdiff"
+	"github.com/spf13/cobra"
+)
+
+// checkSeed is check-stubs' own --seed, as its default of 1 differs from the
+// other commands'.
+var checkSeed int64
+
+var checkStubsCmd = &cobra.Command{
+	Use:   "check-stubs query.graphql stub.json",
+	Short: "Check that a committed stub still matches the shape of its query",
+	Long: `Generate a fresh stub from the query and compare it structurally against the
+committed stub. Values are ignored; only keys and JSON types are compared.
+Exits with a non-zero status and lists the differences when they diverge.`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE:         runCheckStubs,
+}
+
+func init() {
+	checkStubsCmd.Flags().StringVar(&overridesFile, "overrides", "", "path to overrides JSON file")
+	checkStubsCmd.Flags().Int64Var(&checkSeed, "seed", 1, "seed used to generate the fresh stub")
+	rootCmd.AddCommand(checkStubsCmd)
+}
+
+func runCheckStubs(cmd *cobra.Command, args []string) error {
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+
+	query, err := os.ReadFile(filepath.Clean(args[0]))
+	if err != nil {
+		return err
+	}
+	schema, err := graphqlschema.BuildSchema(string(query), overrides)
+	if err != nil {
+		return err
+	}
+	fresh, err := jsonschemastub.NewGenerator(jsonschemastub.WithSeed(checkSeed)).Generate(schema)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Clean(args[1]))
+	if err != nil {
+		return err
+	}
+	var committed any
+	if err := json.Unmarshal(data, &committed); err != nil {
+		return fmt.Errorf("parsing stub: %w", err)
+	}
+
+	diffs := stubdiff.Compare(committed, fresh)
+	if len(diffs) == 0 {
+		return nil
+	}
+	for _, d := range diffs {
+		fmt.Fprintln(cmd.ErrOrStderr(), d)
+	}
+	return fmt.Errorf("%s no longer matches %s (%d differences)", args[1], args[0], len(diffs))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// pathFlags are flags holding file paths. Relative paths in a config file are
+// resolved against the directory containing it.
+var pathFlags = map[string]bool{
+	"overrides":          true,
+	"graphql-schema":     true,
+	"introspection-file": true,
+	"base-schema":        true,
+	"operation-aliases":  true,
+	"dictionary":         true,
+	"record":             true,
+	"replay":             true,
+	"template":           true,
+	"template-out":       true,
+	"schema-out":         true,
+	"output":             true,
+	"dir":                true,
+	"out-dir":            true,
+	"history-file":       true,
+	"lock-file":          true,
+	"save-manifest":      true,
+	"schema-cache-dir":   true,
+}
+
+func init() {
+	rootCmd.PersistentPreRunE = applyConfig
+}
+
+// applyConfig fills in flags not given on the command line from the nearest
+// .graphqlstubrc file. Keys for flags the command does not have are ignored,
+// so one file can serve every subcommand.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil || cfg == nil {
+		return err
+	}
+	for name, value := range cfg.Values {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		// Repeatable flags take a list, set one element at a time.
+		values, ok := value.([]any)
+		if !ok {
+			values = []any{value}
+		}
+		for _, v := range values {
+			s := configValue(v)
+			if pathFlags[name] && !filepath.IsAbs(s) {
+				s = filepath.Join(cfg.Dir(), s)
+			}
+			if err := flag.Value.Set(s); err != nil {
+				return fmt.Errorf("%s: invalid value for %q: %w", cfg.Path, name, err)
+			}
+		}
+		// Mark the flag as set so Changed checks, such as the one deciding
+		// whether to seed, treat it like a command-line value.
+		flag.Changed = true
+	}
+	return nil
+}
+
+// configValue formats a decoded config value as a flag argument.
+func configValue(v any) string {
+	if f, ok := v.(float64); ok {
+		// JSON numbers decode as float64; avoid exponent notation for large
+		// seeds.
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/docgen"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/spf13/cobra"
+)
+
+var docsFormat string
+
+var docsCmd = &cobra.Command{
+	Use:   "docs [query.graphql]",
+	Short: "Document the response schema of a GraphQL query",
+	Long: `Build the JSON Schema for the query and describe each field's path, type
+and overrides as a Markdown or HTML table, for including in a project wiki.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDocs,
+}
+
+func init() {
+	docsCmd.Flags().StringVar(&overridesFile, "overrides", "", "path to overrides JSON file")
+	docsCmd.Flags().StringArrayVar(&graphqlSchemas, "graphql-schema", nil, "path to a GraphQL SDL file to resolve field types from (repeatable; files are merged)")
+	docsCmd.Flags().StringVar(&graphqlSchemaEnv, "graphql-schema-env", "", "name of an environment variable holding the GraphQL SDL")
+	docsCmd.Flags().StringVar(&docsFormat, "format", "markdown", "document format (markdown, html)")
+	rootCmd.AddCommand(docsCmd)
+}
+
+func runDocs(cmd *cobra.Command, args []string) error {
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+
+	query, err := readInput(args)
+	if err != nil {
+		return err
+	}
+
+	// The docs note which types were overridden.
+	schema, err := buildSchema(cmd, string(query), overrides, graphqlschema.WithOverrideMarkers())
+	if err != nil {
+		return err
+	}
+
+	doc, err := docgen.Generate(schema, docsFormat)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), doc)
+	return nil
+}
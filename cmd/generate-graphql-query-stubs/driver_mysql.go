@@ -0,0 +1,6 @@
+//go:build mysql
+
+package main
+
+// The MySQL driver is only compiled in with -tags mysql.
+import _ "github.com/go-sql-driver/mysql"
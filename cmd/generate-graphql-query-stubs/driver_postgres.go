@@ -0,0 +1,6 @@
+//go:build postgres
+
+package main
+
+// The PostgreSQL driver is only compiled in with -tags postgres.
+import _ "github.com/lib/pq"
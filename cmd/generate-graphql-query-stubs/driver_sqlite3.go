@@ -0,0 +1,6 @@
+//go:build sqlite3
+
+package main
+
+// The SQLite driver needs cgo, so it is only compiled in with -tags sqlite3.
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/globwalk"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/jsonschemastub"
+	"github.com/spf13/cobra"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var (
+	schemaOut  string
+	outputFile string
+	quiet      bool
+
+	batchDir     string
+	globs        []string
+	excludeGlobs []string
+	outDir       string
+
+	subscriptionEvents int
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate [query.graphql]",
+	Short: "Generate stub data directly from a GraphQL query",
+	Long: `Run the full pipeline in one step: build the JSON Schema for the query and
+generate a stub from it. Use --schema-out to keep the intermediate schema.
+
+With --dir, every query file under the directory matching --glob is
+processed instead, and each stub is written next to its query (or under
+--out-dir) with a .json extension. "**" in a pattern matches any number of
+nested directories.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGenerate,
+}
+
+func init() {
+	generateCmd.Flags().StringVar(&overridesFile, "overrides", "", "path to overrides JSON file")
+	generateCmd.Flags().BoolVar(&strictOverrides, "strict-overrides", false, "fail when an override key matches no field of the query")
+	generateCmd.Flags().StringVar(&operationAliases, "operation-aliases", "", "path to a JSON file of per-operation field type overrides, {\"GetPokemon\": {\"id\": \"integer\"}}")
+	generateCmd.Flags().StringArrayVar(&graphqlSchemas, "graphql-schema", nil, "path to a GraphQL SDL file to resolve field types from (repeatable; files are merged)")
+	generateCmd.Flags().StringVar(&graphqlSchemaEnv, "graphql-schema-env", "", "name of an environment variable holding the GraphQL SDL")
+	generateCmd.Flags().StringVar(&introspectionFile, "introspection-file", "", "path to a GraphQL introspection query response to resolve field types from")
+	generateCmd.Flags().Int64Var(&seed, "seed", 0, "seed for reproducible output (random when unset)")
+	generateCmd.Flags().BoolVar(&mutationInput, "mutation-input", false, "also generate a mutation's variables under \"input\"")
+	generateCmd.Flags().BoolVar(&firstEnum, "first-enum", false, "always generate the first value of each enum, for stable snapshots")
+	generateCmd.Flags().BoolVar(&sequentialInts, "sequential-integers", false, "generate each integer field as 1, 2, 3, ... across stubs instead of randomly")
+	generateCmd.Flags().BoolVar(&uniqueIDs, "unique-ids", false, "keep id and *_id fields unique among the items of each array")
+	generateCmd.Flags().BoolVar(&typenameVariants, "typename-discriminator", false, "generate one inline fragment variant of interface and union fields, with its __typename")
+	generateCmd.Flags().BoolVar(&useDefaults, "use-defaults", false, "generate a field's \"default\", such as a variable's default value, when it has one")
+	generateCmd.Flags().Var(&referenceCSVs, "reference-csv", "take a field's values from a CSV column, as path=file.csv:column (repeatable)")
+	generateCmd.Flags().BoolVar(&shuffleValues, "shuffle-values", false, "shuffle each x-stub-values list before cycling through it")
+	generateCmd.Flags().BoolVar(&nameAware, "name-aware", false, "pick string formats from field names, e.g. date-times for *_at fields")
+	generateCmd.Flags().IntVar(&subscriptionEvents, "subscription-events", 0, "for subscriptions, generate this many events as a JSON array")
+	generateCmd.Flags().BoolVar(&operationMetadata, "metadata", false, "with --schema-out, describe the operation at the schema root with x-operation-* keys, x-total-fields and, unless --seed is given, x-schema-generated-at")
+	generateCmd.Flags().StringVar(&schemaOut, "schema-out", "", "also write the intermediate JSON Schema to this file")
+	generateCmd.Flags().StringVarP(&outputFile, "output", "o", "", "write the stub to this file instead of stdout")
+	generateCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress all non-error output")
+	generateCmd.Flags().StringVar(&batchDir, "dir", "", "generate stubs for every query file under this directory")
+	generateCmd.Flags().StringArrayVar(&globs, "glob", []string{"**/*.graphql"}, "pattern selecting query files under --dir (repeatable)")
+	generateCmd.Flags().StringArrayVar(&excludeGlobs, "exclude-glob", nil, "pattern of query files under --dir to skip (repeatable)")
+	generateCmd.Flags().StringVar(&outDir, "out-dir", "", "write --dir stubs under this directory instead of next to each query")
+	rootCmd.AddCommand(generateCmd)
+}
+
+func runGenerate(cmd *cobra.Command, args []string) error {
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+
+	if saveManifest != "" {
+		if err := prepareManifest(cmd, args); err != nil {
+			return err
+		}
+	}
+
+	if batchDir != "" {
+		if len(args) > 0 || outputFile != "" {
+			return fmt.Errorf("--dir cannot be combined with a query argument or --output")
+		}
+		return runGenerateDir(cmd, overrides)
+	}
+
+	query, err := readInput(args)
+	if err != nil {
+		return err
+	}
+
+	schema, err := buildSchema(cmd, string(query), overrides)
+	if err != nil {
+		return err
+	}
+
+	// Write the schema before generating so it survives a failed generation.
+	if schemaOut != "" {
+		if err := writeJSON(schemaOut, schema); err != nil {
+			return fmt.Errorf("writing schema: %w", err)
+		}
+	}
+
+	stub, err := generateStub(cmd, string(query), schema)
+	if err != nil {
+		return err
+	}
+	if saveManifest != "" {
+		if err := writeManifest(args[0]); err != nil {
+			return fmt.Errorf("writing manifest: %w", err)
+		}
+	}
+
+	if outputFile != "" {
+		return writeJSON(outputFile, stub)
+	}
+	if quiet {
+		return nil
+	}
+	out, _ := json.MarshalIndent(stub, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
+// runGenerateDir writes a stub for every query file selected by --dir, --glob
+// and --exclude-glob. Each file gets a fresh generator, so a seeded stub does
+// not change when other files are added.
+func runGenerateDir(cmd *cobra.Command, overrides map[string]string) error {
+	files, err := globwalk.Walk(batchDir, globs, excludeGlobs)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		query, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		query = stripBOM(query)
+		schema, err := buildSchema(cmd, string(query), overrides)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		stub, err := generateStub(cmd, string(query), schema)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		out := strings.TrimSuffix(file, filepath.Ext(file)) + ".json"
+		if outDir != "" {
+			rel, err := filepath.Rel(batchDir, out)
+			if err != nil {
+				return err
+			}
+			out = filepath.Join(outDir, rel)
+			if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+				return err
+			}
+		}
+		if err := writeJSON(out, stub); err != nil {
+			return fmt.Errorf("writing %s: %w", out, err)
+		}
+		if !quiet {
+			fmt.Fprintln(cmd.OutOrStdout(), out)
+		}
+	}
+	return nil
+}
+
+// generateStub generates a stub for query from its schema. With
+// --subscription-events, a subscription instead yields that many events, each
+// a complete stub generated from its own seed so they vary like a real stream.
+func generateStub(cmd *cobra.Command, query string, schema map[string]any) (any, error) {
+	events := 0
+	if subscriptionEvents > 0 {
+		op, err := graphqlschema.OperationType(query, "")
+		if err != nil {
+			return nil, err
+		}
+		if op == string(ast.Subscription) {
+			events = subscriptionEvents
+		}
+	}
+	if events == 0 {
+		return generateOne(cmd, schema, generatorOptions(cmd))
+	}
+
+	stubs := make([]any, events)
+	for i := range stubs {
+		opts := generatorOptions(cmd)
+		if cmd.Flags().Changed("seed") {
+			opts = append(opts, jsonschemastub.WithSeed(seed+int64(i)))
+		}
+		stub, err := generateOne(cmd, schema, opts)
+		if err != nil {
+			return nil, err
+		}
+		stubs[i] = stub
+	}
+	return stubs, nil
+}
+
+func generateOne(cmd *cobra.Command, schema map[string]any, opts []jsonschemastub.GenOption) (any, error) {
+	g := jsonschemastub.NewGenerator(opts...)
+	stub, err := g.Generate(schema)
+	if err != nil {
+		return nil, err
+	}
+	printWarnings(cmd, g.Warnings())
+	return stub, nil
+}
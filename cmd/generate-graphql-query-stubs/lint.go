@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suppressRules   []string
+	requireTypename bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [query.graphql]",
+	Short: "Check a GraphQL query for likely mistakes",
+	Long: `Report likely mistakes in the query, one per line with its line number, rule
+ID and field path:
+
+  OVER_FETCH       (warning) an object selects more than 20 direct fields
+  EMPTY_LIST       (warning) a list of objects selects no sub-fields; only
+                             checked with --graphql-schema
+  DUPLICATE_FIELD  (error)   a field is selected twice at the same level
+  TYPENAME         (info)    __typename is selected
+  MISSING_TYPENAME (warning) an object does not select __typename; only
+                             checked with --require-typename
+
+Exits with a non-zero status when any error is found. Rules can be turned off
+with --suppress-rule.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringArrayVar(&suppressRules, "suppress-rule", nil, "rule ID to skip, e.g. TYPENAME (repeatable)")
+	lintCmd.Flags().StringArrayVar(&graphqlSchemas, "graphql-schema", nil, "path to a GraphQL SDL file to tell list fields by their types (repeatable; files are merged)")
+	lintCmd.Flags().BoolVar(&requireTypename, "require-typename", false, "warn about objects that do not select __typename, as Apollo Client's cache needs")
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	query, err := readInput(args)
+	if err != nil {
+		return err
+	}
+	var opts []graphqlschema.LintOption
+	if requireTypename {
+		opts = append(opts, graphqlschema.WithRequiredTypename())
+	}
+	if len(graphqlSchemas) > 0 {
+		var sdl strings.Builder
+		for _, path := range graphqlSchemas {
+			data, err := os.ReadFile(filepath.Clean(path))
+			if err != nil {
+				return fmt.Errorf("reading GraphQL schema: %w", err)
+			}
+			sdl.Write(data)
+			sdl.WriteByte('\n')
+		}
+		opts = append(opts, graphqlschema.WithLintSDL(sdl.String()))
+	}
+	findings, err := graphqlschema.LintQuery(string(query), suppressRules, opts...)
+	if err != nil {
+		return err
+	}
+	errs := 0
+	for _, f := range findings {
+		fmt.Fprintln(cmd.OutOrStdout(), f)
+		if f.Severity == graphqlschema.SeverityError {
+			errs++
+		}
+	}
+	switch {
+	case errs == 1:
+		return fmt.Errorf("lint found 1 error")
+	case errs > 1:
+		return fmt.Errorf("lint found %d errors", errs)
+	}
+	return nil
+}
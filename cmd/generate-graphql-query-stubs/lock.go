@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/jsonschemastub"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/lockfile"
+	"github.com/spf13/cobra"
+)
+
+var lockFile string
+
+var lockCmd = &cobra.Command{
+	Use:   "lock query.graphql...",
+	Short: "Record queries, overrides and seed in a lock file",
+	Long: `Write a lock file recording the query files, their fingerprints, the
+overrides and GraphQL schema files, and the generator seed. A random seed is
+chosen and recorded when --seed is not given. Run regen to generate the stubs.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runLock,
+}
+
+var regenCmd = &cobra.Command{
+	Use:   "regen",
+	Short: "Regenerate the stubs recorded in a lock file",
+	Long: `Regenerate a stub next to every query recorded in the lock file, using the
+recorded overrides, GraphQL schema files and seed. Fails without writing
+anything when a query has changed since it was locked.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runRegen,
+}
+
+func init() {
+	lockCmd.Flags().StringVar(&overridesFile, "overrides", "", "path to overrides JSON file")
+	lockCmd.Flags().StringArrayVar(&graphqlSchemas, "graphql-schema", nil, "path to a GraphQL SDL file to resolve field types from (repeatable; files are merged)")
+	lockCmd.Flags().Int64Var(&seed, "seed", 0, "seed to record (random when unset)")
+	lockCmd.Flags().StringVar(&lockFile, "lock-file", lockfile.FileName, "path to the lock file")
+	regenCmd.Flags().StringVar(&lockFile, "lock-file", lockfile.FileName, "path to the lock file")
+	rootCmd.AddCommand(lockCmd, regenCmd)
+}
+
+func runLock(cmd *cobra.Command, args []string) error {
+	dir := filepath.Dir(lockFile)
+	lock := &lockfile.Lock{Seed: seed}
+	if !cmd.Flags().Changed("seed") {
+		lock.Seed = rand.Int63()
+	}
+
+	var err error
+	if overridesFile != "" {
+		if lock.Overrides, err = lockfile.Rel(dir, overridesFile); err != nil {
+			return err
+		}
+	}
+	for _, path := range graphqlSchemas {
+		rel, err := lockfile.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		lock.GraphQLSchemas = append(lock.GraphQLSchemas, rel)
+	}
+	for _, path := range args {
+		query, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		fingerprint, err := graphqlschema.Fingerprint(string(query))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		rel, err := lockfile.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		lock.Queries = append(lock.Queries, lockfile.Query{Path: rel, Fingerprint: fingerprint})
+	}
+	return lockfile.Save(lockFile, lock)
+}
+
+func runRegen(cmd *cobra.Command, args []string) error {
+	lock, err := lockfile.Load(lockFile)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(lockFile)
+
+	// Check every fingerprint before writing, so a changed query leaves all
+	// stubs untouched.
+	queries := make([]string, len(lock.Queries))
+	for i, q := range lock.Queries {
+		path := lockfile.Resolve(dir, q.Path)
+		query, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fingerprint, err := graphqlschema.Fingerprint(string(query))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if fingerprint != q.Fingerprint {
+			return fmt.Errorf("%s has changed since it was locked; run lock again", path)
+		}
+		queries[i] = string(query)
+	}
+
+	overridesFile = lockfile.Resolve(dir, lock.Overrides)
+	graphqlSchemas = nil
+	for _, path := range lock.GraphQLSchemas {
+		graphqlSchemas = append(graphqlSchemas, lockfile.Resolve(dir, path))
+	}
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+
+	for i, q := range lock.Queries {
+		path := lockfile.Resolve(dir, q.Path)
+		schema, err := buildSchema(cmd, queries[i], overrides)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		g := jsonschemastub.NewGenerator(jsonschemastub.WithSeed(lock.Seed))
+		stub, err := g.Generate(schema)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		printWarnings(cmd, g.Warnings())
+
+		out := strings.TrimSuffix(path, filepath.Ext(path)) + ".json"
+		if err := writeJSON(out, stub); err != nil {
+			return fmt.Errorf("writing %s: %w", out, err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), out)
+	}
+	return nil
+}
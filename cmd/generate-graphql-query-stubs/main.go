@@ -1,14 +1,28 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/envconfig"
 	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
 	"github.com/ohdyno/generate-graphql-query-stubs/internal/jsonschemastub"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/schemaformat"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/stubformat"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/stubtemplate"
 	"github.com/spf13/cobra"
 )
 
@@ -23,13 +37,92 @@ var rootCmd = &cobra.Command{
 	Short: "Generate stub data from GraphQL queries",
 }
 
-var overridesFile string
+var (
+	overridesFile      string
+	graphqlSchemas     []string
+	graphqlSchemaEnv   string
+	introspectionFile  string
+	fieldPaths         bool
+	explain            bool
+	rangeExamples      bool
+	operationMetadata  bool
+	injectTypename     bool
+	nullableNotes      bool
+	requiredValidation bool
+	streamingSchema    bool
+	baseSchema         string
+	operationAliases   string
+	strictOverrides    bool
+	canonical          bool
+	errorTemplate      string
+	errorFormat        string
+	noSchemaKeyword    bool
+	mutationInput      bool
+	splitOperations    bool
+	outputFormat       string
+	alsoOutputs        []string
+	schemaURL          string
+	protoNested        bool
+	includePaths       []string
+	excludePaths       []string
+	stubOutputFormat   string
+	stubInputFormat    string
+	sqlTable           string
+	wiremockOperation  string
+	exampleNames       string
+	generationStats    bool
+	requiredOnly       bool
+	bothStubs          bool
+	withVariables      bool
+	variablesSchema    bool
+	dbDriver           string
+	dbDSN              string
+	dryRun             bool
+	wrapResponse       bool
+	includeErrors      bool
+	currencySymbol     string
+	maxSize            string
+	fieldNames         bool
+	nameAware          bool
+	firstEnum          bool
+	shuffleValues      bool
+	sequentialInts     bool
+	uniqueIDs          bool
+	dictionaryFile     string
+	maxArrayItems      int
+	preferExamples     bool
+	typenameVariants   bool
+	recordFile         string
+	replayFile         string
+	useDefaults        bool
+	metrics            bool
+	costLimit          int
+	printCost          bool
+	fingerprint        bool
+	requireFingerprint string
+	seed               int64
+	count              int
+	templateFile       string
+	templateOut        string
+
+	strictKeywords bool
+	schemaCheck    bool
+	nullableTypes  bool
+	locale         string
+)
 
 var schemaCmd = &cobra.Command{
 	Use:   "schema [query.graphql]",
 	Short: "Generate a JSON Schema from a GraphQL query",
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runSchema,
+	Long: `Generate a JSON Schema describing the response shape of a GraphQL query.
+
+The overrides file maps dot-paths to JSON Schema types. Fields inside lists
+are addressed through an "items" segment, e.g. "data.pokemons.items.id".
+A "*" matches exactly one path segment and may skip over "items" segments,
+so "data.*.id" matches both "data.pokemon.id" and "data.pokemons.items.id".
+Exact keys take precedence over wildcard keys.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSchema,
 }
 
 var stubCmd = &cobra.Command{
@@ -41,50 +134,694 @@ var stubCmd = &cobra.Command{
 
 func init() {
 	schemaCmd.Flags().StringVar(&overridesFile, "overrides", "", "path to overrides JSON file")
+	schemaCmd.Flags().BoolVar(&canonical, "canonical", false, "write the JSON Schema as RFC 8785 canonical JSON, for hashing")
+	schemaCmd.Flags().StringVar(&errorTemplate, "error-template", "", "Go text/template to format schema build errors with, given .File, .Line, .Column and .Message")
+	schemaCmd.Flags().StringVar(&errorFormat, "error-format", "", "format schema build errors for a CI system (github, teamcity)")
+	schemaCmd.Flags().BoolVar(&strictOverrides, "strict-overrides", false, "fail when an override key matches no field of the query")
+	schemaCmd.Flags().StringVar(&operationAliases, "operation-aliases", "", "path to a JSON file of per-operation field type overrides, {\"GetPokemon\": {\"id\": \"integer\"}}")
+	schemaCmd.Flags().StringArrayVar(&graphqlSchemas, "graphql-schema", nil, "path to a GraphQL SDL file to resolve field types from (repeatable; files are merged)")
+	schemaCmd.Flags().StringVar(&graphqlSchemaEnv, "graphql-schema-env", "", "name of an environment variable holding the GraphQL SDL")
+	schemaCmd.Flags().StringVar(&introspectionFile, "introspection-file", "", "path to a GraphQL introspection query response to resolve field types from")
+	schemaCmd.Flags().StringVar(&baseSchema, "base-schema", "", "path to an earlier JSON Schema whose hand-written keywords, such as descriptions, are kept")
+	schemaCmd.Flags().BoolVar(&fieldPaths, "field-paths", false, "annotate every schema node with its x-graphql-path")
+	schemaCmd.Flags().BoolVar(&explain, "explain", false, "annotate every leaf with an x-stub-reason explaining its type")
+	schemaCmd.Flags().BoolVar(&rangeExamples, "range-examples", false, "add examples showing the minimum, midpoint and maximum of numeric ranges")
+	schemaCmd.Flags().BoolVar(&operationMetadata, "metadata", false, "describe the operation at the schema root with x-operation-* keys, x-total-fields and x-schema-generated-at")
+	schemaCmd.Flags().BoolVar(&nullableNotes, "nullable-annotations", false, "mark leaves typed by the GraphQL schema with \"x-nullable\", false for non-null types")
+	schemaCmd.Flags().BoolVar(&injectTypename, "inject-typename", false, "give every object a constant __typename, its SDL type or PascalCased field name")
+	schemaCmd.Flags().BoolVar(&fieldNames, "field-names", false, "annotate every leaf with its x-field-name for name-aware stub generation")
+	schemaCmd.Flags().StringVar(&outputFormat, "output-format", "json-schema", "schema format to output (json-schema, avro, jsonl, proto3, schemastore, type-map)")
+	schemaCmd.Flags().BoolVar(&streamingSchema, "streaming-schema", false, "write each leaf as a line of JSON, {\"path\": ..., \"schema\": ...}; shorthand for --output-format jsonl")
+	schemaCmd.Flags().StringArrayVar(&includePaths, "include-paths", nil, "only keep fields at or below this dot-path (repeatable)")
+	schemaCmd.Flags().StringArrayVar(&excludePaths, "exclude-paths", nil, "remove fields at or below this dot-path (repeatable)")
+	schemaCmd.Flags().StringVarP(&outputFile, "output", "o", "", "write the schema to this file instead of stdout")
+	schemaCmd.Flags().StringVar(&schemaURL, "schema-url", "", "URL the schema is published at, referenced by --output-format schemastore")
+	schemaCmd.Flags().BoolVar(&protoNested, "proto-nested", false, "with --output-format proto3, declare each message inside its parent's instead of at the top level")
+	schemaCmd.Flags().StringArrayVar(&alsoOutputs, "also-output", nil, "also write the schema as format:file, e.g. avro:schema.avsc (repeatable)")
+	schemaCmd.Flags().BoolVar(&metrics, "metrics", false, "print field count, depth and type inference metrics to stderr")
+	schemaCmd.Flags().IntVar(&costLimit, "cost-limit", 0, "fail when the query's estimated cost exceeds this (0 disables the check)")
+	schemaCmd.Flags().BoolVar(&printCost, "print-cost", false, "print the query's estimated cost to stderr")
+	schemaCmd.Flags().BoolVar(&fingerprint, "fingerprint", false, "print the query's fingerprint, a hash of its normalized form, to stderr")
+	schemaCmd.Flags().StringVar(&requireFingerprint, "require-fingerprint", "", "fail unless the query's fingerprint equals this value")
+	schemaCmd.Flags().BoolVar(&mutationInput, "mutation-input", false, "also describe a mutation's variables under \"input\"")
+	schemaCmd.Flags().BoolVar(&splitOperations, "split-operations", false, "write a schema per named operation to --out-dir as <OperationName>.schema.json")
+	schemaCmd.Flags().StringVar(&outDir, "out-dir", "", "directory for --split-operations schemas")
+	schemaCmd.Flags().BoolVar(&variablesSchema, "variables-schema", false, "also describe the operation's variables under \"x-graphql-variables\"")
+	schemaCmd.Flags().BoolVar(&noSchemaKeyword, "no-schema-keyword", false, "omit \"$schema\" so the schema can be embedded in another schema")
+	stubCmd.Flags().Int64Var(&seed, "seed", 0, "seed for reproducible output (random when unset)")
+	stubCmd.Flags().IntVar(&count, "count", 1, "number of stubs to generate; more than one are output as a JSON array")
+	stubCmd.Flags().BoolVar(&schemaCheck, "schema-check", false, "validate the input against the draft-07 meta-schema before generating")
+	stubCmd.Flags().BoolVar(&strictKeywords, "strict-keywords", false, "fail on schema keywords the generator does not recognise")
+	stubCmd.Flags().BoolVar(&nullableTypes, "nullable-types", false, "sometimes generate null for types that include \"null\"")
+	stubCmd.Flags().BoolVar(&requiredValidation, "required-validation", false, "fail when a field marked \"x-nullable\": false could be generated as null")
+	stubCmd.Flags().StringVar(&locale, "locale", "en", "language of generated words (en, fr)")
+	stubCmd.Flags().BoolVar(&firstEnum, "first-enum", false, "always generate the first value of each enum, for stable snapshots")
+	stubCmd.Flags().BoolVar(&sequentialInts, "sequential-integers", false, "generate each integer field as 1, 2, 3, ... across stubs instead of randomly")
+	stubCmd.Flags().BoolVar(&uniqueIDs, "unique-ids", false, "keep id and *_id fields unique among the items of each array")
+	stubCmd.Flags().BoolVar(&typenameVariants, "typename-discriminator", false, "generate one inline fragment variant of interface and union fields, with its __typename")
+	stubCmd.Flags().BoolVar(&preferExamples, "prefer-examples", false, "pick a field's value from its \"examples\", when it has any, instead of generating one")
+	stubCmd.Flags().BoolVar(&useDefaults, "use-defaults", false, "generate a field's \"default\", such as a variable's default value, when it has one")
+	stubCmd.Flags().StringVar(&dictionaryFile, "dictionary", "", "path to a JSON file mapping field names or patterns, such as \"*_name\", to lists of values to pick from")
+	stubCmd.Flags().Var(&referenceCSVs, "reference-csv", "take a field's values from a CSV column, as path=file.csv:column (repeatable)")
+	stubCmd.Flags().BoolVar(&shuffleValues, "shuffle-values", false, "shuffle each x-stub-values list before cycling through it")
+	stubCmd.Flags().BoolVar(&nameAware, "name-aware", false, "pick string formats from x-field-name, e.g. date-times for *_at fields")
+	stubCmd.Flags().Var(&arraySizes, "array-size", "generate arrays whose field name matches a pattern with a length in a range, as pattern=min:max, e.g. stats=6:6 (repeatable)")
+	stubCmd.Flags().IntVar(&maxArrayItems, "max-array-items", 0, "generate at most this many items in any array, whatever its maxItems (0 for no cap)")
+	stubCmd.Flags().StringVar(&recordFile, "record", "", "write every generated value to this JSON Lines file, for --replay")
+	stubCmd.Flags().StringVar(&replayFile, "replay", "", "reuse the values recorded by --record in this file, generating only what it lacks")
+	stubCmd.Flags().StringVar(&maxSize, "max-size", "", "keep each stub to about this much JSON, e.g. 1KB or 10MB, by truncating arrays and omitting optional properties")
+	stubCmd.Flags().StringVar(&currencySymbol, "currency-symbol", "$", "symbol prefixed to strings with format \"currency\"")
+	stubCmd.Flags().StringVar(&stubInputFormat, "input-format", "json", "schema input format (json, or ndjson for one schema per line and one stub per output line)")
+	stubCmd.Flags().StringVarP(&outputFile, "output", "o", "", "write the stubs to this file instead of stdout")
+	stubCmd.Flags().BoolVar(&wrapResponse, "wrap-response", false, "wrap each stub in a GraphQL response envelope, {\"data\": stub}")
+	stubCmd.Flags().BoolVar(&includeErrors, "include-errors-schema", false, "with --wrap-response, add an empty \"errors\" list to the envelope")
+	stubCmd.Flags().StringVar(&stubOutputFormat, "output-format", "json", "stub format to output (har, json, openapi-examples, sql, sql-seed, wiremock, yaml)")
+	stubCmd.Flags().StringArrayVar(&alsoOutputs, "also-output", nil, "also write the stubs as format:file, e.g. yaml:stub.yaml (repeatable)")
+	stubCmd.Flags().StringVar(&sqlTable, "sql-table", "", "table to insert into with --output-format sql or sql-seed")
+	stubCmd.Flags().StringVar(&dbDriver, "db-driver", "", "database driver for --output-format sql-seed (mysql, postgres, sqlite3)")
+	stubCmd.Flags().StringVar(&dbDSN, "db-dsn", "", "data source name of the database --output-format sql-seed inserts into")
+	stubCmd.Flags().BoolVar(&dryRun, "dry-run", false, "with --output-format sql-seed, print the SQL instead of executing it")
+	stubCmd.Flags().StringVar(&wiremockOperation, "operation-name", "", "operation name WireMock mappings match on (default: the schema's x-operation-name)")
+	stubCmd.Flags().StringVar(&exampleNames, "example-names", "", "comma-separated names for --output-format openapi-examples, one per stub (default Example1, Example2, ...)")
+	stubCmd.Flags().BoolVar(&requiredOnly, "required-only", false, "generate minimal stubs with only the properties listed in \"required\"")
+	stubCmd.Flags().BoolVar(&withVariables, "with-variables", false, "also generate the schema's x-graphql-variables under \"variables\", seeded with --seed plus 1")
+	stubCmd.Flags().BoolVar(&bothStubs, "both", false, "output each stub as {\"minimal\": ..., \"full\": ...}, the minimal one a subset of the full one")
+	stubCmd.Flags().BoolVar(&generationStats, "stats", false, "print the 10 slowest fields to generate to stderr")
+	stubCmd.Flags().StringVar(&templateFile, "template", "", "render each stub through this Go template file (the stub is .Data)")
+	stubCmd.Flags().StringVar(&templateOut, "template-out", "", "write rendered template output to this file instead of stdout")
 	rootCmd.AddCommand(schemaCmd, stubCmd)
 }
 
-func runSchema(_ *cobra.Command, args []string) error {
+// readInput reads the file named by the first argument, or stdin when no
+// argument is given.
+func readInput(args []string) ([]byte, error) {
+	var data []byte
+	var err error
+	if len(args) > 0 {
+		data, err = readStream(args[0])
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	return stripBOM(data), err
+}
+
+// utf8BOM is the byte order mark some editors, notably on Windows, write at
+// the start of UTF-8 files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// stripBOM removes a leading UTF-8 byte order mark, which neither the GraphQL
+// nor the JSON parser accepts.
+func stripBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, utf8BOM)
+}
+
+// skipBOM returns r without a leading UTF-8 byte order mark, like stripBOM
+// for input read as a stream.
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if prefix, _ := br.Peek(len(utf8BOM)); bytes.Equal(prefix, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+	return br
+}
+
+// readStream reads path to the end. Unlike os.ReadFile it does not rely on
+// the file's size, so it also reads FIFOs, named pipes and character devices.
+func readStream(path string) ([]byte, error) {
+	f, err := os.Open(cleanInputPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// cleanInputPath cleans path, except for device paths such as /dev/stdin,
+// which are passed through exactly as the user wrote them.
+func cleanInputPath(path string) string {
+	if strings.HasPrefix(path, "/dev/") {
+		return path
+	}
+	return filepath.Clean(path)
+}
+
+// loadOverrides reads the --overrides file and merges in overrides from
+// GRAPHQL_STUB_OVERRIDE_ environment variables.
+func loadOverrides() (map[string]string, error) {
 	overrides := map[string]string{}
 	if overridesFile != "" {
 		data, err := os.ReadFile(filepath.Clean(overridesFile))
 		if err != nil {
-			return fmt.Errorf("reading overrides: %w", err)
+			return nil, fmt.Errorf("reading overrides: %w", err)
 		}
 		if err := json.Unmarshal(data, &overrides); err != nil {
-			return fmt.Errorf("parsing overrides: %w", err)
+			return nil, fmt.Errorf("parsing overrides: %w", err)
 		}
 	}
+	// Environment overrides win over the file, so containers can adjust it.
+	maps.Copy(overrides, envconfig.LoadOverridesFromEnv(envconfig.OverridePrefix, os.Environ()))
+	return overrides, nil
+}
 
-	var query []byte
-	var err error
-	if len(args) > 0 {
-		query, err = os.ReadFile(filepath.Clean(args[0]))
-	} else {
-		query, err = io.ReadAll(os.Stdin)
+// loadSDLEnv returns the GraphQL SDL held in the --graphql-schema-env
+// variable, or "" when the flag is not set.
+func loadSDLEnv() (string, error) {
+	if graphqlSchemaEnv == "" {
+		return "", nil
+	}
+	sdl, ok := os.LookupEnv(graphqlSchemaEnv)
+	if !ok || sdl == "" {
+		return "", fmt.Errorf("environment variable %s is not set", graphqlSchemaEnv)
+	}
+	return sdl, nil
+}
+
+// schemaOptions returns the schema options implied by the command's flags.
+func schemaOptions() []graphqlschema.SchemaOption {
+	var opts []graphqlschema.SchemaOption
+	if fieldPaths {
+		opts = append(opts, graphqlschema.WithFieldPaths())
+	}
+	if explain {
+		opts = append(opts, graphqlschema.WithExplanations())
+	}
+	if metrics {
+		opts = append(opts, graphqlschema.WithOverrideMarkers())
+	}
+	if rangeExamples {
+		opts = append(opts, graphqlschema.WithRangeExamples())
+	}
+	if operationMetadata {
+		opts = append(opts, graphqlschema.WithOperationMetadata())
+	}
+	if injectTypename {
+		opts = append(opts, graphqlschema.WithInjectedTypename())
+	}
+	if strictOverrides {
+		opts = append(opts, graphqlschema.WithStrictOverrides())
+	}
+	if nullableNotes {
+		opts = append(opts, graphqlschema.WithNullableAnnotations())
+	}
+	if fieldNames || nameAware {
+		opts = append(opts, graphqlschema.WithFieldNames())
+	}
+	if mutationInput {
+		opts = append(opts, graphqlschema.WithMutationInputSchema())
+	}
+	if variablesSchema {
+		opts = append(opts, graphqlschema.WithVariablesSchema())
+	}
+	if noSchemaKeyword {
+		opts = append(opts, graphqlschema.WithoutSchemaKeyword())
+	}
+	return opts
+}
+
+// buildSchema builds the JSON Schema for query, taking field types from the
+// GraphQL SDL or an introspection response when one is given. SDL files win
+// over a schema URL, which wins over an introspection response and then the
+// SDL environment variable.
+func buildSchema(cmd *cobra.Command, query string, overrides map[string]string, extra ...graphqlschema.SchemaOption) (map[string]any, error) {
+	opts := append(schemaOptions(), extra...)
+	if operationAliases != "" {
+		var aliases map[string]map[string]string
+		if err := readJSONFile(operationAliases, &aliases); err != nil {
+			return nil, fmt.Errorf("reading operation aliases: %w", err)
+		}
+		opts = append(opts, graphqlschema.WithOperationAliases(aliases))
+	}
+	if operationMetadata && cmd.Flags().Changed("seed") {
+		// A seeded run is meant to be reproducible, timestamps included.
+		opts = append(opts, graphqlschema.WithoutGeneratedAt())
+	}
+	if len(graphqlSchemas) > 0 {
+		if graphqlSchemaURL != "" || graphqlSchemaEnv != "" || introspectionFile != "" {
+			fmt.Fprintln(cmd.ErrOrStderr(), "warning: --graphql-schema takes precedence over --graphql-schema-url, --graphql-schema-env and --introspection-file")
+		}
+		return graphqlschema.BuildSchemaFromSDLFiles(query, graphqlSchemas, overrides, opts...)
+	}
+	sdl, err := loadSDLURL()
+	if err != nil {
+		return nil, err
+	}
+	if sdl != "" {
+		return graphqlschema.BuildSchemaFromSDL(query, sdl, overrides, opts...)
+	}
+	if introspectionFile != "" {
+		introspection, err := os.ReadFile(filepath.Clean(introspectionFile))
+		if err != nil {
+			return nil, fmt.Errorf("reading introspection response: %w", err)
+		}
+		return graphqlschema.BuildSchemaFromIntrospection(introspection, query, overrides, opts...)
+	}
+	sdl, err = loadSDLEnv()
+	if err != nil {
+		return nil, err
+	}
+	if sdl != "" {
+		return graphqlschema.BuildSchemaFromSDL(query, sdl, overrides, opts...)
+	}
+	return graphqlschema.BuildSchema(query, overrides, opts...)
+}
+
+// generatorOptions returns the generator options implied by the command's flags.
+func generatorOptions(cmd *cobra.Command) []jsonschemastub.GenOption {
+	var opts []jsonschemastub.GenOption
+	if cmd.Flags().Changed("seed") {
+		opts = append(opts, jsonschemastub.WithSeed(seed))
+	}
+	if strictKeywords {
+		opts = append(opts, jsonschemastub.WithStrictKeywords())
+	}
+	if nullableTypes {
+		opts = append(opts, jsonschemastub.WithNullableTypes())
+	}
+	if locale != "" {
+		opts = append(opts, jsonschemastub.WithLocale(locale))
+	}
+	if firstEnum {
+		opts = append(opts, jsonschemastub.WithFirstEnumValue())
+	}
+	if nameAware {
+		opts = append(opts, jsonschemastub.WithNameAwareGeneration())
+	}
+	if shuffleValues {
+		opts = append(opts, jsonschemastub.WithShuffledValues())
+	}
+	if sequentialInts {
+		opts = append(opts, jsonschemastub.WithSequentialIntegers())
+	}
+	if uniqueIDs {
+		opts = append(opts, jsonschemastub.WithUniqueIds())
+	}
+	if useDefaults {
+		opts = append(opts, jsonschemastub.WithUseDefaults())
 	}
+	if preferExamples {
+		opts = append(opts, jsonschemastub.WithPreferExamples())
+	}
+	if typenameVariants {
+		opts = append(opts, jsonschemastub.WithTypenameDiscriminator())
+	}
+	opts = append(opts, referenceCSVs.options()...)
+	opts = append(opts, arraySizes.options()...)
+	if requiredValidation {
+		opts = append(opts, jsonschemastub.WithRequiredValidation())
+	}
+	if maxArrayItems > 0 {
+		opts = append(opts, jsonschemastub.WithMaxArrayItems(maxArrayItems))
+	}
+	if dictionaryFile != "" {
+		opts = append(opts, jsonschemastub.WithDictionary(dictionaryFile))
+	}
+	if requiredOnly {
+		opts = append(opts, jsonschemastub.WithRequiredOnly())
+	}
+	if currencySymbol != "" {
+		opts = append(opts, jsonschemastub.WithCurrencySymbol(currencySymbol))
+	}
+	return opts
+}
+
+func printWarnings(cmd *cobra.Command, warnings []string) {
+	if quiet {
+		return
+	}
+	for _, w := range warnings {
+		fmt.Fprintln(cmd.ErrOrStderr(), "warning:", w)
+	}
+}
+
+// writeJSON writes v as indented JSON to path atomically: the data goes to a
+// temporary file in the same directory which is then renamed into place, so
+// readers never observe a partially written file.
+func writeJSON(path string, v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return err
 	}
+	return writeAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(append(out, '\n'))
+		return err
+	})
+}
 
-	schema, err := graphqlschema.BuildSchema(string(query), overrides)
+// writeAtomic writes a file through a temporary file and a rename, so readers
+// never see it half-written. The file keeps the mode of the one it replaces,
+// or is 0644 when new, rather than the temporary file's 0600.
+func writeAtomic(path string, write func(io.Writer) error) error {
+	path = filepath.Clean(path)
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
 
-	out, _ := json.MarshalIndent(schema, "", "  ")
-	fmt.Println(string(out))
-	return nil
+// extraOutput is an additional output requested with --also-output, written
+// by a schema or stub formatter F.
+type extraOutput[F any] struct {
+	formatter F
+	path      string
 }
 
-func runStub(_ *cobra.Command, args []string) error {
-	var input []byte
-	var err error
+// parseAlsoOutputs parses --also-output values of the form format:file,
+// looking formats up with lookup.
+func parseAlsoOutputs[F any](values []string, lookup func(string) (F, error)) ([]extraOutput[F], error) {
+	outputs := make([]extraOutput[F], 0, len(values))
+	for _, v := range values {
+		format, path, ok := strings.Cut(v, ":")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("--also-output %q: want format:file", v)
+		}
+		f, err := lookup(format)
+		if err != nil {
+			return nil, fmt.Errorf("--also-output %q: %w", v, err)
+		}
+		outputs = append(outputs, extraOutput[F]{formatter: f, path: path})
+	}
+	return outputs, nil
+}
+
+// byteUnits are the size suffixes parseByteSize accepts, in powers of 1024.
+var byteUnits = []struct {
+	suffix string
+	size   int
+}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+// parseByteSize parses a size such as "512", "1KB" or "10MB".
+func parseByteSize(s string) (int, error) {
+	number, unit := strings.ToUpper(strings.TrimSpace(s)), 1
+	for _, u := range byteUnits {
+		if trimmed, ok := strings.CutSuffix(number, u.suffix); ok {
+			number, unit = strings.TrimSpace(trimmed), u.size
+			break
+		}
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (want a positive number of bytes, optionally followed by B, KB, MB or GB)", s)
+	}
+	return n * unit, nil
+}
+
+// configureFormatter fills in the settings a formatter takes from flags or
+// the query: a schemastore catalog points at --schema-url and is named after
+// the query's first operation, proto3 messages nest with --proto-nested, and
+// JSON Schemas are canonical with --canonical.
+func configureFormatter(f schemaformat.Formatter, query string) schemaformat.Formatter {
+	switch f := f.(type) {
+	case schemaformat.JSONSchema:
+		f.Canonical = canonical
+		return f
+	case schemaformat.SchemaStore:
+		f.URL = schemaURL
+		if names, err := graphqlschema.OperationNames(query); err == nil && len(names) > 0 {
+			f.OperationName = names[0]
+		}
+		return f
+	case schemaformat.Proto3:
+		f.Nested = protoNested
+		return f
+	}
+	return f
+}
+
+// errorTemplateOptions renders schema build errors with --error-template or
+// the predefined --error-format, naming the query file given in args.
+func errorTemplateOptions(args []string) ([]graphqlschema.SchemaOption, error) {
+	text := errorTemplate
+	if errorFormat != "" {
+		if errorTemplate != "" {
+			return nil, fmt.Errorf("--error-format cannot be combined with --error-template")
+		}
+		var ok bool
+		if text, ok = graphqlschema.ErrorFormats[errorFormat]; !ok {
+			return nil, fmt.Errorf("--error-format: unknown format %q (want github or teamcity)", errorFormat)
+		}
+	}
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := graphqlschema.ParseErrorTemplate(text)
+	if err != nil {
+		return nil, fmt.Errorf("--error-template: %w", err)
+	}
+	opts := []graphqlschema.SchemaOption{graphqlschema.WithErrorTemplate(tmpl)}
 	if len(args) > 0 {
-		input, err = os.ReadFile(filepath.Clean(args[0]))
+		opts = append(opts, graphqlschema.WithSourceName(args[0]))
+	}
+	return opts, nil
+}
+
+// reportTemplateError prints an error rendered by an error template as is,
+// without cobra's "Error: " prefix or usage, so CI systems recognize it.
+func reportTemplateError(cmd *cobra.Command, err error) error {
+	var templateErr *graphqlschema.TemplateError
+	if errors.As(err, &templateErr) {
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
+// costListMultiplier is the number of items assumed per list when estimating
+// query cost.
+const costListMultiplier = 10
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	if streamingSchema {
+		if cmd.Flags().Changed("output-format") && outputFormat != "jsonl" {
+			return fmt.Errorf("--streaming-schema cannot be combined with --output-format %s", outputFormat)
+		}
+		outputFormat = "jsonl"
+	}
+	formatter, err := schemaformat.Lookup(outputFormat)
+	if err != nil {
+		return fmt.Errorf("--output-format: %w", err)
+	}
+	extraOutputs, err := parseAlsoOutputs(alsoOutputs, schemaformat.Lookup)
+	if err != nil {
+		return err
+	}
+	if splitOperations && outDir == "" {
+		return fmt.Errorf("--split-operations requires --out-dir")
+	}
+	if splitOperations && (outputFormat != "json-schema" || len(extraOutputs) > 0) {
+		return fmt.Errorf("--split-operations writes JSON Schema files and cannot be combined with --output-format %s or --also-output", outputFormat)
+	}
+	if canonical && outputFormat != "json-schema" {
+		return fmt.Errorf("--canonical cannot be combined with --output-format %s", outputFormat)
+	}
+
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+
+	query, err := readInput(args)
+	if err != nil {
+		return err
+	}
+	formatter = configureFormatter(formatter, string(query))
+	for i := range extraOutputs {
+		extraOutputs[i].formatter = configureFormatter(extraOutputs[i].formatter, string(query))
+	}
+
+	if fingerprint || requireFingerprint != "" {
+		fp, err := graphqlschema.Fingerprint(string(query))
+		if err != nil {
+			return err
+		}
+		if fingerprint {
+			fmt.Fprintf(cmd.ErrOrStderr(), "fingerprint: %s\n", fp)
+		}
+		if requireFingerprint != "" && fp != requireFingerprint {
+			return fmt.Errorf("query fingerprint %s does not match --require-fingerprint %s", fp, requireFingerprint)
+		}
+	}
+
+	if costLimit > 0 || printCost {
+		cost, err := graphqlschema.EstimateCost(string(query), costListMultiplier)
+		if err != nil {
+			return err
+		}
+		if printCost {
+			fmt.Fprintf(cmd.ErrOrStderr(), "cost: %d\n", cost)
+		}
+		if costLimit > 0 && cost > costLimit {
+			return fmt.Errorf("estimated query cost %d exceeds --cost-limit %d", cost, costLimit)
+		}
+	}
+
+	errorOpts, err := errorTemplateOptions(args)
+	if err != nil {
+		return err
+	}
+	if splitOperations {
+		return writeOperationSchemas(cmd, string(query), overrides, formatter, errorOpts)
+	}
+	schema, err := buildSchema(cmd, string(query), overrides, errorOpts...)
+	if err != nil {
+		return reportTemplateError(cmd, err)
+	}
+	if schema, err = finishSchema(cmd, schema, ""); err != nil {
+		return err
+	}
+
+	// The schema is built once and every output formats that same schema.
+	if outputFile != "" {
+		err = writeAtomic(outputFile, func(w io.Writer) error { return formatter.Format(schema, w) })
 	} else {
-		input, err = io.ReadAll(os.Stdin)
+		err = formatter.Format(schema, cmd.OutOrStdout())
+	}
+	if err != nil {
+		return err
+	}
+	for _, o := range extraOutputs {
+		if err := writeAtomic(o.path, func(w io.Writer) error { return o.formatter.Format(schema, w) }); err != nil {
+			return fmt.Errorf("writing %s: %w", o.path, err)
+		}
+	}
+	return nil
+}
+
+// finishSchema applies what follows building a schema to it: --base-schema,
+// --include-paths and --exclude-paths, and --metrics, whose summary is
+// printed after prefix.
+func finishSchema(cmd *cobra.Command, schema map[string]any, prefix string) (map[string]any, error) {
+	if baseSchema != "" {
+		var base map[string]any
+		if err := readJSONFile(baseSchema, &base); err != nil {
+			return nil, fmt.Errorf("reading base schema: %w", err)
+		}
+		schema = graphqlschema.MergeBaseSchema(schema, base)
+	}
+	schema = filterPaths(schema)
+
+	if metrics {
+		m, _ := json.Marshal(graphqlschema.ComputeMetrics(schema))
+		fmt.Fprintln(cmd.ErrOrStderr(), prefix+string(m))
+		// The markers only feed the metrics; they are not part of the schema.
+		graphqlschema.StripOverrideMarkers(schema)
+	}
+	return schema, nil
+}
+
+// filterPaths applies --include-paths and --exclude-paths to schema.
+func filterPaths(schema map[string]any) map[string]any {
+	if len(includePaths) == 0 && len(excludePaths) == 0 {
+		return schema
+	}
+	return graphqlschema.FilterSchema(schema, includePaths, excludePaths)
+}
+
+// writeOperationSchemas writes a self-contained schema per named operation in
+// the query to --out-dir as <OperationName>.schema.json, each formatted by
+// formatter after going through finishSchema. A failing operation does not
+// stop the others; all errors are reported together at the end, those
+// rendered by an error template as they are.
+func writeOperationSchemas(cmd *cobra.Command, query string, overrides map[string]string, formatter schemaformat.Formatter, errorOpts []graphqlschema.SchemaOption) error {
+	names, err := graphqlschema.OperationNames(query)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	// One overrides file serves every operation, so with --strict-overrides
+	// a key need only match a field of one of them.
+	used := map[string]bool{}
+	var errs []error
+	for i, name := range names {
+		if name == "" {
+			errs = append(errs, fmt.Errorf("operation %d: anonymous operations cannot be split", i+1))
+			continue
+		}
+		opts := append([]graphqlschema.SchemaOption{graphqlschema.WithOperationName(name), graphqlschema.WithOverrideUsage(used)}, errorOpts...)
+		schema, err := buildSchema(cmd, query, overrides, opts...)
+		var templateErr *graphqlschema.TemplateError
+		if errors.As(err, &templateErr) {
+			// Prefixing the operation would hide the error from CI systems.
+			errs = append(errs, err)
+			continue
+		}
+		if err == nil {
+			schema, err = finishSchema(cmd, schema, name+": ")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		file := name + ".schema.json"
+		schema["$id"] = file
+		if err := writeAtomic(filepath.Join(outDir, file), func(w io.Writer) error { return formatter.Format(schema, w) }); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if unused := graphqlschema.UnusedOverrides(overrides, used); strictOverrides && len(unused) > 0 {
+		errs = append(errs, fmt.Errorf("overrides match no field of any operation: %s", strings.Join(unused, ", ")))
+	}
+	return reportTemplateError(cmd, errors.Join(errs...))
+}
+
+func runStub(cmd *cobra.Command, args []string) error {
+	if count < 1 {
+		return fmt.Errorf("--count must be at least 1, got %d", count)
+	}
+	formatter, err := stubformat.Lookup(stubOutputFormat)
+	if err != nil {
+		return fmt.Errorf("--output-format: %w", err)
+	}
+	extraOutputs, err := parseAlsoOutputs(alsoOutputs, stubformat.Lookup)
+	if err != nil {
+		return err
+	}
+	if (stubOutputFormat == "sql" || stubOutputFormat == "sql-seed") && sqlTable == "" {
+		return fmt.Errorf("--output-format %s requires --sql-table", stubOutputFormat)
+	}
+	if stubOutputFormat == "sql-seed" && !dryRun && (dbDriver == "" || dbDSN == "") {
+		return fmt.Errorf("--output-format sql-seed requires --db-driver and --db-dsn, or --dry-run")
+	}
+	if requiredOnly && bothStubs {
+		return fmt.Errorf("--required-only cannot be combined with --both")
+	}
+	if includeErrors && !wrapResponse {
+		return fmt.Errorf("--include-errors-schema requires --wrap-response")
+	}
+	if stubOutputFormat != "json" && templateFile != "" {
+		return fmt.Errorf("--output-format %s cannot be combined with --template", stubOutputFormat)
+	}
+	switch stubInputFormat {
+	case "json":
+	case "ndjson":
+		if count != 1 || stubOutputFormat != "json" || templateFile != "" || len(extraOutputs) > 0 {
+			return fmt.Errorf("--input-format ndjson cannot be combined with --count, --output-format, --also-output or --template")
+		}
+		return runStubNDJSON(cmd, args)
+	default:
+		return fmt.Errorf("unsupported --input-format %q (want json or ndjson)", stubInputFormat)
 	}
+
+	input, err := readInput(args)
 	if err != nil {
 		return err
 	}
@@ -93,9 +830,239 @@ func runStub(_ *cobra.Command, args []string) error {
 	if err := json.Unmarshal(input, &schema); err != nil {
 		return fmt.Errorf("parsing JSON schema: %w", err)
 	}
+	if schemaCheck {
+		if err := jsonschemastub.ValidateSchema(schema); err != nil {
+			return err
+		}
+	}
+
+	opts := generatorOptions(cmd)
+	if generationStats {
+		opts = append(opts, jsonschemastub.WithGenerationStats())
+	}
+	if maxSize != "" {
+		n, err := parseByteSize(maxSize)
+		if err != nil {
+			return fmt.Errorf("--max-size: %w", err)
+		}
+		opts = append(opts, jsonschemastub.WithMaxOutputBytes(n))
+	}
+	if replayFile != "" {
+		f, err := os.Open(filepath.Clean(replayFile))
+		if err != nil {
+			return fmt.Errorf("--replay: %w", err)
+		}
+		defer f.Close()
+		opts = append(opts, jsonschemastub.WithReplay(f))
+	}
+	if recordFile != "" {
+		f, err := os.Create(filepath.Clean(recordFile))
+		if err != nil {
+			return fmt.Errorf("--record: %w", err)
+		}
+		defer f.Close()
+		opts = append(opts, jsonschemastub.WithRecording(f))
+	}
+	g := jsonschemastub.NewGenerator(opts...)
+	vars, varsGen := variablesGenerator(cmd, schema)
+	stubs := make([]any, count)
+	for i := range stubs {
+		if stubs[i], err = g.Generate(schema); err != nil {
+			return err
+		}
+		if bothStubs {
+			// Pruning the full stub keeps shared fields identical.
+			stubs[i] = map[string]any{"minimal": jsonschemastub.RequiredOnly(schema, stubs[i]), "full": stubs[i]}
+		}
+		if wrapResponse {
+			stubs[i] = responseEnvelope(stubs[i], includeErrors)
+		}
+		if varsGen != nil {
+			if err := addVariables(stubs[i], vars, varsGen); err != nil {
+				return err
+			}
+		}
+	}
+	printWarnings(cmd, g.Warnings())
+	if varsGen != nil {
+		printWarnings(cmd, varsGen.Warnings())
+	}
+	if generationStats {
+		printGenerationStats(cmd, g.Stats())
+	}
+
+	// Every output formats the same stubs, so they agree with each other.
+	wrapped := stubformat.Wrap(stubs)
+	for _, o := range extraOutputs {
+		f := configureStubFormatter(cmd, o.formatter, schema)
+		if err := writeAtomic(o.path, func(w io.Writer) error { return f.Format(wrapped, w) }); err != nil {
+			return fmt.Errorf("writing %s: %w", o.path, err)
+		}
+	}
+	if templateFile != "" {
+		return renderTemplate(cmd, stubs)
+	}
+	formatter = configureStubFormatter(cmd, formatter, schema)
+	if outputFile != "" {
+		return writeAtomic(outputFile, func(w io.Writer) error { return formatter.Format(wrapped, w) })
+	}
+	return formatter.Format(wrapped, cmd.OutOrStdout())
+}
+
+// variablesGenerator returns the schema's variables schema and a generator
+// for it under --with-variables, or a nil generator when there is nothing to
+// generate. Seeded runs use the next seed, so the variables differ from the
+// data but are just as reproducible.
+func variablesGenerator(cmd *cobra.Command, schema map[string]any) (map[string]any, *jsonschemastub.Generator) {
+	if !withVariables {
+		return nil, nil
+	}
+	vars, ok := schema[graphqlschema.VariablesKeyword].(map[string]any)
+	if !ok {
+		printWarnings(cmd, []string{"--with-variables: schema has no " + graphqlschema.VariablesKeyword + "; build it with schema --variables-schema"})
+		return nil, nil
+	}
+	opts := generatorOptions(cmd)
+	if cmd.Flags().Changed("seed") {
+		opts = append(opts, jsonschemastub.WithSeed(seed+1))
+	}
+	return vars, jsonschemastub.NewGenerator(opts...)
+}
+
+// addVariables generates variables for stub and adds them under "variables".
+func addVariables(stub any, vars map[string]any, g *jsonschemastub.Generator) error {
+	object, ok := stub.(map[string]any)
+	if !ok {
+		return fmt.Errorf("--with-variables requires object stubs, got %T", stub)
+	}
+	v, err := g.Generate(vars)
+	if err != nil {
+		return fmt.Errorf("generating variables: %w", err)
+	}
+	object["variables"] = v
+	return nil
+}
+
+// slowestFields is the number of fields --stats reports.
+const slowestFields = 10
+
+// printGenerationStats prints the fields that took longest to generate,
+// including their nested values, slowest first.
+func printGenerationStats(cmd *cobra.Command, stats map[string]jsonschemastub.GenerationStat) {
+	paths := slices.SortedFunc(maps.Keys(stats), func(a, b string) int {
+		if c := cmp.Compare(stats[b].TotalNs, stats[a].TotalNs); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	w := cmd.ErrOrStderr()
+	fmt.Fprintf(w, "%-12s %6s %6s  %s\n", "time", "calls", "depth", "field")
+	for _, path := range paths[:min(len(paths), slowestFields)] {
+		stat := stats[path]
+		fmt.Fprintf(w, "%-12s %6d %6d  %s\n", time.Duration(stat.TotalNs), stat.Count, stat.MaxDepth, path)
+	}
+}
+
+// responseEnvelope wraps stub as a GraphQL response, {"data": stub}, adding
+// an empty "errors" list when withErrors is set. A stub that already has a
+// top-level "data" key, as stubs of schemas built from queries do, is not
+// wrapped again.
+func responseEnvelope(stub any, withErrors bool) any {
+	envelope, ok := stub.(map[string]any)
+	if _, hasData := envelope["data"]; !ok || !hasData {
+		envelope = map[string]any{"data": stub}
+	}
+	if withErrors {
+		envelope["errors"] = []any{}
+	}
+	return envelope
+}
+
+// configureStubFormatter fills in the settings a stub formatter takes from
+// flags or the schema: the --sql-table for SQL, the database to seed, the
+// --example-names of OpenAPI examples, the time HAR entries are stamped with,
+// and the operation WireMock mappings match, HAR requests name and example
+// summaries mention, from --operation-name or the schema's x-operation-name.
+func configureStubFormatter(cmd *cobra.Command, f stubformat.Formatter, schema map[string]any) stubformat.Formatter {
+	operationName := wiremockOperation
+	if operationName == "" {
+		operationName, _ = schema["x-operation-name"].(string)
+	}
+	switch f := f.(type) {
+	case stubformat.SQL:
+		f.Table = sqlTable
+		return f
+	case stubformat.SQLSeed:
+		f.Table, f.Driver, f.DSN, f.DryRun = sqlTable, dbDriver, dbDSN, dryRun
+		return f
+	case stubformat.WireMock:
+		f.OperationName = operationName
+		return f
+	case stubformat.HAR:
+		f.OperationName = operationName
+		// A seeded run is meant to be reproducible, so it keeps the epoch.
+		if !cmd.Flags().Changed("seed") {
+			f.Started = time.Now()
+		}
+		return f
+	case stubformat.OpenAPIExamples:
+		if exampleNames != "" {
+			f.Names = strings.Split(exampleNames, ",")
+			for i := range f.Names {
+				f.Names[i] = strings.TrimSpace(f.Names[i])
+			}
+		}
+		f.OperationName = operationName
+		return f
+	}
+	return f
+}
 
-	result := jsonschemastub.Generate(schema)
-	out, _ := json.MarshalIndent(result, "", "  ")
-	fmt.Println(string(out))
+// runStubNDJSON generates a stub per line of NDJSON input, writing them as
+// NDJSON to stdout or --output.
+func runStubNDJSON(cmd *cobra.Command, args []string) error {
+	in := io.Reader(os.Stdin)
+	if len(args) > 0 {
+		f, err := os.Open(cleanInputPath(args[0]))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	in = skipBOM(in)
+
+	g := jsonschemastub.NewGenerator(generatorOptions(cmd)...)
+	defer func() { printWarnings(cmd, g.Warnings()) }()
+	if outputFile != "" {
+		return writeAtomic(outputFile, func(w io.Writer) error {
+			return jsonschemastub.ProcessNDJSON(in, w, g)
+		})
+	}
+	return jsonschemastub.ProcessNDJSON(in, cmd.OutOrStdout(), g)
+}
+
+// renderTemplate renders the --template file once per stub.
+func renderTemplate(cmd *cobra.Command, stubs []any) error {
+	tmpl, err := template.ParseFiles(filepath.Clean(templateFile))
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	if templateOut != "" {
+		f, err := os.Create(filepath.Clean(templateOut))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	for _, stub := range stubs {
+		if err := stubtemplate.Render(tmpl, stub, w); err != nil {
+			return fmt.Errorf("rendering template: %w", err)
+		}
+	}
 	return nil
 }
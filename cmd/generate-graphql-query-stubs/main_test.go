@@ -0,0 +1,1653 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/jsonschemastub"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// execute runs the CLI with args and returns what it wrote to stdout. Flag
+// values are reset first because cobra keeps them between executions.
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var reset func(*cobra.Command)
+	reset = func(c *cobra.Command) {
+		c.Flags().VisitAll(func(f *pflag.Flag) {
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				// Set appends to slice flags, so restore their defaults wholesale.
+				def := strings.Trim(f.DefValue, "[]")
+				var vals []string
+				if def != "" {
+					vals = strings.Split(def, ",")
+				}
+				_ = sv.Replace(vals)
+			} else {
+				_ = f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+		for _, child := range c.Commands() {
+			reset(child)
+		}
+	}
+	reset(rootCmd)
+
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs(args)
+	err := rootCmd.Execute()
+	return stdout.String(), err
+}
+
+// flagHelp returns the line of command's --help describing flag.
+func flagHelp(t *testing.T, command, flag string) string {
+	t.Helper()
+	out, err := execute(t, command, "--help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for line := range strings.SplitSeq(out, "\n") {
+		if strings.Contains(line, flag+" ") {
+			return line
+		}
+	}
+	t.Fatalf("no %s in the help of %s:\n%s", flag, command, out)
+	return ""
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGenerateCommand(t *testing.T) {
+	t.Run("adds the __typename of an inline fragment with --typename-discriminator", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { attack { ... on Fire { damage } } }")
+		out, err := execute(t, "generate", query, "--typename-discriminator")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stub struct {
+			Data struct {
+				Attack map[string]any `json:"attack"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(out), &stub); err != nil {
+			t.Fatal(err)
+		}
+		if stub.Data.Attack["__typename"] != "Fire" || stub.Data.Attack["damage"] == nil {
+			t.Errorf("expected a Fire attack with damage, got %v", stub.Data.Attack)
+		}
+	})
+
+	t.Run("keeps values within type:min:max override ranges", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { height rate } }")
+		overrides := writeFile(t, "overrides.json", `{"data.pokemon.height": "integer:1:3", "data.pokemon.rate": "number:0.5:1.0"}`)
+		for seed := range 20 {
+			out, err := execute(t, "generate", query, "--overrides", overrides, "--seed", fmt.Sprint(seed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var stub struct {
+				Data struct {
+					Pokemon struct {
+						Height float64 `json:"height"`
+						Rate   float64 `json:"rate"`
+					} `json:"pokemon"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal([]byte(out), &stub); err != nil {
+				t.Fatal(err)
+			}
+			p := stub.Data.Pokemon
+			if p.Height < 1 || p.Height > 3 || p.Height != float64(int(p.Height)) {
+				t.Errorf("height %v outside integer range 1..3", p.Height)
+			}
+			if p.Rate < 0.5 || p.Rate > 1.0 {
+				t.Errorf("rate %v outside range 0.5..1.0", p.Rate)
+			}
+		}
+	})
+
+	t.Run("picks formats from field names with --name-aware", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { created_at } }")
+		out, err := execute(t, "generate", query, "--name-aware", "--seed", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, `"created_at": "2024-01-01T00:00:00Z"`) {
+			t.Errorf("expected a date-time created_at, got %s", out)
+		}
+	})
+
+	t.Run("writes the intermediate schema with --schema-out", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name height } }")
+		schemaPath := filepath.Join(t.TempDir(), "schema.json")
+
+		out, err := execute(t, "generate", query, "--schema-out", schemaPath, "--seed", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(schemaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schema map[string]any
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatalf("schema file is not valid JSON: %v", err)
+		}
+		pokemon := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)
+		height := pokemon["properties"].(map[string]any)["height"].(map[string]any)
+		if height["type"] != "integer" {
+			t.Errorf("height type: got %v, want integer", height["type"])
+		}
+
+		var stub map[string]any
+		if err := json.Unmarshal([]byte(out), &stub); err != nil {
+			t.Fatalf("stub output is not valid JSON: %v", err)
+		}
+		if stub["data"] == nil {
+			t.Errorf("expected stub with data key, got %v", stub)
+		}
+	})
+
+	t.Run("writes the stub to --output and nothing to stdout", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		stubPath := filepath.Join(t.TempDir(), "stub.json")
+
+		out, err := execute(t, "generate", query, "--output", stubPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != "" {
+			t.Errorf("expected no stdout, got %q", out)
+		}
+		data, err := os.ReadFile(stubPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stub map[string]any
+		if err := json.Unmarshal(data, &stub); err != nil {
+			t.Fatalf("stub file is not valid JSON: %v", err)
+		}
+	})
+
+	t.Run("writes --output readable by others, keeping an existing file's mode", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		stubPath := filepath.Join(t.TempDir(), "stub.json")
+		mode := func() os.FileMode {
+			info, err := os.Stat(stubPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return info.Mode().Perm()
+		}
+
+		if _, err := execute(t, "generate", query, "--output", stubPath); err != nil {
+			t.Fatal(err)
+		}
+		if got := mode(); got != 0o644 {
+			t.Errorf("expected a new file to be 0644, got %o", got)
+		}
+		if err := os.Chmod(stubPath, 0o640); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := execute(t, "generate", query, "--output", stubPath); err != nil {
+			t.Fatal(err)
+		}
+		if got := mode(); got != 0o640 {
+			t.Errorf("expected the existing 0640 mode to be kept, got %o", got)
+		}
+	})
+
+	t.Run("prints nothing with --quiet", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		out, err := execute(t, "generate", query, "--quiet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != "" {
+			t.Errorf("expected no stdout, got %q", out)
+		}
+	})
+
+	t.Run("generates events for subscriptions with --subscription-events", func(t *testing.T) {
+		query := writeFile(t, "subscription.graphql", "subscription OnBattle { battle { turn damage_ratio } }")
+		out, err := execute(t, "generate", query, "--subscription-events", "5", "--seed", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var events []map[string]any
+		if err := json.Unmarshal([]byte(out), &events); err != nil {
+			t.Fatalf("expected a JSON array of events: %v\n%s", err, out)
+		}
+		if len(events) != 5 {
+			t.Fatalf("expected 5 events, got %d", len(events))
+		}
+		for i, event := range events {
+			if _, ok := event["data"]; !ok {
+				t.Errorf("event %d: expected a data key, got %v", i, event)
+			}
+		}
+		if fmt.Sprint(events[0]) == fmt.Sprint(events[1]) {
+			t.Errorf("expected events to vary, got %v twice", events[0])
+		}
+	})
+
+	t.Run("ignores --subscription-events for queries", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		out, err := execute(t, "generate", query, "--subscription-events", "5")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stub map[string]any
+		if err := json.Unmarshal([]byte(out), &stub); err != nil {
+			t.Fatalf("expected a single stub: %v\n%s", err, out)
+		}
+	})
+}
+
+func TestGenerateDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"get.graphql", "nested/list.graphql", "nested/list_test.graphql"} {
+		p := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("query Q { pokemon { name } }"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outDir := t.TempDir()
+
+	if _, err := execute(t, "generate", "--dir", dir, "--exclude-glob", "*_test.graphql", "--out-dir", outDir, "--seed", "1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"get.json", "nested/list.json"} {
+		if _, err := os.Stat(filepath.Join(outDir, f)); err != nil {
+			t.Errorf("expected stub %s: %v", f, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "nested", "list_test.json")); err == nil {
+		t.Error("expected excluded query to have no stub")
+	}
+}
+
+func TestSchemaCommand(t *testing.T) {
+	const sdl = `type Query { pokemon: Pokemon }
+type Pokemon { name: Int height: String }`
+
+	leafTypes := func(t *testing.T, out string) map[string]any {
+		t.Helper()
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(out), &schema); err != nil {
+			t.Fatalf("output is not valid JSON: %v", err)
+		}
+		data := schema["properties"].(map[string]any)["data"].(map[string]any)
+		props := data["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+		types := map[string]any{}
+		for name, p := range props {
+			types[name] = p.(map[string]any)["type"]
+		}
+		return types
+	}
+
+	t.Run("reads the query from a pipe", func(t *testing.T) {
+		if _, err := os.Stat("/dev/fd"); err != nil {
+			t.Skip("no /dev/fd on this platform")
+		}
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		go func() {
+			fmt.Fprint(w, "query Q { pokemon { name height } }")
+			w.Close()
+		}()
+
+		out, err := execute(t, "schema", fmt.Sprintf("/dev/fd/%d", r.Fd()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if types := leafTypes(t, out); types["name"] != "string" || types["height"] != "integer" {
+			t.Errorf("expected the piped query's fields, got %v", types)
+		}
+	})
+
+	t.Run("reads a query file starting with a byte order mark", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "\xef\xbb\xbfquery Q { pokemon { name height } }")
+		out, err := execute(t, "schema", query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if types := leafTypes(t, out); types["name"] != "string" || types["height"] != "integer" {
+			t.Errorf("expected the query's fields, got %v", types)
+		}
+	})
+
+	t.Run("locates syntax errors in the query", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q {\n  pokemon {\n    name\n  }}\n}")
+		_, err := execute(t, "schema", query)
+		if err == nil || err.Error() != "line 5, col 1: Unexpected }" {
+			t.Errorf("expected a located syntax error, got %v", err)
+		}
+	})
+
+	t.Run("formats errors as GitHub Actions annotations with --error-format github", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q {\n  pokemon {\n    name\n  }}\n}")
+		_, err := execute(t, "schema", query, "--error-format", "github")
+		if err == nil || !strings.HasPrefix(err.Error(), "::error") {
+			t.Fatalf("expected a ::error annotation, got %v", err)
+		}
+		if want := "::error file=" + strings.ReplaceAll(query, ":", "%3A") + ",line=5,col=1::Unexpected }"; err.Error() != want {
+			t.Errorf("got %q, want %q", err, want)
+		}
+	})
+
+	t.Run("leaves the file out of GitHub Actions annotations for stdin", func(t *testing.T) {
+		stdin, err := os.Open(writeFile(t, "query.graphql", "query Q {\n  pokemon {\n    name\n  }}\n}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stdin.Close()
+		defer func(orig *os.File) { os.Stdin = orig }(os.Stdin)
+		os.Stdin = stdin
+
+		_, err = execute(t, "schema", "--error-format", "github")
+		if want := "::error::Unexpected }"; err == nil || err.Error() != want {
+			t.Errorf("got %v, want %q", err, want)
+		}
+	})
+
+	t.Run("formats errors with --error-template", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name }")
+		_, err := execute(t, "schema", query, "--error-template", "{{.Line}}:{{.Column}} {{.Message}}")
+		if err == nil || err.Error() != "1:27 Expected Name, found <EOF>" {
+			t.Errorf("expected the templated error, got %v", err)
+		}
+	})
+
+	t.Run("writes canonical JSON with --canonical", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		out, err := execute(t, "schema", query, "--canonical")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"$schema":"http://json-schema.org/draft-07/schema#","properties":{"data":{"properties":{"pokemon":{"properties":{"name":{"type":"string"}},"type":"object"}},"type":"object"}},"required":["data"],"type":"object"}` + "\n"
+		if out != want {
+			t.Errorf("got  %s\nwant %s", out, want)
+		}
+		if _, err := execute(t, "schema", query, "--canonical", "--output-format", "avro"); err == nil {
+			t.Error("expected --canonical to be rejected for avro output")
+		}
+	})
+
+	t.Run("reads the SDL from --graphql-schema-env", func(t *testing.T) {
+		t.Setenv("POKEMON_SDL", sdl)
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name height } }")
+
+		out, err := execute(t, "schema", query, "--graphql-schema-env", "POKEMON_SDL")
+		if err != nil {
+			t.Fatal(err)
+		}
+		types := leafTypes(t, out)
+		if types["name"] != "integer" || types["height"] != "string" {
+			t.Errorf("expected SDL types, got %v", types)
+		}
+	})
+
+	t.Run("prefers --graphql-schema over --graphql-schema-env", func(t *testing.T) {
+		t.Setenv("POKEMON_SDL", "type Query { unrelated: String }")
+		sdlPath := writeFile(t, "schema.graphqls", sdl)
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name height } }")
+
+		out, err := execute(t, "schema", query, "--graphql-schema", sdlPath, "--graphql-schema-env", "POKEMON_SDL")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if types := leafTypes(t, out); types["name"] != "integer" {
+			t.Errorf("expected types from the SDL file, got %v", types)
+		}
+	})
+
+	t.Run("fails when the environment variable is unset", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		if _, err := execute(t, "schema", query, "--graphql-schema-env", "UNSET_POKEMON_SDL"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("takes field types from --introspection-file", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query Q { pokemon(name: "pikachu") { weight } }`)
+		out, err := execute(t, "schema", query, "--introspection-file", "../../internal/graphqlschema/testdata/pokemon.introspection.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, `"type": "number"`) {
+			t.Errorf("expected weight typed from the introspection response, got %s", out)
+		}
+	})
+
+	t.Run("omits $schema with --no-schema-keyword", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		for args, want := range map[string]bool{"": true, "--no-schema-keyword": false} {
+			cmdArgs := []string{"schema", query}
+			if args != "" {
+				cmdArgs = append(cmdArgs, args)
+			}
+			out, err := execute(t, cmdArgs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var schema map[string]any
+			if err := json.Unmarshal([]byte(out), &schema); err != nil {
+				t.Fatalf("output is not valid JSON: %v", err)
+			}
+			if _, ok := schema["$schema"]; ok != want {
+				t.Errorf("%q: expected $schema present = %v, got %v", args, want, schema["$schema"])
+			}
+		}
+	})
+
+	t.Run("filters fields with --include-paths and --exclude-paths", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name height weight } trainer { name } }")
+		out, err := execute(t, "schema", query, "--include-paths", "data.pokemon", "--exclude-paths", "data.pokemon.weight")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(out), &schema); err != nil {
+			t.Fatal(err)
+		}
+		data := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)
+		if _, ok := data["trainer"]; ok {
+			t.Error("expected trainer to be filtered out")
+		}
+		pokemon := data["pokemon"].(map[string]any)["properties"].(map[string]any)
+		if len(pokemon) != 2 || pokemon["name"] == nil || pokemon["height"] == nil {
+			t.Errorf("expected only name and height, got %v", pokemon)
+		}
+	})
+
+	t.Run("outputs an Avro schema with --output-format avro", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		out, err := execute(t, "schema", query, "--output-format", "avro")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(out), &record); err != nil {
+			t.Fatalf("output is not valid JSON: %v", err)
+		}
+		if record["type"] != "record" || record["name"] != "Response" {
+			t.Errorf("expected a Response record, got %v", record)
+		}
+	})
+
+	t.Run("outputs proto3 messages with --output-format proto3", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		out, err := execute(t, "schema", query, "--output-format", "proto3", "--proto-nested")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(out, "syntax = \"proto3\";") || !strings.Contains(out, "\n  message Data {") {
+			t.Errorf("expected nested proto3 messages, got %s", out)
+		}
+	})
+
+	t.Run("writes a schema catalog entry with --output-format schemastore", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query GetPokemon { pokemon { name } }")
+		out, err := execute(t, "schema", query, "--output-format", "schemastore", "--schema-url", "https://example.com/get-pokemon.schema.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, `"name": "GetPokemon Response"`) || !strings.Contains(out, `"url": "https://example.com/get-pokemon.schema.json"`) {
+			t.Errorf("unexpected catalog %s", out)
+		}
+	})
+
+	t.Run("rejects unknown output formats", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		if _, err := execute(t, "schema", query, "--output-format", "protobuf"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("fails when the estimated cost exceeds --cost-limit", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemons { name } }")
+		if _, err := execute(t, "schema", query, "--cost-limit", "29"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if _, err := execute(t, "schema", query, "--cost-limit", "30"); err != nil {
+			t.Fatalf("expected cost 30 to be within the limit: %v", err)
+		}
+	})
+
+	t.Run("guards against query changes with --require-fingerprint", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		fp, err := graphqlschema.Fingerprint("query Q {\n  pokemon { name }\n}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := execute(t, "schema", query, "--require-fingerprint", fp); err != nil {
+			t.Fatalf("expected matching fingerprint to pass: %v", err)
+		}
+		if _, err := execute(t, "schema", query, "--require-fingerprint", "0000"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("writes every --also-output from one schema", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		dir := t.TempDir()
+		schemaPath := filepath.Join(dir, "schema.json")
+		avroPath := filepath.Join(dir, "schema.avsc")
+
+		if _, err := execute(t, "schema", query, "--output", schemaPath, "--also-output", "avro:"+avroPath); err != nil {
+			t.Fatal(err)
+		}
+		for path, wantType := range map[string]string{schemaPath: "object", avroPath: "record"} {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out map[string]any
+			if err := json.Unmarshal(data, &out); err != nil {
+				t.Fatalf("%s is not valid JSON: %v", path, err)
+			}
+			if out["type"] != wantType {
+				t.Errorf("%s: expected type %q, got %v", path, wantType, out["type"])
+			}
+		}
+	})
+
+	t.Run("rejects malformed --also-output values", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		for _, v := range []string{"schema.ts", "typescript:types.ts"} {
+			if _, err := execute(t, "schema", query, "--also-output", v); err == nil {
+				t.Errorf("%s: expected error, got nil", v)
+			}
+		}
+	})
+
+	t.Run("takes overrides from GRAPHQL_STUB_OVERRIDE_ variables over the file", func(t *testing.T) {
+		t.Setenv("GRAPHQL_STUB_OVERRIDE_data__pokemon__name", "integer")
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name height } }")
+		overrides := writeFile(t, "overrides.json", `{"data.pokemon.name": "boolean", "data.pokemon.height": "number"}`)
+
+		out, err := execute(t, "schema", query, "--overrides", overrides)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if types := leafTypes(t, out); types["name"] != "integer" || types["height"] != "number" {
+			t.Errorf("expected env and file overrides, got %v", types)
+		}
+	})
+
+	t.Run("explains leaf types with --explain", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query Q { pokemon { base_experience } }`)
+
+		out, err := execute(t, "schema", query, "--explain")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, `"x-stub-reason": "matched intRE pattern 'experience'"`) {
+			t.Errorf("expected an x-stub-reason, got:\n%s", out)
+		}
+	})
+
+	t.Run("writes the same schema with --metrics or --explain", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query Q { pokemon { name height } }`)
+		overrides := writeFile(t, "overrides.json", `{"data.pokemon.height": "number"}`)
+
+		want, err := execute(t, "schema", query, "--overrides", overrides)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := execute(t, "schema", query, "--overrides", overrides, "--metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("--metrics changed the schema:\n%s\nwant:\n%s", got, want)
+		}
+		explained, err := execute(t, "schema", query, "--overrides", overrides, "--explain")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(explained, "x-stub-overridden") {
+			t.Errorf("expected no x-stub-overridden with --explain, got:\n%s", explained)
+		}
+	})
+
+	t.Run("describes the operation with --metadata", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query GetPokemon($name: String!) { pokemon(name: $name) { name height } }`)
+
+		out, err := execute(t, "schema", query, "--metadata")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(out), &schema); err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range []string{"x-operation-name", "x-operation-type", "x-operation-variables", "x-total-fields", "x-schema-generated-at"} {
+			if _, ok := schema[key]; !ok {
+				t.Errorf("expected %s in %s", key, out)
+			}
+		}
+
+		schemaOut := filepath.Join(t.TempDir(), "schema.json")
+		if _, err := execute(t, "generate", query, "--metadata", "--schema-out", schemaOut, "--seed", "1", "--quiet"); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(schemaOut)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "x-schema-generated-at") || !strings.Contains(string(data), `"x-operation-name": "GetPokemon"`) {
+			t.Errorf("expected seeded metadata without a timestamp, got %s", data)
+		}
+	})
+
+	t.Run("injects a constant __typename with --inject-typename", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query Q { pokemon_v2_pokemon { name } }`)
+
+		out, err := execute(t, "schema", query, "--inject-typename")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, `"const": "PokemonV2Pokemon"`) {
+			t.Errorf("expected a PokemonV2Pokemon __typename, got:\n%s", out)
+		}
+	})
+
+	t.Run("streams leaves as JSON Lines with --streaming-schema", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query Q { pokemons { name height } }`)
+
+		full, err := execute(t, "schema", query, "--output-format", "type-map")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var types map[string]string
+		if err := json.Unmarshal([]byte(full), &types); err != nil {
+			t.Fatal(err)
+		}
+		out, err := execute(t, "schema", query, "--streaming-schema")
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		for _, line := range lines {
+			var leaf graphqlschema.StreamedLeaf
+			if err := json.Unmarshal([]byte(line), &leaf); err != nil {
+				t.Fatalf("line %q: %v", line, err)
+			}
+			if _, ok := types[leaf.Path]; !ok {
+				t.Errorf("unexpected path %s", leaf.Path)
+			}
+		}
+		if len(lines) != len(types) {
+			t.Errorf("expected %d lines, got %d:\n%s", len(types), len(lines), out)
+		}
+
+		if _, err := execute(t, "schema", query, "--streaming-schema", "--output-format", "avro"); err == nil {
+			t.Error("expected --streaming-schema with another format to fail")
+		}
+	})
+
+	t.Run("keeps hand-written keywords from --base-schema", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query Q { pokemon { name height } }`)
+		base := writeFile(t, "base.json", `{"type":"object","properties":{"data":{"type":"object","properties":{"pokemon":{"type":"object","properties":{"name":{"type":"string","description":"Species name"},"gone":{"type":"string"}}}}}}}`)
+
+		out, err := execute(t, "schema", query, "--base-schema", base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, `"description": "Species name"`) || strings.Contains(out, "gone") || !strings.Contains(out, "height") {
+			t.Errorf("expected the base description, the new field and not the removed one, got:\n%s", out)
+		}
+	})
+
+	t.Run("applies --operation-aliases to the named operation only", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query GetPokemon { pokemon { id } }
+query GetTrainer { pokemon { id } }`)
+		aliases := writeFile(t, "aliases.json", `{"GetPokemon": {"id": "string"}}`)
+		dir := t.TempDir()
+
+		if _, err := execute(t, "schema", query, "--split-operations", "--out-dir", dir, "--operation-aliases", aliases); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range map[string]string{"GetPokemon": "string", "GetTrainer": "integer"} {
+			data, err := os.ReadFile(filepath.Join(dir, name+".schema.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if types := leafTypes(t, string(data)); types["id"] != want {
+				t.Errorf("%s: expected id %s, got %v", name, want, types)
+			}
+		}
+	})
+
+	t.Run("writes a schema per operation with --split-operations", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query GetPokemon { pokemon { name } }
+query GetTrainer { trainer { name } }
+query Broken { broken_range }`)
+		overrides := writeFile(t, "overrides.json", `{"data.broken_range": "integer:9:1"}`)
+		dir := t.TempDir()
+
+		_, err := execute(t, "schema", query, "--split-operations", "--out-dir", dir, "--overrides", overrides)
+		if err == nil || !strings.Contains(err.Error(), "Broken") {
+			t.Fatalf("expected an error naming Broken, got %v", err)
+		}
+		for _, name := range []string{"GetPokemon", "GetTrainer"} {
+			data, err := os.ReadFile(filepath.Join(dir, name+".schema.json"))
+			if err != nil {
+				t.Fatalf("expected %s to be written despite the failure: %v", name, err)
+			}
+			var schema map[string]any
+			if err := json.Unmarshal(data, &schema); err != nil {
+				t.Fatal(err)
+			}
+			if schema["$id"] != name+".schema.json" || schema["$schema"] == nil {
+				t.Errorf("%s: expected its own $id and $schema, got %v, %v", name, schema["$id"], schema["$schema"])
+			}
+		}
+	})
+
+	t.Run("applies the schema flags to every split operation", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query GetPokemon { pokemon { name } }
+query Broken { broken_range }`)
+		overrides := writeFile(t, "overrides.json", `{"data.broken_range": "integer:9:1"}`)
+		base := writeFile(t, "base.json", `{"properties":{"data":{"properties":{"pokemon":{"properties":{"name":{"description":"Species name"}}}}}}}`)
+		dir := t.TempDir()
+
+		_, err := execute(t, "schema", query, "--split-operations", "--out-dir", dir, "--overrides", overrides,
+			"--base-schema", base, "--canonical", "--error-format", "github")
+		if err == nil || !strings.HasPrefix(err.Error(), "::error file=") {
+			t.Fatalf("expected a ::error annotation, got %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "GetPokemon.schema.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"name":{"description":"Species name","type":"string"}`) {
+			t.Errorf("expected canonical JSON keeping the base description, got %s", data)
+		}
+	})
+
+	t.Run("rejects other output formats with --split-operations", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query GetPokemon { pokemon { name } }`)
+		for _, args := range [][]string{{"--output-format", "avro"}, {"--also-output", "avro:schema.avsc"}} {
+			args = append([]string{"schema", query, "--split-operations", "--out-dir", t.TempDir()}, args...)
+			if _, err := execute(t, args...); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		}
+	})
+
+	t.Run("checks --strict-overrides across every split operation", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query A { pokemon { height } }
+query B { trainer { age } }`)
+		overrides := writeFile(t, "overrides.json", `{"data.pokemon.height": "number", "data.trainer.age": "number"}`)
+		if _, err := execute(t, "schema", query, "--split-operations", "--out-dir", t.TempDir(), "--overrides", overrides, "--strict-overrides"); err != nil {
+			t.Fatalf("expected overrides used by some operation to pass, got %v", err)
+		}
+
+		overrides = writeFile(t, "overrides.json", `{"data.pokemon.height": "number", "data.gym.city": "string"}`)
+		_, err := execute(t, "schema", query, "--split-operations", "--out-dir", t.TempDir(), "--overrides", overrides, "--strict-overrides")
+		if err == nil || err.Error() != "overrides match no field of any operation: data.gym.city" {
+			t.Errorf("expected one error naming data.gym.city, got %v", err)
+		}
+	})
+}
+
+func TestStubCommand(t *testing.T) {
+	schema := writeFile(t, "schema.json", `{"type":"object","properties":{"name":{"type":"string"},"height":{"type":"integer"}}}`)
+
+	t.Run("outputs minimal and full stubs with --both", func(t *testing.T) {
+		schema := writeFile(t, "schema.json", `{"type":"object","required":["name"],"properties":{"name":{"type":"string"},"height":{"type":"integer"}}}`)
+		out, err := execute(t, "stub", schema, "--both")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var both struct {
+			Minimal map[string]any `json:"minimal"`
+			Full    map[string]any `json:"full"`
+		}
+		if err := json.Unmarshal([]byte(out), &both); err != nil {
+			t.Fatal(err)
+		}
+		if len(both.Minimal) != 1 || len(both.Full) != 2 {
+			t.Errorf("expected one minimal and two full properties, got %s", out)
+		}
+		for key, v := range both.Minimal {
+			if both.Full[key] != v {
+				t.Errorf("%s: minimal %v differs from full %v", key, v, both.Full[key])
+			}
+		}
+	})
+
+	t.Run("replays a --record recording with --replay", func(t *testing.T) {
+		schema := writeFile(t, "schema.json", `{"type":"array","items":{"type":"object","properties":{"name":{"type":"string"},"height":{"type":"integer"}}}}`)
+		recording := filepath.Join(t.TempDir(), "record.jsonl")
+		recorded, err := execute(t, "stub", schema, "--count", "3", "--record", recording)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replayed, err := execute(t, "stub", schema, "--count", "3", "--replay", recording)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replayed != recorded {
+			t.Errorf("replayed stubs differ from the recorded ones:\nrecorded: %s\nreplayed: %s", recorded, replayed)
+		}
+	})
+
+	t.Run("caps arrays with --max-array-items", func(t *testing.T) {
+		schema := writeFile(t, "schema.json", `{"type":"array","items":{"type":"array","items":{"type":"string"}}}`)
+		out, err := execute(t, "stub", schema, "--max-array-items", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stub [][]string
+		if err := json.Unmarshal([]byte(out), &stub); err != nil {
+			t.Fatal(err)
+		}
+		if len(stub) != 1 || len(stub[0]) != 1 {
+			t.Errorf("expected one item per array, got %s", out)
+		}
+
+		tooLong := writeFile(t, "schema.json", `{"type":"array","minItems":2}`)
+		if _, err := execute(t, "stub", tooLong, "--max-array-items", "1"); err == nil || !strings.Contains(err.Error(), "minItems 2") {
+			t.Errorf("expected a minItems error, got %v", err)
+		}
+	})
+
+	t.Run("generates variables alongside the data with --with-variables", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", `query Get($name: String!, $limit: Int) { pokemon { name height } }`)
+		schemaOut, err := execute(t, "schema", query, "--variables-schema")
+		if err != nil {
+			t.Fatal(err)
+		}
+		schemaFile := writeFile(t, "schema.json", schemaOut)
+
+		out, err := execute(t, "stub", schemaFile, "--with-variables", "--seed", "7")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stub map[string]any
+		if err := json.Unmarshal([]byte(out), &stub); err != nil {
+			t.Fatal(err)
+		}
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(schemaOut), &schema); err != nil {
+			t.Fatal(err)
+		}
+		vars := schema["x-graphql-variables"].(map[string]any)
+		if err := jsonschemastub.ValidateStub(vars, stub["variables"]); err != nil {
+			t.Errorf("variables: %v", err)
+		}
+		if err := jsonschemastub.ValidateStub(schema, stub); err != nil {
+			t.Errorf("data: %v", err)
+		}
+
+		// The variables come from the next seed, so they are reproducible
+		// without repeating the data generator's values.
+		want, err := jsonschemastub.NewGenerator(jsonschemastub.WithSeed(8)).Generate(vars)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantJSON, _ := json.Marshal(want)
+		gotJSON, _ := json.Marshal(stub["variables"])
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("expected variables generated with seed 8, %s, got %s", wantJSON, gotJSON)
+		}
+	})
+
+	t.Run("takes values from --reference-csv", func(t *testing.T) {
+		schema := writeFile(t, "schema.json", `{"type":"object","properties":{"pokemons":{"type":"array","items":{"type":"object","properties":{"name":{"type":"string"}}}}}}`)
+		csv := writeFile(t, "pokemon.csv", "id,name\n25,Pikachu\n133,Eevee\n")
+		out, err := execute(t, "stub", schema, "--count", "5", "--reference-csv", "pokemons.items.name="+csv+":name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stubs []struct {
+			Pokemons []struct{ Name string } `json:"pokemons"`
+		}
+		if err := json.Unmarshal([]byte(out), &stubs); err != nil {
+			t.Fatal(err)
+		}
+		for _, stub := range stubs {
+			for _, p := range stub.Pokemons {
+				if p.Name != "Pikachu" && p.Name != "Eevee" {
+					t.Errorf("expected a name from the CSV, got %q", p.Name)
+				}
+			}
+		}
+		if _, err := execute(t, "stub", schema, "--reference-csv", "pokemons.items.name"); err == nil {
+			t.Error("expected a malformed --reference-csv to fail")
+		}
+	})
+
+	t.Run("shows no default for --array-size", func(t *testing.T) {
+		if line := flagHelp(t, "stub", "--array-size"); strings.Contains(line, "(default") {
+			t.Errorf("expected no default, got %q", line)
+		}
+	})
+
+	t.Run("shows no default for --reference-csv", func(t *testing.T) {
+		for _, command := range []string{"stub", "generate"} {
+			if line := flagHelp(t, command, "--reference-csv"); strings.Contains(line, "(default") {
+				t.Errorf("%s: expected no default, got %q", command, line)
+			}
+		}
+	})
+
+	t.Run("limits stub size with --max-size", func(t *testing.T) {
+		schema := writeFile(t, "schema.json", `{"type":"object","properties":{"pokemons":{"type":"array","minItems":50,"maxItems":50,"items":{"type":"object","properties":{"name":{"type":"string"}}}}}}`)
+		out, err := execute(t, "stub", schema, "--max-size", "1KB")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(out)); err != nil {
+			t.Fatal(err)
+		}
+		if compact.Len() > 1100 {
+			t.Errorf("expected about 1KB of JSON, got %d bytes", compact.Len())
+		}
+		if _, err := execute(t, "stub", schema, "--max-size", "lots"); err == nil || !strings.Contains(err.Error(), "--max-size") {
+			t.Errorf("expected an invalid size error, got %v", err)
+		}
+	})
+
+	t.Run("cycles through x-stub-values with --shuffle-values", func(t *testing.T) {
+		schema := writeFile(t, "schema.json", `{"type":"object","properties":{"name":{"type":"string","x-stub-values":["Pikachu","Charizard","Bulbasaur"]}}}`)
+		out, err := execute(t, "stub", schema, "--count", "6", "--shuffle-values")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stubs []map[string]string
+		if err := json.Unmarshal([]byte(out), &stubs); err != nil {
+			t.Fatal(err)
+		}
+		counts := map[string]int{}
+		for _, stub := range stubs {
+			counts[stub["name"]]++
+		}
+		if len(counts) != 3 || counts["Pikachu"] != 2 || counts["Charizard"] != 2 || counts["Bulbasaur"] != 2 {
+			t.Errorf("expected each value twice, got %v", counts)
+		}
+	})
+
+	t.Run("prints the SQL of --output-format sql-seed with --dry-run", func(t *testing.T) {
+		out, err := execute(t, "stub", schema, "--output-format", "sql-seed", "--sql-table", "pokemon", "--dry-run")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(out, "INSERT INTO pokemon (height, name) VALUES (") {
+			t.Errorf("expected an INSERT statement, got %q", out)
+		}
+		if _, err := execute(t, "stub", schema, "--output-format", "sql-seed", "--sql-table", "pokemon"); err == nil {
+			t.Error("expected an error without --db-driver and --db-dsn")
+		}
+	})
+
+	t.Run("keeps --stats off stdout", func(t *testing.T) {
+		out, err := execute(t, "stub", schema, "--stats")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !json.Valid([]byte(out)) {
+			t.Errorf("expected only the stub on stdout, got %q", out)
+		}
+	})
+
+	t.Run("writes one stub per line with --input-format ndjson, skipping a byte order mark", func(t *testing.T) {
+		schemas := writeFile(t, "schemas.ndjson", "\xef\xbb\xbf"+`{"type":"string"}
+{"type":"integer"}
+{"type":"object","properties":{"name":{"type":"string"}}}
+`)
+		stubs := filepath.Join(t.TempDir(), "stubs.ndjson")
+		if _, err := execute(t, "stub", schemas, "--input-format", "ndjson", "--output", stubs, "--seed", "1"); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(stubs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("expected 3 stubs, got %q", data)
+		}
+		for i, line := range lines {
+			if !json.Valid([]byte(line)) {
+				t.Errorf("line %d is not JSON: %q", i+1, line)
+			}
+		}
+	})
+
+	t.Run("writes a WireMock mapping with --output-format wiremock", func(t *testing.T) {
+		out, err := execute(t, "stub", schema, "--output-format", "wiremock", "--operation-name", "GetPokemon", "--seed", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mapping struct {
+			Request struct {
+				URLPath      string `json:"urlPath"`
+				BodyPatterns []any  `json:"bodyPatterns"`
+			} `json:"request"`
+			Response struct {
+				Status int    `json:"status"`
+				Body   string `json:"body"`
+			} `json:"response"`
+		}
+		if err := json.Unmarshal([]byte(out), &mapping); err != nil {
+			t.Fatal(err)
+		}
+		if mapping.Request.URLPath != "/graphql" || len(mapping.Request.BodyPatterns) != 1 || mapping.Response.Status != 200 {
+			t.Errorf("unexpected mapping %+v", mapping)
+		}
+		if !json.Valid([]byte(mapping.Response.Body)) {
+			t.Errorf("expected a JSON body, got %q", mapping.Response.Body)
+		}
+	})
+
+	t.Run("writes HAR entries with --output-format har", func(t *testing.T) {
+		out, err := execute(t, "stub", schema, "--output-format", "har", "--count", "3", "--seed", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var har struct {
+			Log struct {
+				Entries []struct {
+					StartedDateTime string `json:"startedDateTime"`
+					Response        struct {
+						Content struct {
+							Text string `json:"text"`
+						} `json:"content"`
+					} `json:"response"`
+				} `json:"entries"`
+			} `json:"log"`
+		}
+		if err := json.Unmarshal([]byte(out), &har); err != nil {
+			t.Fatal(err)
+		}
+		if len(har.Log.Entries) != 3 {
+			t.Fatalf("expected an entry per stub, got %d", len(har.Log.Entries))
+		}
+		if text := har.Log.Entries[0].Response.Content.Text; !json.Valid([]byte(text)) {
+			t.Errorf("expected log.entries[0].response.content.text to hold JSON, got %q", text)
+		}
+		if started := har.Log.Entries[0].StartedDateTime; started != "1970-01-01T00:00:00Z" {
+			t.Errorf("expected a seeded run to be stamped with the epoch, got %s", started)
+		}
+	})
+
+	t.Run("writes named OpenAPI examples with --output-format openapi-examples", func(t *testing.T) {
+		out, err := execute(t, "stub", schema, "--output-format", "openapi-examples", "--count", "2", "--example-names", "Pikachu, Eevee")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var examples map[string]struct {
+			Summary string `json:"summary"`
+			Value   any    `json:"value"`
+		}
+		if err := json.Unmarshal([]byte(out), &examples); err != nil {
+			t.Fatal(err)
+		}
+		if len(examples) != 2 || examples["Pikachu"].Value == nil || examples["Eevee"].Value == nil {
+			t.Errorf("expected Pikachu and Eevee examples, got %s", out)
+		}
+
+		if _, err := execute(t, "stub", schema, "--output-format", "openapi-examples", "--count", "2", "--example-names", "Pikachu"); err == nil {
+			t.Error("expected an error for too few example names")
+		}
+	})
+
+	t.Run("wraps stubs in a response envelope with --wrap-response", func(t *testing.T) {
+		out, err := execute(t, "stub", schema, "--wrap-response", "--include-errors-schema")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var response map[string]any
+		if err := json.Unmarshal([]byte(out), &response); err != nil {
+			t.Fatal(err)
+		}
+		if data, ok := response["data"].(map[string]any); !ok || data["name"] == nil {
+			t.Errorf("expected the stub under data, got %v", response)
+		}
+		if errs, ok := response["errors"].([]any); !ok || len(errs) != 0 {
+			t.Errorf("expected an empty errors list, got %v", response["errors"])
+		}
+	})
+
+	t.Run("does not wrap stubs that already have data", func(t *testing.T) {
+		envelope := writeFile(t, "schema.json", `{"type":"object","properties":{"data":{"type":"object","properties":{"name":{"type":"string"}}}}}`)
+		out, err := execute(t, "stub", envelope, "--wrap-response")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var response map[string]map[string]any
+		if err := json.Unmarshal([]byte(out), &response); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := response["data"]["name"]; !ok {
+			t.Errorf("expected data.name, got %v", response)
+		}
+	})
+
+	t.Run("writes every --also-output from the same stubs", func(t *testing.T) {
+		dir := t.TempDir()
+		yamlPath := filepath.Join(dir, "stub.yaml")
+		sqlPath := filepath.Join(dir, "stub.sql")
+		out, err := execute(t, "stub", schema, "--also-output", "yaml:"+yamlPath, "--also-output", "sql:"+sqlPath, "--sql-table", "pokemon", "--seed", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stub struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(out), &stub); err != nil {
+			t.Fatal(err)
+		}
+		yamlOut, err := os.ReadFile(yamlPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(yamlOut), "name: "+stub.Name) {
+			t.Errorf("expected the YAML to hold the same name %q, got %s", stub.Name, yamlOut)
+		}
+		sqlOut, err := os.ReadFile(sqlPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(sqlOut), "'"+stub.Name+"'") {
+			t.Errorf("expected the SQL to hold the same name %q, got %s", stub.Name, sqlOut)
+		}
+	})
+
+	t.Run("writes one INSERT per stub with --output-format sql", func(t *testing.T) {
+		out, err := execute(t, "stub", schema, "--output-format", "sql", "--sql-table", "pokemon", "--count", "3", "--seed", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("expected 3 statements, got %q", out)
+		}
+		for _, line := range lines {
+			if !strings.HasPrefix(line, "INSERT INTO pokemon (height, name) VALUES (") {
+				t.Errorf("unexpected statement %q", line)
+			}
+		}
+	})
+
+	t.Run("requires --sql-table", func(t *testing.T) {
+		if _, err := execute(t, "stub", schema, "--output-format", "sql"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestConfigFile(t *testing.T) {
+	root := t.TempDir()
+	query := filepath.Join(root, "query.graphql")
+	if err := os.WriteFile(query, []byte("query Q { pokemon { name height } }"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	overrides := filepath.Join(root, "overrides.json")
+	if err := os.WriteFile(overrides, []byte(`{"data.pokemon.height": "string"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rc := filepath.Join(root, ".graphqlstubrc.json")
+	if err := os.WriteFile(rc, []byte(`{"overrides": "overrides.json", "seed": 42, "locale": "fr"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(root, "nested")
+	if err := os.Mkdir(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(nested)
+
+	t.Run("takes flag values from the config file", func(t *testing.T) {
+		fromConfig, err := execute(t, "generate", query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		explicit, err := execute(t, "generate", query, "--overrides", overrides, "--seed", "42")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fromConfig != explicit {
+			t.Errorf("expected config values to match explicit flags:\n%s\n%s", fromConfig, explicit)
+		}
+	})
+
+	t.Run("prefers flags given on the command line", func(t *testing.T) {
+		a, err := execute(t, "generate", query, "--seed", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := execute(t, "generate", query, "--seed", "1", "--overrides", overrides)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a != b {
+			t.Errorf("expected --seed 1 to win over the config file:\n%s\n%s", a, b)
+		}
+		var stub struct {
+			Data struct {
+				Pokemon struct {
+					Height any `json:"height"`
+				} `json:"pokemon"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(a), &stub); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := stub.Data.Pokemon.Height.(string); !ok {
+			t.Errorf("expected the config overrides to apply, got %v", stub.Data.Pokemon.Height)
+		}
+	})
+}
+
+func TestCheckStubsCommand(t *testing.T) {
+	query := writeFile(t, "query.graphql", "query Q { pokemon { name height } }")
+	stub := filepath.Join(t.TempDir(), "stub.json")
+	if _, err := execute(t, "generate", query, "--seed", "1", "--output", stub); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := execute(t, "check-stubs", query, stub); err != nil {
+		t.Fatalf("expected the stub to match, got %v", err)
+	}
+	if checkSeed != 1 {
+		t.Errorf("expected --seed to default to 1, got %d", checkSeed)
+	}
+
+	changed := writeFile(t, "query.graphql", "query Q { pokemon { name weight } }")
+	if _, err := execute(t, "check-stubs", changed, stub); err == nil {
+		t.Error("expected a changed query to fail the check")
+	}
+}
+
+func TestSchemaHistoryCommand(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "schema-history.json")
+	record := func(t *testing.T, query string, args ...string) []map[string]any {
+		t.Helper()
+		path := writeFile(t, "query.graphql", query)
+		if _, err := execute(t, append([]string{"schema-history", path, "--history-file", historyPath}, args...)...); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(historyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entries []map[string]any
+		if err := json.Unmarshal(data, &entries); err != nil {
+			t.Fatal(err)
+		}
+		return entries
+	}
+
+	if entries := record(t, "query Q { pokemon { name } }"); len(entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(entries))
+	}
+	if entries := record(t, "query Q {\n  pokemon { name }\n}"); len(entries) != 1 {
+		t.Errorf("expected an unchanged query to add no entry, got %d", len(entries))
+	}
+	entries := record(t, "query Q { pokemon { name height } }", "--max-history", "1")
+	if len(entries) != 1 {
+		t.Fatalf("expected history pruned to one entry, got %d", len(entries))
+	}
+	props := entries[0]["schema"].(map[string]any)["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+	if _, ok := props["height"]; !ok {
+		t.Errorf("expected the newest schema to be kept, got %v", props)
+	}
+}
+
+func TestLockRegenCommands(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "queries"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	query := filepath.Join(dir, "queries", "pokemon.graphql")
+	overrides := filepath.Join(dir, "overrides.json")
+	lock := filepath.Join(dir, "graphql-stubs.lock")
+	for path, content := range map[string]string{
+		query:     "query Q { pokemon { name height } }",
+		overrides: `{"data.pokemon.height": "string"}`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := execute(t, "lock", query, "--overrides", overrides, "--lock-file", lock); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"path": "queries/pokemon.graphql"`) || !strings.Contains(string(data), `"overrides": "overrides.json"`) {
+		t.Errorf("expected paths relative to the lock file, got %s", data)
+	}
+
+	regen := func(t *testing.T) string {
+		t.Helper()
+		if _, err := execute(t, "regen", "--lock-file", lock); err != nil {
+			t.Fatal(err)
+		}
+		stub, err := os.ReadFile(filepath.Join(dir, "queries", "pokemon.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(stub)
+	}
+	first := regen(t)
+	if !strings.Contains(first, `"height": "`) {
+		t.Errorf("expected the locked overrides to apply, got %s", first)
+	}
+	if second := regen(t); second != first {
+		t.Errorf("expected regen to be idempotent, got:\n%s\n---\n%s", first, second)
+	}
+
+	if err := os.WriteFile(query, []byte("query Q { pokemon { name } }"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := execute(t, "regen", "--lock-file", lock); err == nil || !strings.Contains(err.Error(), "changed since it was locked") {
+		t.Errorf("expected a changed query to fail, got %v", err)
+	}
+}
+
+func TestLintCommand(t *testing.T) {
+	t.Run("prints warnings without failing", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { __typename moves } }")
+		sdl := writeFile(t, "schema.graphql", "type Query { pokemon: Pokemon } type Pokemon { moves: [Move] } type Move { name: String }")
+		out, err := execute(t, "lint", query, "--graphql-schema", sdl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "EMPTY_LIST data.pokemon.moves") || !strings.Contains(out, "TYPENAME") {
+			t.Errorf("expected EMPTY_LIST and TYPENAME findings, got %q", out)
+		}
+		if out, _ := execute(t, "lint", query, "--suppress-rule", "TYPENAME"); strings.Contains(out, "TYPENAME") {
+			t.Errorf("expected TYPENAME to be suppressed, got %q", out)
+		}
+	})
+
+	t.Run("warns about missing __typename with --require-typename", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		if out, _ := execute(t, "lint", query); strings.Contains(out, "MISSING_TYPENAME") {
+			t.Errorf("expected no MISSING_TYPENAME by default, got %q", out)
+		}
+		out, err := execute(t, "lint", query, "--require-typename")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "MISSING_TYPENAME data.pokemon") {
+			t.Errorf("expected a MISSING_TYPENAME finding, got %q", out)
+		}
+	})
+
+	t.Run("fails on errors", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name name } }")
+		if _, err := execute(t, "lint", query); err == nil || err.Error() != "lint found 1 error" {
+			t.Fatalf("expected one error for a duplicate field, got %v", err)
+		}
+	})
+
+	t.Run("rejects unknown --suppress-rule IDs", func(t *testing.T) {
+		query := writeFile(t, "query.graphql", "query Q { pokemon { name } }")
+		if _, err := execute(t, "lint", query, "--suppress-rule", "BOGUS"); err == nil {
+			t.Error("expected an error for an unknown rule")
+		}
+	})
+}
+
+func TestValidateCommand(t *testing.T) {
+	schema := writeFile(t, "schema.json", `{"type":"object","properties":{"type":{"type":"string","x-stub-format":"pokemon-type"}}}`)
+
+	if _, err := execute(t, "validate", schema, writeFile(t, "stub.json", `{"type":"fire"}`)); err != nil {
+		t.Errorf("expected a valid stub, got %v", err)
+	}
+	_, err := execute(t, "validate", schema, writeFile(t, "stub.json", `{"type":"lava"}`))
+	if err == nil || !strings.Contains(err.Error(), "/type: ") {
+		t.Errorf("expected an invalid pokemon-type, got %v", err)
+	}
+}
+
+func TestDocsCommand(t *testing.T) {
+	query := writeFile(t, "query.graphql", "query Q { pokemons { name base_stat } }")
+	out, err := execute(t, "docs", query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| `data.pokemons` | list of items |", "| `data.pokemons.items.base_stat` | integer |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected docs to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestMigrateOverridesCommand(t *testing.T) {
+	oldQuery := writeFile(t, "old.graphql", "query Q { pokemon { name height } }")
+	newQuery := writeFile(t, "new.graphql", "query Q { pokemon { names weight } }")
+	overrides := writeFile(t, "overrides.json", `{"data.pokemon.name": "string:3:8", "data.pokemon.height": "integer:1:20"}`)
+
+	out, err := execute(t, "migrate-overrides", oldQuery, newQuery, overrides, "--apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report graphqlschema.OverrideMigration
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatal(err)
+	}
+	// "height" and "weight" are two edits apart, so height is moved as well.
+	if len(report.Renamed) != 2 || len(report.Stale) != 0 || len(report.Added) != 0 {
+		t.Errorf("unexpected report %s", out)
+	}
+
+	var migrated map[string]string
+	if err := readJSONFile(overrides, &migrated); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"data.pokemon.names": "string:3:8", "data.pokemon.weight": "integer:1:20"}
+	if !reflect.DeepEqual(migrated, want) {
+		t.Errorf("overrides file: got %v, want %v", migrated, want)
+	}
+}
+
+func TestReplayCommand(t *testing.T) {
+	dir := t.TempDir()
+	query := filepath.Join(dir, "pokemon.graphql")
+	overrides := filepath.Join(dir, "overrides.json")
+	stub := filepath.Join(dir, "pokemon.json")
+	manifestPath := filepath.Join(dir, "manifest.json")
+	for path, content := range map[string]string{
+		query:     "query Q { pokemon { name height } }",
+		overrides: `{"data.pokemon.height": "integer:1:3"}`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := execute(t, "generate", query, "--overrides", overrides, "--first-enum", "--output", stub, "--save-manifest", manifestPath); err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.ReadFile(stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"path": "pokemon.graphql"`, `"output": "pokemon.json"`, `"first_enum": true`, `"seed": `} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in manifest %s", want, data)
+		}
+	}
+
+	t.Run("regenerates the same stub", func(t *testing.T) {
+		if err := os.Remove(stub); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := execute(t, "replay", manifestPath); err != nil {
+			t.Fatal(err)
+		}
+		replayed, err := os.ReadFile(stub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, replayed) {
+			t.Errorf("replay differs:\n%s\n---\n%s", first, replayed)
+		}
+	})
+
+	t.Run("still replays after an input changed", func(t *testing.T) {
+		if err := os.WriteFile(query, []byte("query Q { pokemon { name height weight } }"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := execute(t, "replay", manifestPath); err != nil {
+			t.Fatal(err)
+		}
+		replayed, err := os.ReadFile(stub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(replayed), "weight") {
+			t.Errorf("expected the changed query to be used, got %s", replayed)
+		}
+	})
+
+	t.Run("replays --reference-csv", func(t *testing.T) {
+		csv := filepath.Join(dir, "names.csv")
+		if err := os.WriteFile(csv, []byte("name\nPikachu\nEevee\nMew\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := execute(t, "generate", query, "--reference-csv", "data.pokemon.name="+csv+":name", "--output", stub, "--save-manifest", manifestPath); err != nil {
+			t.Fatal(err)
+		}
+		first, err := os.ReadFile(stub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"path": "names.csv"`) {
+			t.Errorf("expected the CSV file in manifest %s", data)
+		}
+
+		if _, err := execute(t, "replay", manifestPath); err != nil {
+			t.Fatal(err)
+		}
+		replayed, err := os.ReadFile(stub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, replayed) {
+			t.Errorf("replay differs:\n%s\n---\n%s", first, replayed)
+		}
+	})
+
+	t.Run("replays --operation-aliases, --metadata and --strict-overrides", func(t *testing.T) {
+		aliases := filepath.Join(dir, "aliases.json")
+		if err := os.WriteFile(aliases, []byte(`{"Q": {"name": "integer:1:9"}}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		schemaPath := filepath.Join(dir, "schema.json")
+		if _, err := execute(t, "generate", query, "--overrides", overrides, "--strict-overrides", "--operation-aliases", aliases, "--metadata",
+			"--schema-out", schemaPath, "--output", stub, "--save-manifest", manifestPath); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{`"path": "aliases.json"`, `"metadata": true`, `"strict_overrides": true`} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected %s in manifest %s", want, data)
+			}
+		}
+		first, err := os.ReadFile(schemaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := execute(t, "replay", manifestPath); err != nil {
+			t.Fatal(err)
+		}
+		replayed, err := os.ReadFile(schemaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, replayed) || !strings.Contains(string(replayed), "x-operation-name") {
+			t.Errorf("expected the aliased schema with metadata again, got:\n%s\n---\n%s", first, replayed)
+		}
+	})
+}
+
+func TestGraphQLSchemaURL(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			http.Error(w, "offline", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "type Query { pokemon: Pokemon }\ntype Pokemon { weight: Float }")
+	}))
+	defer server.Close()
+
+	query := writeFile(t, "query.graphql", "query Q { pokemon { weight } }")
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	schema := func(t *testing.T, extra ...string) (string, error) {
+		t.Helper()
+		return execute(t, append([]string{"schema", query, "--graphql-schema-url", server.URL, "--schema-cache-dir", cacheDir}, extra...)...)
+	}
+
+	for i := range 2 {
+		out, err := schema(t)
+		if err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+		if !strings.Contains(out, `"type": "number"`) {
+			t.Errorf("run %d: expected weight typed from the fetched SDL, got %s", i+1, out)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the second run to use the cache, got %d requests", requests)
+	}
+
+	t.Run("refetches with --refresh-cache", func(t *testing.T) {
+		if _, err := schema(t, "--refresh-cache"); err == nil {
+			t.Error("expected the failing server to be asked again")
+		}
+	})
+}
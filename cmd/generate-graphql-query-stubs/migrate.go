@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/spf13/cobra"
+)
+
+var applyMigration bool
+
+var migrateOverridesCmd = &cobra.Command{
+	Use:   "migrate-overrides old-query.graphql new-query.graphql overrides.json",
+	Short: "Update an overrides file after a query changed",
+	Long: `Compare the field paths of the old and new query and print a JSON report of
+the overrides file entries that need attention:
+
+  renamed  overrides whose field was renamed or moved, with their new path
+  stale    overrides that no longer match any field
+  added    new fields that may need an override
+
+A field counts as renamed when a new field's name is within two edits of the
+old one. With --apply, the renames are written back to the overrides file;
+stale entries are kept for review.`,
+	Args:         cobra.ExactArgs(3),
+	SilenceUsage: true,
+	RunE:         runMigrateOverrides,
+}
+
+func init() {
+	migrateOverridesCmd.Flags().StringArrayVar(&graphqlSchemas, "graphql-schema", nil, "path to a GraphQL SDL file to resolve field types from (repeatable; files are merged)")
+	migrateOverridesCmd.Flags().BoolVar(&applyMigration, "apply", false, "rewrite the overrides file with the suggested renames")
+	rootCmd.AddCommand(migrateOverridesCmd)
+}
+
+func runMigrateOverrides(cmd *cobra.Command, args []string) error {
+	var schemas [2]map[string]any
+	for i, path := range args[:2] {
+		query, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		// Overrides only change types, not paths, so they are left out here.
+		if schemas[i], err = buildSchema(cmd, string(query), nil); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	var overrides map[string]string
+	if err := readJSONFile(args[2], &overrides); err != nil {
+		return fmt.Errorf("parsing overrides: %w", err)
+	}
+
+	migration := graphqlschema.MigrateOverrides(schemas[0], schemas[1], overrides)
+	if applyMigration && len(migration.Renamed) > 0 {
+		if err := writeJSON(args[2], migration.Apply(overrides)); err != nil {
+			return fmt.Errorf("writing overrides: %w", err)
+		}
+	}
+	out, _ := json.MarshalIndent(migration, "", "  ")
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/jsonschemastub"
+)
+
+// referenceCSV is a --reference-csv value, path=file.csv:column.
+type referenceCSV struct {
+	path, file, column string
+}
+
+// referenceCSVFlag collects --reference-csv values, rejecting malformed ones
+// as the flags are parsed. It implements pflag.SliceValue.
+type referenceCSVFlag struct {
+	refs []referenceCSV
+}
+
+var referenceCSVs referenceCSVFlag
+
+func parseReferenceCSV(s string) (referenceCSV, error) {
+	path, source, ok := strings.Cut(s, "=")
+	i := strings.LastIndex(source, ":")
+	if !ok || path == "" || i <= 0 || i == len(source)-1 {
+		return referenceCSV{}, fmt.Errorf("invalid reference %q (want path=file.csv:column)", s)
+	}
+	return referenceCSV{path: path, file: source[:i], column: source[i+1:]}, nil
+}
+
+// String is empty without references, so --help shows no default.
+func (f *referenceCSVFlag) String() string {
+	if len(f.refs) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(f.GetSlice(), ",") + "]"
+}
+
+func (f *referenceCSVFlag) Set(s string) error {
+	return f.Append(s)
+}
+
+func (f *referenceCSVFlag) Type() string {
+	return "stringArray"
+}
+
+func (f *referenceCSVFlag) Append(s string) error {
+	ref, err := parseReferenceCSV(s)
+	if err != nil {
+		return err
+	}
+	f.refs = append(f.refs, ref)
+	return nil
+}
+
+func (f *referenceCSVFlag) Replace(values []string) error {
+	refs := make([]referenceCSV, 0, len(values))
+	for _, s := range values {
+		ref, err := parseReferenceCSV(s)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, ref)
+	}
+	f.refs = refs
+	return nil
+}
+
+func (f *referenceCSVFlag) GetSlice() []string {
+	values := make([]string, len(f.refs))
+	for i, ref := range f.refs {
+		values[i] = ref.path + "=" + ref.file + ":" + ref.column
+	}
+	return values
+}
+
+// options returns a WithReferenceCSV option for each reference.
+func (f *referenceCSVFlag) options() []jsonschemastub.GenOption {
+	opts := make([]jsonschemastub.GenOption, len(f.refs))
+	for i, ref := range f.refs {
+		opts[i] = jsonschemastub.WithReferenceCSV(ref.path, ref.file, ref.column)
+	}
+	return opts
+}
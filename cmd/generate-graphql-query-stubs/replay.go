@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/lockfile"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var saveManifest string
+
+var replayCmd = &cobra.Command{
+	Use:   "replay manifest.json",
+	Short: "Re-run a generate command recorded in a manifest",
+	Long: `Re-run generate with the query, overrides, operation aliases, GraphQL schema
+files, reference CSV files, seed, options and output paths recorded by generate --save-manifest. A warning is
+printed for every input file that has changed since the manifest was saved,
+but the stub is generated anyway.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runReplay,
+}
+
+func init() {
+	generateCmd.Flags().StringVar(&saveManifest, "save-manifest", "", "record the query, options and output paths in this manifest for replay")
+	rootCmd.AddCommand(replayCmd)
+}
+
+// prepareManifest checks a generate run can be recorded and, when no seed was
+// given, picks the random seed the manifest records.
+func prepareManifest(cmd *cobra.Command, args []string) error {
+	if batchDir != "" || len(args) == 0 {
+		return fmt.Errorf("--save-manifest needs a query file argument and cannot be combined with --dir")
+	}
+	if graphqlSchemaEnv != "" || graphqlSchemaURL != "" {
+		return fmt.Errorf("--save-manifest cannot record --graphql-schema-env or --graphql-schema-url; use --graphql-schema")
+	}
+	if cmd.Flags().Changed("seed") {
+		return nil
+	}
+	return cmd.Flags().Set("seed", strconv.FormatInt(rand.Int63(), 10))
+}
+
+// writeManifest saves the options of the current generate run for query to
+// --save-manifest.
+func writeManifest(query string) error {
+	dir := filepath.Dir(saveManifest)
+	m := &manifest.Manifest{
+		Seed:               seed,
+		SubscriptionEvents: subscriptionEvents,
+		MutationInput:      mutationInput,
+		FirstEnum:          firstEnum,
+		NameAware:          nameAware,
+		ShuffleValues:      shuffleValues,
+		SequentialIntegers: sequentialInts,
+		UniqueIDs:          uniqueIDs,
+		TypenameVariants:   typenameVariants,
+		UseDefaults:        useDefaults,
+		Metadata:           operationMetadata,
+		StrictOverrides:    strictOverrides,
+		SchemaDraft:        graphqlschema.SchemaDraft,
+	}
+	var err error
+	if m.Query, err = manifest.NewFile(dir, query); err != nil {
+		return err
+	}
+	if overridesFile != "" {
+		f, err := manifest.NewFile(dir, overridesFile)
+		if err != nil {
+			return err
+		}
+		m.Overrides = &f
+	}
+	if operationAliases != "" {
+		f, err := manifest.NewFile(dir, operationAliases)
+		if err != nil {
+			return err
+		}
+		m.OperationAliases = &f
+	}
+	for _, path := range graphqlSchemas {
+		f, err := manifest.NewFile(dir, path)
+		if err != nil {
+			return err
+		}
+		m.GraphQLSchemas = append(m.GraphQLSchemas, f)
+	}
+	if introspectionFile != "" {
+		f, err := manifest.NewFile(dir, introspectionFile)
+		if err != nil {
+			return err
+		}
+		m.IntrospectionFile = &f
+	}
+	for _, ref := range referenceCSVs.refs {
+		f, err := manifest.NewFile(dir, ref.file)
+		if err != nil {
+			return err
+		}
+		m.ReferenceCSVs = append(m.ReferenceCSVs, manifest.ReferenceCSV{Field: ref.path, File: f, Column: ref.column})
+	}
+	for _, out := range []struct {
+		path string
+		rel  *string
+	}{{outputFile, &m.Output}, {schemaOut, &m.SchemaOut}} {
+		if out.path == "" {
+			continue
+		}
+		if *out.rel, err = lockfile.Rel(dir, out.path); err != nil {
+			return err
+		}
+	}
+	return manifest.Save(saveManifest, m)
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	m, err := manifest.Load(args[0])
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(args[0])
+
+	changed, err := m.Changed(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range changed {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s has changed since the manifest was saved\n", path)
+	}
+	if m.SchemaDraft != graphqlschema.SchemaDraft {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: the manifest was saved with schema draft %s, but schemas are now built for %s\n", m.SchemaDraft, graphqlschema.SchemaDraft)
+	}
+
+	// Replay through generate's own flags, so it runs exactly like the
+	// recorded command.
+	values := map[string]string{
+		"seed":                   strconv.FormatInt(m.Seed, 10),
+		"subscription-events":    strconv.Itoa(m.SubscriptionEvents),
+		"mutation-input":         strconv.FormatBool(m.MutationInput),
+		"first-enum":             strconv.FormatBool(m.FirstEnum),
+		"name-aware":             strconv.FormatBool(m.NameAware),
+		"shuffle-values":         strconv.FormatBool(m.ShuffleValues),
+		"sequential-integers":    strconv.FormatBool(m.SequentialIntegers),
+		"unique-ids":             strconv.FormatBool(m.UniqueIDs),
+		"typename-discriminator": strconv.FormatBool(m.TypenameVariants),
+		"use-defaults":           strconv.FormatBool(m.UseDefaults),
+		"metadata":               strconv.FormatBool(m.Metadata),
+		"strict-overrides":       strconv.FormatBool(m.StrictOverrides),
+		"output":                 lockfile.Resolve(dir, m.Output),
+		"schema-out":             lockfile.Resolve(dir, m.SchemaOut),
+	}
+	if m.Overrides != nil {
+		values["overrides"] = lockfile.Resolve(dir, m.Overrides.Path)
+	}
+	if m.OperationAliases != nil {
+		values["operation-aliases"] = lockfile.Resolve(dir, m.OperationAliases.Path)
+	}
+	if m.IntrospectionFile != nil {
+		values["introspection-file"] = lockfile.Resolve(dir, m.IntrospectionFile.Path)
+	}
+	flags := generateCmd.Flags()
+	for name, value := range values {
+		if err := flags.Set(name, value); err != nil {
+			return err
+		}
+	}
+	for _, f := range m.GraphQLSchemas {
+		if err := flags.Set("graphql-schema", lockfile.Resolve(dir, f.Path)); err != nil {
+			return err
+		}
+	}
+	for _, ref := range m.ReferenceCSVs {
+		if err := flags.Set("reference-csv", ref.Field+"="+lockfile.Resolve(dir, ref.File.Path)+":"+ref.Column); err != nil {
+			return err
+		}
+	}
+	return runGenerate(generateCmd, []string{lockfile.Resolve(dir, m.Query.Path)})
+}
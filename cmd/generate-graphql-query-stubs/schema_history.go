@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyFile string
+	maxHistory  int
+)
+
+var schemaHistoryCmd = &cobra.Command{
+	Use:   "schema-history [query.graphql]",
+	Short: "Record the query's schema in a versioned history file",
+	Long: `Build the JSON Schema for the query and append it to the history file with a
+timestamp and the query's fingerprint. Nothing is added when the fingerprint
+matches the latest entry, and the oldest entries are pruned beyond
+--max-history.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSchemaHistory,
+}
+
+func init() {
+	schemaHistoryCmd.Flags().StringVar(&overridesFile, "overrides", "", "path to overrides JSON file")
+	schemaHistoryCmd.Flags().StringArrayVar(&graphqlSchemas, "graphql-schema", nil, "path to a GraphQL SDL file to resolve field types from (repeatable; files are merged)")
+	schemaHistoryCmd.Flags().StringVar(&graphqlSchemaEnv, "graphql-schema-env", "", "name of an environment variable holding the GraphQL SDL")
+	schemaHistoryCmd.Flags().StringVar(&historyFile, "history-file", "schema-history.json", "path to the schema history file")
+	schemaHistoryCmd.Flags().IntVar(&maxHistory, "max-history", 10, "number of entries to keep; older ones are pruned")
+	rootCmd.AddCommand(schemaHistoryCmd)
+}
+
+func runSchemaHistory(cmd *cobra.Command, args []string) error {
+	if maxHistory < 1 {
+		return fmt.Errorf("--max-history must be at least 1, got %d", maxHistory)
+	}
+
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+
+	query, err := readInput(args)
+	if err != nil {
+		return err
+	}
+
+	fingerprint, err := graphqlschema.Fingerprint(string(query))
+	if err != nil {
+		return err
+	}
+	schema, err := buildSchema(cmd, string(query), overrides)
+	if err != nil {
+		return err
+	}
+
+	entries, err := history.Load(historyFile)
+	if err != nil {
+		return err
+	}
+	entries, added := history.Append(entries, history.Entry{
+		Timestamp:        time.Now().UTC(),
+		QueryFingerprint: fingerprint,
+		Schema:           schema,
+	})
+	if !added && len(entries) <= maxHistory {
+		return nil
+	}
+	return history.Save(historyFile, history.Prune(entries, maxHistory))
+}
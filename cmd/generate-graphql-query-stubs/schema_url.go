@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/schemacache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphqlSchemaURL string
+	schemaCacheDir   string
+	schemaCacheTTL   time.Duration
+	refreshCache     bool
+)
+
+// schemaFetchTimeout bounds how long fetching a --graphql-schema-url waits.
+const schemaFetchTimeout = 30 * time.Second
+
+func init() {
+	for _, cmd := range []*cobra.Command{schemaCmd, generateCmd} {
+		cmd.Flags().StringVar(&graphqlSchemaURL, "graphql-schema-url", "", "URL to fetch the GraphQL SDL to resolve field types from")
+		cmd.Flags().StringVar(&schemaCacheDir, "schema-cache-dir", "", "cache --graphql-schema-url schemas in this directory, so later runs work offline")
+		cmd.Flags().DurationVar(&schemaCacheTTL, "schema-cache-ttl", 24*time.Hour, "refetch cached schemas older than this")
+		cmd.Flags().BoolVar(&refreshCache, "refresh-cache", false, "fetch --graphql-schema-url even when it is cached, and update the cache")
+	}
+}
+
+// loadSDLURL returns the GraphQL SDL at --graphql-schema-url, or "" when the
+// flag is not set. With --schema-cache-dir, a fresh cached copy is used
+// without a request, and fetched schemas are cached.
+func loadSDLURL() (string, error) {
+	if graphqlSchemaURL == "" {
+		return "", nil
+	}
+	var cache *schemacache.Cache
+	if schemaCacheDir != "" {
+		cache = schemacache.New(schemaCacheDir, schemaCacheTTL)
+		if !refreshCache {
+			sdl, ok, err := cache.Get(graphqlSchemaURL)
+			if err != nil {
+				return "", fmt.Errorf("reading schema cache: %w", err)
+			}
+			if ok {
+				return string(sdl), nil
+			}
+		}
+	}
+
+	sdl, err := fetchSDL(graphqlSchemaURL)
+	if err != nil {
+		return "", err
+	}
+	if cache != nil {
+		if err := cache.Set(graphqlSchemaURL, sdl); err != nil {
+			return "", fmt.Errorf("writing schema cache: %w", err)
+		}
+	}
+	return string(sdl), nil
+}
+
+func fetchSDL(url string) ([]byte, error) {
+	client := &http.Client{Timeout: schemaFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching GraphQL schema: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching GraphQL schema: %s returned %s", url, resp.Status)
+	}
+	sdl, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fetching GraphQL schema: %w", err)
+	}
+	return sdl, nil
+}
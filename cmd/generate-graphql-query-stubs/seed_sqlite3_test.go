@@ -0,0 +1,34 @@
+//go:build sqlite3
+
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLSeedSQLite(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "seed.db")
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE pokemon (name TEXT, height INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := writeFile(t, "schema.json", `{"type":"object","properties":{"name":{"type":"string"},"height":{"type":"integer"}}}`)
+	if _, err := execute(t, "stub", schema, "--output-format", "sql-seed", "--sql-table", "pokemon", "--db-driver", "sqlite3", "--db-dsn", dsn, "--count", "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM pokemon WHERE name <> '' AND height > 0").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 seeded rows, got %d", n)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/jsonschemastub"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate schema.json stub.json",
+	Short: "Check that a stub matches a JSON Schema",
+	Long: `Validate the stub against the JSON Schema. Strings with a custom "format" or
+"x-stub-format" are checked by the built-in format validators:
+
+  pokemon-name  lowercase letters and hyphens, e.g. mr-mime
+  pokemon-type  one of the eighteen Pokemon types, e.g. psychic`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE:         runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	var schema map[string]any
+	if err := readJSONFile(args[0], &schema); err != nil {
+		return fmt.Errorf("parsing JSON schema: %w", err)
+	}
+	var stub any
+	if err := readJSONFile(args[1], &stub); err != nil {
+		return fmt.Errorf("parsing stub: %w", err)
+	}
+	return jsonschemastub.ValidateStub(schema, stub)
+}
+
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,11 @@
+// Package gogenerate shows how to keep a stub fixture up to date with
+// go generate. Running
+//
+//	go generate ./examples/gogenerate
+//
+// regenerates testdata/stub.json from query.graphql. With the binary
+// installed, the directive can call generate-graphql-query-stubs directly
+// instead of going through go run.
+package gogenerate
+
+//go:generate go run github.com/ohdyno/generate-graphql-query-stubs/cmd/generate-graphql-query-stubs generate --seed 1 --quiet --output testdata/stub.json query.graphql
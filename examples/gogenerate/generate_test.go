@@ -0,0 +1,66 @@
+package gogenerate
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestGoGenerate runs go generate on a copy of this package, so the test
+// neither rewrites the committed fixture nor needs a writable checkout.
+func TestGoGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go generate")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"generate.go", "query.graphql"} {
+		copyFile(t, name, filepath.Join(dir, name))
+	}
+	copyFile(t, filepath.Join(root, "go.sum"), filepath.Join(dir, "go.sum"))
+	goMod := "module example\n\ngo 1.26.0\n\n" +
+		"require github.com/ohdyno/generate-graphql-query-stubs v0.0.0\n\n" +
+		"replace github.com/ohdyno/generate-graphql-query-stubs => " + root + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "testdata"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "generate", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go generate: %v\n%s", err, out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "testdata", "stub.json"))
+	if err != nil {
+		t.Fatalf("expected go generate to write the stub: %v", err)
+	}
+	var stub map[string]any
+	if err := json.Unmarshal(data, &stub); err != nil {
+		t.Fatalf("stub is not valid JSON: %v", err)
+	}
+	if stub["data"] == nil {
+		t.Errorf("expected stub with data key, got %v", stub)
+	}
+}
+
+func copyFile(t *testing.T, from, to string) {
+	t.Helper()
+	data, err := os.ReadFile(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(to, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,139 @@
+// Package avroexport converts the JSON Schemas built from GraphQL queries into
+// Apache Avro schemas.
+package avroexport
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// primitiveTypes maps JSON Schema types to Avro primitive types.
+var primitiveTypes = map[string]string{
+	"string":  "string",
+	"integer": "long",
+	"number":  "double",
+	"boolean": "boolean",
+	"null":    "null",
+}
+
+// Convert returns the Avro schema for a JSON Schema object, as a record named
+// name. Nested records are named after their field path, e.g.
+// "data_pokemon_v2_pokemon", so every record name in the result is unique.
+// Properties are emitted as fields in sorted order, and a type that includes
+// "null" becomes the union ["null", type].
+func Convert(schema map[string]any, name string) (map[string]any, error) {
+	c := &converter{defs: map[string]any{}, defined: map[string]bool{}}
+	if defs, ok := schema["$defs"].(map[string]any); ok {
+		c.defs = defs
+	}
+	return c.record(schema, name)
+}
+
+type converter struct {
+	// defs holds the root "$defs" that "$ref"s point into.
+	defs map[string]any
+	// defined records the names of records already emitted; Avro refers to
+	// them by name from then on.
+	defined map[string]bool
+}
+
+func (c *converter) record(schema map[string]any, name string) (map[string]any, error) {
+	c.defined[name] = true
+	properties, _ := schema["properties"].(map[string]any)
+	fields := make([]any, 0, len(properties))
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		ps, ok := properties[key].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: property schema is not an object", name, key)
+		}
+		t, err := c.convert(ps, name+"_"+key)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, map[string]any{"name": key, "type": t})
+	}
+	return map[string]any{"type": "record", "name": name, "fields": fields}, nil
+}
+
+// convert returns the Avro type for schema, found at the path given by name.
+func (c *converter) convert(schema map[string]any, name string) (any, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		return c.ref(ref)
+	}
+
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	var nullable bool
+	types = slices.DeleteFunc(types, func(t string) bool {
+		if t == "null" {
+			nullable = true
+			return true
+		}
+		return false
+	})
+	if len(types) != 1 {
+		if nullable && len(types) == 0 {
+			return "null", nil
+		}
+		return nil, fmt.Errorf("%s: cannot convert type %v to Avro", name, schema["type"])
+	}
+
+	t, err := c.convertType(schema, types[0], name)
+	if err != nil {
+		return nil, err
+	}
+	if nullable {
+		return []any{"null", t}, nil
+	}
+	return t, nil
+}
+
+func (c *converter) convertType(schema map[string]any, t, name string) (any, error) {
+	switch t {
+	case "object":
+		return c.record(schema, name)
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return nil, fmt.Errorf("%s: array has no items schema", name)
+		}
+		it, err := c.convert(items, name)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": it}, nil
+	}
+	if p, ok := primitiveTypes[t]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("%s: cannot convert type %q to Avro", name, t)
+}
+
+// ref resolves a "#/$defs/..." reference. The first use defines the record;
+// later uses refer to it by name.
+func (c *converter) ref(ref string) (any, error) {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	if c.defined[name] {
+		return name, nil
+	}
+	def, ok := c.defs[name].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("$ref %q has no definition", ref)
+	}
+	return c.convert(def, name)
+}
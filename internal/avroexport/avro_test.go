@@ -0,0 +1,167 @@
+package avroexport
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+)
+
+// avroType is the subset of Avro schema JSON the converter emits.
+type avroType struct {
+	Type   string      `json:"type"`
+	Name   string      `json:"name"`
+	Fields []avroField `json:"fields"`
+	Items  any         `json:"items"`
+}
+
+type avroField struct {
+	Name string `json:"name"`
+	Type any    `json:"type"`
+}
+
+var avroName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parse round-trips an Avro schema through JSON, as a consumer would read it.
+func parse(t *testing.T, v any) avroType {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a avroType
+	if err := json.Unmarshal(b, &a); err != nil {
+		t.Fatalf("decoding %s: %v", b, err)
+	}
+	return a
+}
+
+func field(t *testing.T, r avroType, name string) any {
+	t.Helper()
+	for _, f := range r.Fields {
+		if f.Name == name {
+			return f.Type
+		}
+	}
+	t.Fatalf("record %s has no field %q", r.Name, name)
+	return nil
+}
+
+func TestConvert(t *testing.T) {
+	t.Run("converts the pokemon_stats fixture", func(t *testing.T) {
+		query, err := os.ReadFile("../graphqlschema/testdata/pokemon_stats.graphql")
+		if err != nil {
+			t.Fatalf("reading fixture: %v", err)
+		}
+		schema, err := graphqlschema.BuildSchema(string(query), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Convert(schema, "Response")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		root := parse(t, out)
+		names := map[string]bool{}
+		var walk func(r avroType)
+		walk = func(r avroType) {
+			if r.Type != "record" {
+				t.Fatalf("expected record, got %q", r.Type)
+			}
+			if !avroName.MatchString(r.Name) || names[r.Name] {
+				t.Errorf("record name %q is invalid or repeated", r.Name)
+			}
+			names[r.Name] = true
+			for _, f := range r.Fields {
+				if !avroName.MatchString(f.Name) {
+					t.Errorf("field name %q is invalid", f.Name)
+				}
+				switch ft := f.Type.(type) {
+				case map[string]any:
+					nested := parse(t, ft)
+					if nested.Type == "array" {
+						if items, ok := nested.Items.(map[string]any); ok {
+							walk(parse(t, items))
+						}
+						continue
+					}
+					walk(nested)
+				case string:
+				default:
+					t.Errorf("%s.%s: unexpected type %v", r.Name, f.Name, f.Type)
+				}
+			}
+		}
+		walk(root)
+
+		pokemon := parse(t, field(t, parse(t, field(t, root, "data")), "pokemon_v2_pokemon"))
+		if pokemon.Name != "Response_data_pokemon_v2_pokemon" {
+			t.Errorf("expected record named after its path, got %q", pokemon.Name)
+		}
+		for name, want := range map[string]string{"name": "string", "height": "long"} {
+			if got := field(t, pokemon, name); got != want {
+				t.Errorf("%s: expected %q, got %v", name, want, got)
+			}
+		}
+		stats := parse(t, field(t, pokemon, "pokemon_v2_pokemonstats"))
+		if stats.Type != "array" {
+			t.Fatalf("expected array, got %q", stats.Type)
+		}
+		if stat := parse(t, stats.Items); field(t, stat, "base_stat") != "long" {
+			t.Errorf("expected long base_stat, got %v", field(t, stat, "base_stat"))
+		}
+	})
+
+	t.Run("converts nullable types to unions", func(t *testing.T) {
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"rate": map[string]any{"type": []any{"number", "null"}},
+			},
+		}
+		out, err := Convert(schema, "Response")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := json.Marshal(field(t, parse(t, out), "rate"))
+		if string(got) != `["null","double"]` {
+			t.Errorf(`expected ["null","double"], got %s`, got)
+		}
+	})
+
+	t.Run("defines a $ref record once and refers to it by name", func(t *testing.T) {
+		stat := map[string]any{"type": "object", "properties": map[string]any{"base_stat": map[string]any{"type": "integer"}}}
+		schema := map[string]any{
+			"type":  "object",
+			"$defs": map[string]any{"stat": stat},
+			"properties": map[string]any{
+				"attack":  map[string]any{"$ref": "#/$defs/stat"},
+				"defense": map[string]any{"$ref": "#/$defs/stat"},
+			},
+		}
+		out, err := Convert(schema, "Response")
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := parse(t, out)
+		if attack := parse(t, field(t, root, "attack")); attack.Name != "stat" {
+			t.Errorf("expected record stat, got %+v", attack)
+		}
+		if got := field(t, root, "defense"); got != "stat" {
+			t.Errorf("expected a reference to stat, got %v", got)
+		}
+	})
+
+	t.Run("rejects types Avro cannot express", func(t *testing.T) {
+		schema := map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"id": map[string]any{"type": []any{"string", "integer"}}},
+		}
+		if _, err := Convert(schema, "Response"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
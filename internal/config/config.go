@@ -0,0 +1,78 @@
+// Package config loads project defaults for the CLI from a .graphqlstubrc
+// file.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames lists the config file names looked for in each directory, in
+// order of preference.
+var FileNames = []string{".graphqlstubrc.json", ".graphqlstubrc.yaml"}
+
+// Config holds the values from a config file, keyed by long flag name.
+type Config struct {
+	// Path is the config file the values were read from.
+	Path   string
+	Values map[string]any
+}
+
+// Dir returns the directory holding the config file, against which relative
+// paths in it are resolved.
+func (c *Config) Dir() string {
+	return filepath.Dir(c.Path)
+}
+
+// Load finds the nearest config file, starting in the current directory and
+// walking up to the filesystem root. It returns nil, and no error, when there
+// is none.
+func Load() (*Config, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFrom(dir)
+}
+
+// LoadFrom is like Load but starts the search in dir.
+func LoadFrom(dir string) (*Config, error) {
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			data, err := os.ReadFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			return parse(path, data)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func parse(path string, data []byte) (*Config, error) {
+	values := map[string]any{}
+	var err error
+	if filepath.Ext(path) == ".yaml" {
+		err = yaml.Unmarshal(data, &values)
+	} else {
+		err = json.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &Config{Path: path, Values: values}, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("finds a config file in a parent directory", func(t *testing.T) {
+		root := t.TempDir()
+		path := writeConfig(t, root, ".graphqlstubrc.json", `{"overrides": "overrides.json", "seed": 42, "locale": "en"}`)
+		nested := filepath.Join(root, "a", "b")
+		if err := os.MkdirAll(nested, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		t.Chdir(nested)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg == nil {
+			t.Fatal("expected a config, got nil")
+		}
+		if cfg.Path != path || cfg.Dir() != root {
+			t.Errorf("expected config at %s, got %s", path, cfg.Path)
+		}
+		if cfg.Values["overrides"] != "overrides.json" || cfg.Values["seed"] != float64(42) {
+			t.Errorf("unexpected values %v", cfg.Values)
+		}
+	})
+
+	t.Run("prefers the nearest config file", func(t *testing.T) {
+		root := t.TempDir()
+		writeConfig(t, root, ".graphqlstubrc.json", `{"locale": "en"}`)
+		nested := filepath.Join(root, "nested")
+		if err := os.Mkdir(nested, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeConfig(t, nested, ".graphqlstubrc.yaml", "locale: fr\nseed: 7\n")
+
+		cfg, err := LoadFrom(nested)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Values["locale"] != "fr" || cfg.Values["seed"] != 7 {
+			t.Errorf("expected the nested YAML config, got %v", cfg.Values)
+		}
+	})
+
+	t.Run("returns nil without a config file", func(t *testing.T) {
+		cfg, err := LoadFrom(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg != nil {
+			t.Errorf("expected no config, got %s", cfg.Path)
+		}
+	})
+
+	t.Run("reports malformed config files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfig(t, dir, ".graphqlstubrc.json", `{"seed":`)
+		if _, err := LoadFrom(dir); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,70 @@
+// Package dictionary maps field names to the values stub fields may take,
+// keyed by glob patterns such as "*_name".
+package dictionary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// DictionaryMatcher finds the values allowed for a field by its name.
+type DictionaryMatcher struct {
+	entries  map[string][]any
+	patterns []string
+}
+
+// New returns a matcher for entries, which map field names or glob patterns,
+// in the syntax of path.Match, to their values. Every pattern must be valid
+// and have at least one value.
+func New(entries map[string][]any) (*DictionaryMatcher, error) {
+	m := &DictionaryMatcher{entries: entries}
+	for pattern, values := range entries {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("pattern %q: no values", pattern)
+		}
+		m.patterns = append(m.patterns, pattern)
+	}
+	// Patterns are tried in order, so sort them for a stable winner when
+	// several match.
+	sort.Strings(m.patterns)
+	return m, nil
+}
+
+// Load reads a dictionary from a JSON file such as
+// {"*_name": ["Pikachu", "Charizard"], "*_type": ["fire", "water"]}.
+func Load(file string) (*DictionaryMatcher, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string][]any
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	m, err := New(entries)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return m, nil
+}
+
+// Match returns the values for fieldName. An exact key wins over patterns;
+// otherwise the first matching pattern in sorted order is used.
+func (m *DictionaryMatcher) Match(fieldName string) ([]any, bool) {
+	if values, ok := m.entries[fieldName]; ok {
+		return values, true
+	}
+	for _, pattern := range m.patterns {
+		if ok, _ := path.Match(pattern, fieldName); ok {
+			return m.entries[pattern], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,58 @@
+package dictionary
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	m, err := Load("testdata/pokemon.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field string
+		want  []any
+	}{
+		{"name", []any{"Pikachu", "Charizard", "Bulbasaur"}},
+		{"primary_type", []any{"fire", "water", "grass"}},
+		{"nickname", nil},
+		{"count", nil},
+	}
+	for _, tt := range tests {
+		got, ok := m.Match(tt.field)
+		if ok != (tt.want != nil) || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %v, %v; want %v", tt.field, got, ok, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("prefers exact keys and then patterns in sorted order", func(t *testing.T) {
+		m, err := New(map[string][]any{
+			"*":      {"any"},
+			"*_name": {"pattern"},
+			"a_name": {"exact"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for field, want := range map[string]string{"a_name": "exact", "b_name": "any", "other": "any"} {
+			if got, _ := m.Match(field); got[0] != want {
+				t.Errorf("Match(%q) = %v, want %q", field, got, want)
+			}
+		}
+	})
+
+	t.Run("rejects bad patterns and empty lists", func(t *testing.T) {
+		for want, entries := range map[string]map[string][]any{
+			"syntax error": {"[": {"x"}},
+			"no values":    {"name": {}},
+		} {
+			if _, err := New(entries); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("expected %q error, got %v", want, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,135 @@
+// Package docgen renders human-readable documentation for the JSON Schemas
+// built from GraphQL queries.
+package docgen
+
+import (
+	"fmt"
+	"html/template"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+)
+
+// field is one documented schema node.
+type field struct {
+	Path  string
+	Type  string
+	Notes string
+}
+
+// Generate documents every field in schema as a table of path, type and
+// override notes. Format is "markdown" or "html". Paths are the dot-paths
+// used in overrides files, with "items" addressing list elements.
+func Generate(schema map[string]any, format string) (string, error) {
+	var fields []field
+	defs, _ := schema["$defs"].(map[string]any)
+	var walk func(node map[string]any, path string)
+	walk = func(node map[string]any, path string) {
+		if ref, ok := node["$ref"].(string); ok {
+			if def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any); ok {
+				node = def
+			}
+		}
+		t := graphqlschema.NodeType(node)
+		if path != "" {
+			fields = append(fields, field{Path: path, Type: describeType(node, t), Notes: notes(node)})
+		}
+		switch t {
+		case "object":
+			props, _ := node["properties"].(map[string]any)
+			for _, key := range slices.Sorted(maps.Keys(props)) {
+				if ps, ok := props[key].(map[string]any); ok {
+					walk(ps, join(path, key))
+				}
+			}
+		case "array":
+			if items, ok := node["items"].(map[string]any); ok {
+				walk(items, join(path, "items"))
+			}
+		}
+	}
+	walk(schema, "")
+
+	switch format {
+	case "markdown":
+		return markdown(fields), nil
+	case "html":
+		var b strings.Builder
+		if err := htmlTemplate.Execute(&b, fields); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("unsupported docs format %q (want markdown or html)", format)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func describeType(node map[string]any, t string) string {
+	switch t {
+	case "array":
+		return "list of items"
+	case "":
+		return "any"
+	}
+	if enum, ok := node["enum"].([]any); ok {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			values[i] = fmt.Sprint(v)
+		}
+		return fmt.Sprintf("%s (one of %s)", t, strings.Join(values, ", "))
+	}
+	return t
+}
+
+// notes describes the overrides applied to a node.
+func notes(node map[string]any) string {
+	var n []string
+	if node["x-stub-overridden"] == true {
+		n = append(n, "type overridden")
+	}
+	minimum, hasMin := node["minimum"].(float64)
+	maximum, hasMax := node["maximum"].(float64)
+	if hasMin && hasMax {
+		n = append(n, fmt.Sprintf("range %v to %v", minimum, maximum))
+	}
+	if p, ok := node["x-stub-null-prob"].(float64); ok {
+		n = append(n, fmt.Sprintf("null %v%% of the time", p*100))
+	}
+	return strings.Join(n, "; ")
+}
+
+func markdown(fields []field) string {
+	var b strings.Builder
+	b.WriteString("# Response schema\n\n")
+	b.WriteString("| Path | Type | Overrides |\n")
+	b.WriteString("| --- | --- | --- |\n")
+	for _, f := range fields {
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", f.Path, f.Type, strings.ReplaceAll(f.Notes, "|", `\|`))
+	}
+	return b.String()
+}
+
+var htmlTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Response schema</title></head>
+<body>
+<h1>Response schema</h1>
+<table>
+<thead><tr><th>Path</th><th>Type</th><th>Overrides</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr><td><code>{{.Path}}</code></td><td>{{.Type}}</td><td>{{.Notes}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
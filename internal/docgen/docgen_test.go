@@ -0,0 +1,59 @@
+package docgen
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+)
+
+func pokemonStatsSchema(t *testing.T, overrides map[string]string) map[string]any {
+	t.Helper()
+	query, err := os.ReadFile("../graphqlschema/testdata/pokemon_stats.graphql")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	schema, err := graphqlschema.BuildSchema(string(query), overrides, graphqlschema.WithOverrideMarkers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestGenerate(t *testing.T) {
+	overrides := map[string]string{"data.pokemon_v2_pokemon.height": "integer:1:100"}
+	schema := pokemonStatsSchema(t, overrides)
+
+	t.Run("documents paths, types and overrides in markdown", func(t *testing.T) {
+		doc, err := Generate(schema, "markdown")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"| `data.pokemon_v2_pokemon.pokemon_v2_pokemonstats.items.base_stat` | integer |",
+			"| `data.pokemon_v2_pokemon.pokemon_v2_pokemonstats` | list of items |",
+			"| `data.pokemon_v2_pokemon.height` | integer | type overridden; range 1 to 100 |",
+		} {
+			if !strings.Contains(doc, want) {
+				t.Errorf("expected markdown to contain %q, got:\n%s", want, doc)
+			}
+		}
+	})
+
+	t.Run("renders an html table", func(t *testing.T) {
+		doc, err := Generate(schema, "html")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(doc, "<td><code>data.pokemon_v2_pokemon.pokemon_v2_pokemonstats.items.base_stat</code></td><td>integer</td>") {
+			t.Errorf("expected an html row for base_stat, got:\n%s", doc)
+		}
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		if _, err := Generate(schema, "pdf"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,27 @@
+// Package envconfig reads CLI settings from environment variables.
+package envconfig
+
+import "strings"
+
+// OverridePrefix is the prefix of environment variables holding overrides.
+const OverridePrefix = "GRAPHQL_STUB_OVERRIDE_"
+
+// LoadOverridesFromEnv returns the overrides held in environ, a list of
+// "KEY=value" pairs as returned by os.Environ. Variables named prefix plus a
+// path with "__" for each dot, such as GRAPHQL_STUB_OVERRIDE_data__pokemon__name,
+// override the field at that path.
+func LoadOverridesFromEnv(prefix string, environ []string) map[string]string {
+	overrides := map[string]string{}
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok || name == "" {
+			continue
+		}
+		overrides[strings.ReplaceAll(name, "__", ".")] = value
+	}
+	return overrides
+}
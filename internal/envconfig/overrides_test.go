@@ -0,0 +1,23 @@
+package envconfig
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestLoadOverridesFromEnv(t *testing.T) {
+	environ := []string{
+		"HOME=/root",
+		"GRAPHQL_STUB_OVERRIDE_data__pokemon__name=string",
+		"GRAPHQL_STUB_OVERRIDE_data__pokemons__items__height=integer:1:100",
+		"GRAPHQL_STUB_OVERRIDE_=ignored",
+		"graphql_stub_override_data__id=ignored",
+	}
+	want := map[string]string{
+		"data.pokemon.name":          "string",
+		"data.pokemons.items.height": "integer:1:100",
+	}
+	if got := LoadOverridesFromEnv(OverridePrefix, environ); !maps.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
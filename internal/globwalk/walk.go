@@ -0,0 +1,123 @@
+// Package globwalk finds files under a directory by glob pattern, with "**"
+// matching any number of nested directories.
+package globwalk
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Walk returns the files under dir matching any include pattern and no
+// exclude pattern, sorted. Patterns use "/" separators and are relative to
+// dir; "**" as a whole segment matches zero or more directories, and other
+// segments use filepath.Match syntax. An exclude pattern without a "/" is
+// matched against file names alone, so "*_test.graphql" excludes such files
+// at any depth.
+func Walk(dir string, include, exclude []string) ([]string, error) {
+	var matches []string
+	for _, pattern := range include {
+		var found []string
+		var err error
+		if strings.Contains(pattern, "**") {
+			found, err = walkRecursive(dir, pattern)
+		} else {
+			found, err = filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, found...)
+	}
+
+	result := matches[:0]
+	for _, m := range matches {
+		rel, err := filepath.Rel(dir, m)
+		if err != nil {
+			return nil, err
+		}
+		excluded, err := matchAny(exclude, filepath.ToSlash(rel))
+		if err != nil {
+			return nil, err
+		}
+		if !excluded && isFile(m) {
+			result = append(result, m)
+		}
+	}
+	slices.Sort(result)
+	return slices.Compact(result), nil
+}
+
+// walkRecursive walks dir and returns the files whose relative path matches
+// pattern.
+func walkRecursive(dir, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	var found []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if Match(pattern, filepath.ToSlash(rel)) {
+			found = append(found, p)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Match reports whether a "/"-separated relative path matches pattern.
+// Malformed segments never match.
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], name[0])
+	return err == nil && ok && matchSegments(pattern[1:], name[1:])
+}
+
+// matchAny reports whether rel matches one of patterns, matching patterns
+// without a "/" against the base name.
+func matchAny(patterns []string, rel string) (bool, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return false, err
+		}
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		if Match(pattern, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isFile(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.Mode().IsRegular()
+}
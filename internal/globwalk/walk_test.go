@@ -0,0 +1,99 @@
+package globwalk
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// tree creates the given files, relative to a new temporary directory.
+func tree(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// rel returns paths relative to dir with "/" separators.
+func rel(t *testing.T, dir string, paths []string) []string {
+	t.Helper()
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		r, err := filepath.Rel(dir, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[i] = filepath.ToSlash(r)
+	}
+	return out
+}
+
+func TestWalk(t *testing.T) {
+	dir := tree(t,
+		"top.graphql",
+		"pokemon/get.graphql",
+		"pokemon/get_test.graphql",
+		"pokemon/moves/list.graphql",
+		"pokemon/notes.txt",
+	)
+
+	cases := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{
+			name:    "** matches nested subdirectories",
+			include: []string{"**/*.graphql"},
+			want:    []string{"pokemon/get.graphql", "pokemon/get_test.graphql", "pokemon/moves/list.graphql", "top.graphql"},
+		},
+		{
+			name:    "excluded files are absent",
+			include: []string{"**/*.graphql"},
+			exclude: []string{"*_test.graphql"},
+			want:    []string{"pokemon/get.graphql", "pokemon/moves/list.graphql", "top.graphql"},
+		},
+		{
+			name:    "exclude patterns with a / match the relative path",
+			include: []string{"**/*.graphql"},
+			exclude: []string{"pokemon/**"},
+			want:    []string{"top.graphql"},
+		},
+		{
+			name:    "simple patterns do not recurse",
+			include: []string{"*.graphql"},
+			want:    []string{"top.graphql"},
+		},
+		{
+			name:    "overlapping patterns list each file once",
+			include: []string{"pokemon/*.graphql", "**/get.graphql"},
+			want:    []string{"pokemon/get.graphql", "pokemon/get_test.graphql"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Walk(dir, tc.include, tc.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rel(t, dir, got); !slices.Equal(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("rejects malformed patterns", func(t *testing.T) {
+		if _, err := Walk(dir, []string{"**/[.graphql"}, nil); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package graphqlschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// MarshalCanonical encodes schema in the JSON Canonicalization Scheme of
+// RFC 8785: object keys sorted by their UTF-16 code units at every level, no
+// whitespace, minimal string escaping and numbers written as ECMAScript
+// does. Equal schemas always encode to the same bytes, so the output can be
+// hashed to address the schema by its content.
+func MarshalCanonical(schema map[string]any) ([]byte, error) {
+	// Round-trip through encoding/json so any Go value the schema holds,
+	// such as an int or a struct, is reduced to plain JSON values first.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := sortedMarshal(&out, v); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// sortedMarshal writes the canonical encoding of a decoded JSON value.
+func sortedMarshal(out *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		out.WriteString("null")
+	case bool:
+		out.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("number %s: %w", v, err)
+		}
+		out.WriteString(canonicalNumber(f))
+	case string:
+		writeCanonicalString(out, v)
+	case []any:
+		out.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := sortedMarshal(out, item); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.SortFunc(keys, compareUTF16)
+		out.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			writeCanonicalString(out, key)
+			out.WriteByte(':')
+			if err := sortedMarshal(out, v[key]); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value %T", v)
+	}
+	return nil
+}
+
+// compareUTF16 orders strings by their UTF-16 code units, as RFC 8785
+// requires. It differs from byte order only for characters beyond U+FFFF.
+func compareUTF16(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+}
+
+// writeCanonicalString writes s quoted, escaping only what JSON requires:
+// quotation marks, backslashes and control characters, using the short
+// escapes where they exist.
+func writeCanonicalString(out *bytes.Buffer, s string) {
+	out.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '\b':
+			out.WriteString(`\b`)
+		case '\f':
+			out.WriteString(`\f`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(out, `\u%04x`, r)
+			} else {
+				out.WriteRune(r)
+			}
+		}
+	}
+	out.WriteByte('"')
+}
+
+// canonicalNumber formats f as ECMAScript's Number.prototype.toString does:
+// plain decimals from 1e-6 up to 1e21, and exponents such as 1e+21 or 1.5e-7
+// outside that range.
+func canonicalNumber(f float64) string {
+	if f == 0 {
+		return "0" // also for -0
+	}
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	// Go pads exponents to two digits ("1e-07"); ECMAScript does not.
+	mantissa, exponent, _ := strings.Cut(s, "e")
+	sign, digits := exponent[:1], strings.TrimLeft(exponent[1:], "0")
+	return mantissa + "e" + sign + digits
+}
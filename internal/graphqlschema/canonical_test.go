@@ -0,0 +1,44 @@
+package graphqlschema
+
+import "testing"
+
+func TestMarshalCanonical(t *testing.T) {
+	t.Run("is independent of key insertion order", func(t *testing.T) {
+		a := map[string]any{}
+		a["type"] = "object"
+		a["properties"] = map[string]any{"name": map[string]any{"type": "string"}, "id": map[string]any{"type": "integer"}}
+		b := map[string]any{}
+		b["properties"] = map[string]any{"id": map[string]any{"type": "integer"}, "name": map[string]any{"type": "string"}}
+		b["type"] = "object"
+
+		ca, err := MarshalCanonical(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cb, err := MarshalCanonical(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"properties":{"id":{"type":"integer"},"name":{"type":"string"}},"type":"object"}`
+		if string(ca) != want || string(cb) != want {
+			t.Errorf("got %s and %s, want %s", ca, cb, want)
+		}
+	})
+
+	t.Run("follows RFC 8785 for strings and numbers", func(t *testing.T) {
+		// U+FB01 sorts before the emoji in UTF-8 but after it in UTF-16.
+		got, err := MarshalCanonical(map[string]any{
+			"numbers":    []any{0, 1, -1.5, 1e21, 1.5e-7, 123456789012, 0.000001, 4.50},
+			"string":     "<a & b>\n\"é\"\u001f",
+			"\uFB01":     true,
+			"\U0001F600": nil,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"numbers":[0,1,-1.5,1e+21,1.5e-7,123456789012,0.000001,4.5],"string":"<a & b>\n\"é\"\u001f","😀":null,"ﬁ":true}`
+		if string(got) != want {
+			t.Errorf("got  %s\nwant %s", got, want)
+		}
+	})
+}
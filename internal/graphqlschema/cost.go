@@ -0,0 +1,42 @@
+package graphqlschema
+
+import (
+	"errors"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// EstimateCost scores how expensive a query is likely to be to resolve. Each
+// leaf field costs 1 and each object 2 plus its fields. A list, detected by
+// field name as in BuildSchema, costs listMultiplier times one item, standing
+// in for the unknown number of items.
+func EstimateCost(query string, listMultiplier int) (int, error) {
+	doc, err := parseQuery(query)
+	if err != nil {
+		return 0, err
+	}
+	if len(doc.Operations) == 0 {
+		return 0, errors.New("no operation definition found in query")
+	}
+	return selectionSetCost(doc.Operations[0].SelectionSet, listMultiplier), nil
+}
+
+func selectionSetCost(selectionSet ast.SelectionSet, listMultiplier int) int {
+	cost := 0
+	for _, sel := range selectionSet {
+		field, ok := sel.(*ast.Field)
+		if !ok {
+			continue // skip fragments, as BuildSchema does
+		}
+		if len(field.SelectionSet) == 0 {
+			cost++
+			continue
+		}
+		objectCost := 2 + selectionSetCost(field.SelectionSet, listMultiplier)
+		if defaultListDetector.IsList(field.Name) {
+			objectCost *= listMultiplier
+		}
+		cost += objectCost
+	}
+	return cost
+}
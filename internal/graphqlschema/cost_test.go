@@ -0,0 +1,42 @@
+package graphqlschema
+
+import "testing"
+
+func TestEstimateCost(t *testing.T) {
+	t.Run("scores leaves, objects and lists", func(t *testing.T) {
+		// pokemon: 2 + name; pokemon_v2_pokemonstats: 10 × (2 + base_stat).
+		cost, err := EstimateCost("query Q { pokemon { name pokemon_v2_pokemonstats { base_stat } } }", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost != 33 {
+			t.Errorf("expected 33, got %d", cost)
+		}
+	})
+
+	t.Run("costs a flat query less than a nested list query", func(t *testing.T) {
+		flat, err := EstimateCost("query Q { pokemon { name height weight order base_experience } }", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nested, err := EstimateCost(`query Q {
+  pokemons {
+    pokemon_v2_pokemonstats {
+      pokemon_v2_stat { name }
+    }
+  }
+}`, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flat >= nested {
+			t.Errorf("expected flat query (%d) to cost less than nested lists (%d)", flat, nested)
+		}
+	})
+
+	t.Run("rejects documents without an operation", func(t *testing.T) {
+		if _, err := EstimateCost("fragment F on Pokemon { name }", 10); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package graphqlschema
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// WithDeduplication extracts structurally identical object schemas into the
+// root "$defs" map and replaces every occurrence with a "$ref".
+func WithDeduplication() SchemaOption {
+	return func(b *builder) {
+		b.deduplicate = true
+	}
+}
+
+// occurrence is an object schema found while walking, together with the
+// property name it was found under.
+type occurrence struct {
+	name string
+	key  string
+	size int
+}
+
+// deduplicateSchema repeatedly extracts the largest object schema that occurs
+// more than once, naming each definition after the property it first appears
+// under. Extracting the largest duplicate first means nested duplicates that
+// only repeat because their parent does are not given definitions of their own.
+func deduplicateSchema(schema map[string]any) map[string]any {
+	defs := map[string]any{}
+	for {
+		counts := map[string]int{}
+		var found []occurrence
+		collectObjects(schema, "", counts, &found)
+		for _, name := range slices.Sorted(maps.Keys(defs)) {
+			collectObjects(defs[name].(map[string]any), name, counts, &found)
+		}
+
+		var best *occurrence
+		for i := range found {
+			o := &found[i]
+			if counts[o.key] > 1 && (best == nil || o.size > best.size) {
+				best = o
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		name := best.name
+		for n := 2; defs[name] != nil; n++ {
+			name = best.name + strconv.Itoa(n)
+		}
+		var def map[string]any
+		_ = json.Unmarshal([]byte(best.key), &def)
+		defs[name] = def
+		ref := map[string]any{"$ref": "#/$defs/" + name}
+		replaceObjects(schema, best.key, ref)
+		for defName, d := range defs {
+			if defName != name {
+				replaceObjects(d.(map[string]any), best.key, ref)
+			}
+		}
+	}
+
+	if len(defs) > 0 {
+		schema["$defs"] = defs
+	}
+	return schema
+}
+
+// collectObjects records every object schema with properties below node,
+// keyed by its canonical JSON encoding. encoding/json sorts map keys, so
+// structurally identical schemas encode identically.
+func collectObjects(node map[string]any, name string, counts map[string]int, found *[]occurrence) {
+	if props, ok := node["properties"].(map[string]any); ok {
+		for _, key := range slices.Sorted(maps.Keys(props)) {
+			child, ok := props[key].(map[string]any)
+			if !ok {
+				continue
+			}
+			if items, ok := child["items"].(map[string]any); ok && child["type"] == "array" {
+				recordObject(items, key, counts, found)
+				collectObjects(items, key, counts, found)
+				continue
+			}
+			recordObject(child, key, counts, found)
+			collectObjects(child, key, counts, found)
+		}
+	}
+}
+
+func recordObject(node map[string]any, name string, counts map[string]int, found *[]occurrence) {
+	if _, ok := node["properties"].(map[string]any); !ok {
+		return
+	}
+	encoded, _ := json.Marshal(node)
+	key := string(encoded)
+	counts[key]++
+	if counts[key] == 1 {
+		*found = append(*found, occurrence{name: name, key: key, size: len(key)})
+	}
+}
+
+// replaceObjects swaps every object schema below node that encodes to key for ref.
+func replaceObjects(node map[string]any, key string, ref map[string]any) {
+	props, ok := node["properties"].(map[string]any)
+	if !ok {
+		return
+	}
+	for name, p := range props {
+		child, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		target, slot := child, func(v map[string]any) { props[name] = v }
+		if items, ok := child["items"].(map[string]any); ok && child["type"] == "array" {
+			target, slot = items, func(v map[string]any) { child["items"] = v }
+		}
+		if encoded, _ := json.Marshal(target); string(encoded) == key {
+			slot(maps.Clone(ref))
+			continue
+		}
+		replaceObjects(target, key, ref)
+	}
+}
@@ -0,0 +1,68 @@
+package graphqlschema
+
+import "testing"
+
+func TestDeduplication(t *testing.T) {
+	query := `query Q {
+		pokemon {
+			name
+			stats { base_stat effort stat { name } }
+		}
+		opponent {
+			level
+			stats { base_stat effort stat { name } }
+		}
+	}`
+
+	t.Run("extracts identical sub-schemas into $defs and references them", func(t *testing.T) {
+		schema, err := BuildSchema(query, nil, WithDeduplication())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defs, ok := schema["$defs"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected $defs, got %v", schema)
+		}
+		if len(defs) != 1 {
+			t.Fatalf("expected a single definition, got %v", defs)
+		}
+		def, ok := defs["stats"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected definition named stats, got %v", defs)
+		}
+		if _, ok := def["properties"].(map[string]any)["base_stat"]; !ok {
+			t.Errorf("definition is missing base_stat: %v", def)
+		}
+
+		data := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)
+		for _, parent := range []string{"pokemon", "opponent"} {
+			stats := data[parent].(map[string]any)["properties"].(map[string]any)["stats"].(map[string]any)
+			if stats["type"] != "array" {
+				t.Errorf("%s.stats type: got %v, want array", parent, stats["type"])
+			}
+			if ref := stats["items"].(map[string]any)["$ref"]; ref != "#/$defs/stats" {
+				t.Errorf("%s.stats items: got $ref %v", parent, ref)
+			}
+		}
+	})
+
+	t.Run("leaves schemas without duplicates unchanged", func(t *testing.T) {
+		schema, err := BuildSchema(`query Q { pokemon { name } trainer { age } }`, nil, WithDeduplication())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := schema["$defs"]; ok {
+			t.Errorf("expected no $defs, got %v", schema["$defs"])
+		}
+	})
+
+	t.Run("does not deduplicate by default", func(t *testing.T) {
+		schema, err := BuildSchema(query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := schema["$defs"]; ok {
+			t.Errorf("expected no $defs, got %v", schema["$defs"])
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package graphqlschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrorFormats holds the predefined error templates by name: "github" writes
+// GitHub Actions workflow commands, without a location when the query has no
+// file name, and "teamcity" TeamCity service messages.
+var ErrorFormats = map[string]string{
+	"github":   `::error{{if .File}} file={{githubProperty .File}}{{if .Line}},line={{.Line}},col={{.Column}}{{end}}{{end}}::{{githubMessage .Message}}`,
+	"teamcity": `##teamcity[buildProblem description='{{teamcity .File}}{{if .Line}}:{{.Line}}:{{.Column}}{{end}}: {{teamcity .Message}}']`,
+}
+
+// ErrorDetails is the data an error template is executed with. Line and
+// Column are 0 when the error has no location in the query.
+type ErrorDetails struct {
+	File         string
+	Line, Column int
+	Message      string
+}
+
+// TemplateError is an error from BuildSchema rendered by the template given
+// to WithErrorTemplate. It unwraps to the original error.
+type TemplateError struct {
+	Err  error
+	text string
+}
+
+// Error implements error, returning the rendered template.
+func (e *TemplateError) Error() string {
+	return e.text
+}
+
+// Unwrap returns the original error.
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// WithErrorTemplate renders the errors BuildSchema and its variants return
+// with tmpl, executed with an ErrorDetails once per error; a list of
+// validation errors renders one line each. Parse tmpl with
+// ParseErrorTemplate to use its escaping functions.
+func WithErrorTemplate(tmpl *template.Template) SchemaOption {
+	return func(b *builder) {
+		b.errorTemplate = tmpl
+	}
+}
+
+// WithSourceName names the query's file in the ErrorDetails of errors
+// rendered by WithErrorTemplate.
+func WithSourceName(name string) SchemaOption {
+	return func(b *builder) {
+		b.sourceName = name
+	}
+}
+
+// ParseErrorTemplate parses text as an error template for WithErrorTemplate.
+// Besides the built-in functions, it may call githubProperty and
+// githubMessage to escape GitHub Actions workflow command values, and
+// teamcity to escape TeamCity service message values.
+func ParseErrorTemplate(text string) (*template.Template, error) {
+	return template.New("error").Funcs(template.FuncMap{
+		"githubProperty": strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace,
+		"githubMessage":  strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace,
+		"teamcity":       strings.NewReplacer("|", "||", "'", "|'", "[", "|[", "]", "|]", "\r", "|r", "\n", "|n").Replace,
+	}).Parse(text)
+}
+
+// formatError renders err with the builder's error template, if it has one.
+func (b *builder) formatError(err error) error {
+	if err == nil || b.errorTemplate == nil {
+		return err
+	}
+	var lines []string
+	for _, details := range b.errorDetails(err) {
+		var text strings.Builder
+		if execErr := b.errorTemplate.Execute(&text, details); execErr != nil {
+			return fmt.Errorf("%w (rendering error template: %v)", err, execErr)
+		}
+		lines = append(lines, text.String())
+	}
+	return &TemplateError{Err: err, text: strings.Join(lines, "\n")}
+}
+
+// errorDetails describes err for an error template, one ErrorDetails per
+// error of a gqlerror.List.
+func (b *builder) errorDetails(err error) []ErrorDetails {
+	var syntaxErr *QuerySyntaxError
+	if errors.As(err, &syntaxErr) {
+		return []ErrorDetails{{File: b.sourceName, Line: syntaxErr.Line, Column: syntaxErr.Column, Message: syntaxErr.Message}}
+	}
+	var list gqlerror.List
+	if !errors.As(err, &list) {
+		var gqlErr *gqlerror.Error
+		if !errors.As(err, &gqlErr) {
+			return []ErrorDetails{{File: b.sourceName, Message: err.Error()}}
+		}
+		list = gqlerror.List{gqlErr}
+	}
+	details := make([]ErrorDetails, len(list))
+	for i, gqlErr := range list {
+		d := ErrorDetails{File: b.sourceName, Message: gqlErr.Message}
+		// Errors in an SDL file name it; those in the query do not.
+		if file, _ := gqlErr.Extensions["file"].(string); file != "" {
+			d.File = file
+		}
+		if len(gqlErr.Locations) > 0 {
+			d.Line, d.Column = gqlErr.Locations[0].Line, gqlErr.Locations[0].Column
+		}
+		details[i] = d
+	}
+	return details
+}
@@ -0,0 +1,54 @@
+package graphqlschema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithErrorTemplate(t *testing.T) {
+	const query = "query Q {\n  pokemon {\n    name\n  }\n  {\n}\n"
+	tmpl, err := ParseErrorTemplate(ErrorFormats["github"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = BuildSchema(query, nil, WithErrorTemplate(tmpl), WithSourceName("queries/q.graphql"))
+	want := "::error file=queries/q.graphql,line=5,col=3::Expected Name, found {"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %q", err, want)
+	}
+	var syntaxErr *QuerySyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("expected the error to unwrap to a *QuerySyntaxError, got %T", err)
+	}
+
+	_, err = BuildSchema(query, nil, WithErrorTemplate(tmpl))
+	want = "::error::Expected Name, found {"
+	if err == nil || err.Error() != want {
+		t.Errorf("without a source name: got %v, want %q", err, want)
+	}
+}
+
+func TestWithErrorTemplateValidationErrors(t *testing.T) {
+	const sdl = "type Query { pokemon: Pokemon } type Pokemon { name: String }"
+	tmpl, err := ParseErrorTemplate(ErrorFormats["teamcity"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = BuildSchemaFromSDL("{ pokemon { name [weight] height } }", sdl, nil, WithErrorTemplate(tmpl), WithSourceName("q.graphql"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := "##teamcity[buildProblem description='q.graphql:1:18: Expected Name, found |[']"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err, want)
+	}
+
+	_, err = BuildSchemaFromSDL("{ pokemon { weight height } }", sdl, nil, WithErrorTemplate(tmpl))
+	want = "##teamcity[buildProblem description=':1:13: Cannot query field \"weight\" on type \"Pokemon\".']\n" +
+		"##teamcity[buildProblem description=':1:20: Cannot query field \"height\" on type \"Pokemon\".']"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
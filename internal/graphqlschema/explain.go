@@ -0,0 +1,53 @@
+package graphqlschema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// reasonKeyword holds the explanation of how a leaf's type was chosen.
+const reasonKeyword = "x-stub-reason"
+
+// WithExplanations annotates every leaf schema node with "x-stub-reason",
+// explaining why it was given its type: the name pattern it matched, an
+// override, a @stubType directive or the GraphQL schema.
+func WithExplanations() SchemaOption {
+	return func(b *builder) {
+		b.explain = true
+	}
+}
+
+// explainInferred explains the type inferType gives fieldName, naming the
+// alternative of the pattern that matched.
+func explainInferred(fieldName string) string {
+	for _, rule := range []struct {
+		name string
+		re   *regexp.Regexp
+	}{
+		{"boolRE", boolRE},
+		{"floatRE", floatRE},
+		{"intRE", intRE},
+	} {
+		if rule.re.MatchString(fieldName) {
+			return fmt.Sprintf("matched %s pattern '%s'", rule.name, matchedAlternative(rule.re, fieldName))
+		}
+	}
+	return "default string type"
+}
+
+// matchedAlternative returns the first top-level alternative of re that
+// matches s. The patterns it is used with have no nested groups.
+func matchedAlternative(re *regexp.Regexp, s string) string {
+	source, caseless := strings.CutPrefix(re.String(), "(?i)")
+	for _, alternative := range strings.Split(source, "|") {
+		pattern := alternative
+		if caseless {
+			pattern = "(?i)" + pattern
+		}
+		if regexp.MustCompile(pattern).MatchString(s) {
+			return alternative
+		}
+	}
+	return source
+}
@@ -0,0 +1,55 @@
+package graphqlschema
+
+import "testing"
+
+func TestExplanations(t *testing.T) {
+	query := `query Q { pokemon { base_experience is_default capture_rate name weight @stubType(type: "number") height } }`
+	schema, err := BuildSchema(query, map[string]string{"data.pokemon.height": "string"}, WithExplanations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+
+	tests := map[string]string{
+		"base_experience": "matched intRE pattern 'experience'",
+		"is_default":      "matched boolRE pattern '^is_'",
+		"capture_rate":    "matched floatRE pattern 'rate'",
+		"name":            "default string type",
+		"weight":          "set by @stubType directive",
+		"height":          `override "string" applied from the overrides file`,
+	}
+	for field, want := range tests {
+		if got := props[field].(map[string]any)[reasonKeyword]; got != want {
+			t.Errorf("%s: expected reason %q, got %v", field, want, got)
+		}
+	}
+
+	t.Run("omitted by default", func(t *testing.T) {
+		schema, err := BuildSchema(query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+		if _, ok := props["name"].(map[string]any)[reasonKeyword]; ok {
+			t.Error("expected no reason without WithExplanations")
+		}
+	})
+
+	t.Run("names the GraphQL schema type", func(t *testing.T) {
+		sdl := `enum Color { RED } type Pokemon { name: String color: Color height: Int } type Query { pokemon: Pokemon }`
+		schema, err := BuildSchemaFromSDL(`query Q { pokemon { name color height } }`, sdl, nil, WithExplanations())
+		if err != nil {
+			t.Fatal(err)
+		}
+		props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+		for field, want := range map[string]string{
+			"name":   "declared as String in the GraphQL schema",
+			"color":  "enum Color in the GraphQL schema",
+			"height": "declared as Int in the GraphQL schema",
+		} {
+			if got := props[field].(map[string]any)[reasonKeyword]; got != want {
+				t.Errorf("%s: expected reason %q, got %v", field, want, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package graphqlschema
+
+import (
+	"maps"
+	"strings"
+)
+
+// FilterSchema returns a copy of a schema built by BuildSchema keeping only
+// some fields. When includes is non-empty, only fields at or below one of the
+// include paths, and the objects leading to them, are kept. Fields at or below
+// an exclude path are then removed. Paths use the override syntax, so "*"
+// matches one segment and "items" segments may be skipped. The input schema
+// is not modified.
+func FilterSchema(schema map[string]any, includes, excludes []string) map[string]any {
+	f := pathFilter{includes: splitPaths(includes), excludes: splitPaths(excludes)}
+	out, _ := f.filter(schema, nil, len(includes) == 0)
+	return out
+}
+
+type pathFilter struct {
+	includes, excludes [][]string
+}
+
+func splitPaths(paths []string) [][]string {
+	split := make([][]string, len(paths))
+	for i, p := range paths {
+		split[i] = strings.Split(p, ".")
+	}
+	return split
+}
+
+// filter filters the node at path and reports whether any of it is kept.
+// included is true when an ancestor matched an include path, so the whole
+// subtree is kept apart from exclusions.
+func (f pathFilter) filter(node map[string]any, path []string, included bool) (map[string]any, bool) {
+	if len(path) > 0 {
+		if f.matches(f.excludes, path) {
+			return nil, false
+		}
+		if !included {
+			if f.matches(f.includes, path) {
+				included = true
+			} else if !f.leadsToInclude(path) {
+				return nil, false
+			}
+		}
+	}
+
+	out := maps.Clone(node)
+	if props, ok := node["properties"].(map[string]any); ok {
+		kept := make(map[string]any, len(props))
+		for key, prop := range props {
+			child, ok := prop.(map[string]any)
+			if !ok {
+				continue
+			}
+			if child, ok := f.filter(child, append(path[:len(path):len(path)], key), included); ok {
+				kept[key] = child
+			}
+		}
+		if len(kept) == 0 && !included && len(path) > 0 {
+			return nil, false
+		}
+		out["properties"] = kept
+		return out, true
+	}
+	if items, ok := node["items"].(map[string]any); ok {
+		child, ok := f.filter(items, append(path[:len(path):len(path)], "items"), included)
+		if !ok {
+			return nil, false
+		}
+		out["items"] = child
+		return out, true
+	}
+	// A leaf is only kept when included, not merely on the way to an include.
+	return out, included || len(path) == 0
+}
+
+// matches reports whether path matches one of patterns exactly.
+func (f pathFilter) matches(patterns [][]string, path []string) bool {
+	for _, p := range patterns {
+		if matchOverridePath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// leadsToInclude reports whether path is an ancestor of an include path. A
+// trailing "items" segment is ignored, since list items lie on the way to the
+// fields below the list.
+func (f pathFilter) leadsToInclude(path []string) bool {
+	if path[len(path)-1] == "items" {
+		path = path[:len(path)-1]
+	}
+	for _, p := range f.includes {
+		for n := 1; n < len(p); n++ {
+			if matchOverridePath(p[:n], path) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package graphqlschema
+
+import (
+	"slices"
+	"testing"
+)
+
+// leafPaths lists the dot-paths of every leaf in a schema, sorted.
+func leafPaths(node map[string]any, path string) []string {
+	if props, ok := node["properties"].(map[string]any); ok {
+		var paths []string
+		for key, prop := range props {
+			p := key
+			if path != "" {
+				p = path + "." + key
+			}
+			paths = append(paths, leafPaths(prop.(map[string]any), p)...)
+		}
+		slices.Sort(paths)
+		return paths
+	}
+	if items, ok := node["items"].(map[string]any); ok {
+		return leafPaths(items, path+".items")
+	}
+	return []string{path}
+}
+
+func TestFilterSchema(t *testing.T) {
+	schema, err := BuildSchema(`query Q {
+		pokemon { name height }
+		pokemons { name stats { base_stat effort } }
+	}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := leafPaths(schema, "")
+
+	for _, tc := range []struct {
+		name               string
+		includes, excludes []string
+		want               []string
+	}{
+		{
+			name:     "keeps only the included leaf",
+			includes: []string{"data.pokemon.name"},
+			want:     []string{"data.pokemon.name"},
+		},
+		{
+			name:     "keeps everything below an included object",
+			includes: []string{"data.pokemons.stats"},
+			want:     []string{"data.pokemons.items.stats.items.base_stat", "data.pokemons.items.stats.items.effort"},
+		},
+		{
+			name:     "matches wildcards and skips items segments",
+			includes: []string{"data.*.name"},
+			want:     []string{"data.pokemon.name", "data.pokemons.items.name"},
+		},
+		{
+			name:     "removes excluded fields",
+			excludes: []string{"data.pokemons", "data.pokemon.height"},
+			want:     []string{"data.pokemon.name"},
+		},
+		{
+			name:     "applies excludes within includes",
+			includes: []string{"data.pokemons"},
+			excludes: []string{"data.pokemons.items.stats.effort"},
+			want:     []string{"data.pokemons.items.name", "data.pokemons.items.stats.items.base_stat"},
+		},
+		{
+			name: "keeps everything without paths",
+			want: all,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := leafPaths(FilterSchema(schema, tc.includes, tc.excludes), "")
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("leaves the input schema untouched", func(t *testing.T) {
+		FilterSchema(schema, []string{"data.pokemon.name"}, nil)
+		if got := leafPaths(schema, ""); !slices.Equal(got, all) {
+			t.Errorf("input schema was modified: %v", got)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package graphqlschema
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+// graphqlTypeKeyword names the GraphQL type an object variant describes.
+const graphqlTypeKeyword = "x-graphql-type"
+
+// fragmentSchema returns the schema of an inline fragment such as
+// "... on Fire { damage }": an object of the fragment's fields marked with
+// its type condition in "x-graphql-type", whose "__typename", if present,
+// must be that type. The variants of an interface or union field are listed
+// under the field's "anyOf": an object without a __typename matches them all,
+// so "oneOf" would reject it. Fragment fields appear in the response beside
+// the field's own, so they share its path.
+func (b *builder) fragmentSchema(fragment *ast.InlineFragment, path string) map[string]any {
+	node := b.selectionSetToSchema(fragment.SelectionSet, path)
+	node[graphqlTypeKeyword] = fragment.TypeCondition
+	typename := map[string]any{"type": "string", "const": fragment.TypeCondition}
+	node["properties"].(map[string]any)["__typename"] = b.annotate(typename, path+".__typename")
+	return node
+}
@@ -0,0 +1,43 @@
+package graphqlschema
+
+import "testing"
+
+func TestInlineFragments(t *testing.T) {
+	const query = `query Q { attack { name ... on Fire { damage } ... on Water { depth } } }`
+	schema, err := BuildSchema(query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["attack"].(map[string]any)
+	if _, ok := attack["properties"].(map[string]any)["name"]; !ok {
+		t.Errorf("expected the shared name field, got %v", attack["properties"])
+	}
+	variants, ok := attack["anyOf"].([]any)
+	if !ok || len(variants) != 2 {
+		t.Fatalf("expected two anyOf variants, got %v", attack["anyOf"])
+	}
+	for i, want := range []struct{ typename, field string }{{"Fire", "damage"}, {"Water", "depth"}} {
+		variant := variants[i].(map[string]any)
+		if variant[graphqlTypeKeyword] != want.typename {
+			t.Errorf("variant %d: got x-graphql-type %v, want %s", i, variant[graphqlTypeKeyword], want.typename)
+		}
+		if _, ok := variant["properties"].(map[string]any)[want.field]; !ok {
+			t.Errorf("variant %d: expected %s, got %v", i, want.field, variant["properties"])
+		}
+	}
+
+	t.Run("injects each variant's __typename", func(t *testing.T) {
+		schema, err := BuildSchema(query, nil, WithInjectedTypename())
+		if err != nil {
+			t.Fatal(err)
+		}
+		attack := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["attack"].(map[string]any)
+		fire := attack["anyOf"].([]any)[0].(map[string]any)["properties"].(map[string]any)["__typename"].(map[string]any)
+		if fire["const"] != "Fire" {
+			t.Errorf("expected a Fire __typename, got %v", fire)
+		}
+		if _, ok := attack["properties"].(map[string]any)["__typename"]; ok {
+			t.Error("expected no __typename outside the variants")
+		}
+	})
+}
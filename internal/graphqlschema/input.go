@@ -0,0 +1,87 @@
+package graphqlschema
+
+import (
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// WithMutationInputSchema also describes a mutation's variables, placing
+// their schema under "input" alongside "data". Variable types come from the
+// SDL when there is one; without it, built-in scalars are mapped directly and
+// types named like "PokemonInput" become objects with unknown fields.
+func WithMutationInputSchema() SchemaOption {
+	return func(b *builder) {
+		b.mutationInput = true
+	}
+}
+
+// VariablesKeyword holds the schema of an operation's variables under
+// WithVariablesSchema.
+const VariablesKeyword = "x-graphql-variables"
+
+// WithVariablesSchema describes the operation's variables, if it has any, in
+// a "x-graphql-variables" schema at the root. Unlike WithMutationInputSchema
+// it applies to every operation type and leaves the stub's properties alone,
+// so stubs generated from the schema are unchanged.
+func WithVariablesSchema() SchemaOption {
+	return func(b *builder) {
+		b.variables = true
+	}
+}
+
+// maxInputDepth bounds how deeply nested input objects are expanded, since
+// input types may refer to themselves.
+const maxInputDepth = 8
+
+// variablesSchema returns an object schema with a property per variable.
+// Variables with a default value, including null, record it under "default".
+func (b *builder) variablesSchema(vars ast.VariableDefinitionList) map[string]any {
+	properties := make(map[string]any, len(vars))
+	for _, v := range vars {
+		node := b.inputTypeToSchema(v.Type, 0)
+		if v.DefaultValue != nil {
+			if value, err := v.DefaultValue.Value(nil); err == nil {
+				node["default"] = value
+			}
+		}
+		properties[v.Variable] = node
+	}
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+// inputTypeToSchema converts a variable or input field type into a JSON
+// Schema node.
+func (b *builder) inputTypeToSchema(t *ast.Type, depth int) map[string]any {
+	if t.Elem != nil {
+		return map[string]any{"type": "array", "items": b.inputTypeToSchema(t.Elem, depth)}
+	}
+	if jsonType, ok := scalarTypes[t.NamedType]; ok {
+		return map[string]any{"type": jsonType}
+	}
+
+	var def *ast.Definition
+	if b.schema != nil {
+		def = b.schema.Types[t.NamedType]
+	}
+	switch {
+	case def != nil && def.Kind == ast.Enum:
+		values := make([]any, len(def.EnumValues))
+		for i, v := range def.EnumValues {
+			values[i] = v.Name
+		}
+		return map[string]any{"type": "string", "enum": values}
+	case def != nil && def.Kind == ast.InputObject:
+		properties := map[string]any{}
+		if depth < maxInputDepth {
+			for _, f := range def.Fields {
+				properties[f.Name] = b.inputTypeToSchema(f.Type, depth+1)
+			}
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	case def == nil && strings.HasSuffix(t.NamedType, "Input"):
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	// Custom scalars are opaque; strings are the safest stand-in.
+	return map[string]any{"type": "string"}
+}
@@ -0,0 +1,137 @@
+package graphqlschema
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestMutationInputSchema(t *testing.T) {
+	const mutation = `mutation Create($input: PokemonInput!, $withName: Boolean!) {
+  createPokemon(input: $input) { id name @include(if: $withName) }
+}`
+	inputProps := func(t *testing.T, schema map[string]any) map[string]any {
+		t.Helper()
+		input, ok := schema["properties"].(map[string]any)["input"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected an input schema, got %v", schema["properties"])
+		}
+		return input["properties"].(map[string]any)
+	}
+
+	t.Run("describes variables from the SDL", func(t *testing.T) {
+		schema, err := BuildSchemaFromSDL(mutation, loadSDL(t), nil, WithMutationInputSchema())
+		if err != nil {
+			t.Fatal(err)
+		}
+		vars := inputProps(t, schema)
+		if vars["withName"].(map[string]any)["type"] != "boolean" {
+			t.Errorf("withName: expected boolean, got %v", vars["withName"])
+		}
+		fields := vars["input"].(map[string]any)["properties"].(map[string]any)
+		for name, want := range map[string]string{"name": "string", "height": "integer", "kind": "string", "tags": "array", "stats": "array"} {
+			if got := fields[name].(map[string]any)["type"]; got != want {
+				t.Errorf("input.%s: expected %s, got %v", name, want, got)
+			}
+		}
+		if enum := fields["kind"].(map[string]any)["enum"].([]any); len(enum) != 3 {
+			t.Errorf("input.kind: expected enum values, got %v", enum)
+		}
+		stat := fields["stats"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)
+		if stat["base_stat"].(map[string]any)["type"] != "integer" {
+			t.Errorf("input.stats.items.base_stat: expected integer, got %v", stat["base_stat"])
+		}
+		if _, ok := schema["properties"].(map[string]any)["data"]; !ok {
+			t.Error("expected data alongside input")
+		}
+	})
+
+	t.Run("maps variables without an SDL", func(t *testing.T) {
+		schema, err := BuildSchema(mutation, nil, WithMutationInputSchema())
+		if err != nil {
+			t.Fatal(err)
+		}
+		vars := inputProps(t, schema)
+		if vars["withName"].(map[string]any)["type"] != "boolean" || vars["input"].(map[string]any)["type"] != "object" {
+			t.Errorf("unexpected variable schemas %v", vars)
+		}
+	})
+
+	t.Run("leaves queries and the default schema unchanged", func(t *testing.T) {
+		for _, tc := range []struct {
+			query string
+			opts  []SchemaOption
+		}{
+			{mutation, nil},
+			{`query Q($name: String!) { pokemon(name: $name) { id } }`, []SchemaOption{WithMutationInputSchema()}},
+		} {
+			schema, err := BuildSchema(tc.query, nil, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := schema["properties"].(map[string]any)["input"]; ok {
+				t.Errorf("expected no input schema for %q", tc.query)
+			}
+		}
+	})
+}
+
+func TestVariablesSchema(t *testing.T) {
+	t.Run("describes a query's variables at the root", func(t *testing.T) {
+		schema, err := BuildSchema(`query Get($name: String!, $limit: Int) { pokemon { name } }`, nil, WithVariablesSchema())
+		if err != nil {
+			t.Fatal(err)
+		}
+		vars, ok := schema[VariablesKeyword].(map[string]any)
+		if !ok {
+			t.Fatalf("expected a variables schema, got %v", schema)
+		}
+		props := vars["properties"].(map[string]any)
+		if props["name"].(map[string]any)["type"] != "string" || props["limit"].(map[string]any)["type"] != "integer" {
+			t.Errorf("unexpected variable schemas: %v", props)
+		}
+		if _, ok := schema["properties"].(map[string]any)["variables"]; ok {
+			t.Error("expected the stub's properties to be unchanged")
+		}
+	})
+
+	t.Run("is omitted for operations without variables", func(t *testing.T) {
+		schema, err := BuildSchema(`query Get { pokemon { name } }`, nil, WithVariablesSchema())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := schema[VariablesKeyword]; ok {
+			t.Errorf("expected no variables schema, got %v", schema[VariablesKeyword])
+		}
+	})
+}
+
+func TestVariableDefaults(t *testing.T) {
+	query, err := os.ReadFile("testdata/defaults.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := BuildSchema(string(query), nil, WithVariablesSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := schema[VariablesKeyword].(map[string]any)["properties"].(map[string]any)
+	for name, want := range map[string]any{"search": "pika", "limit": int64(10), "shiny": false, "region": nil} {
+		node := props[name].(map[string]any)
+		got, ok := node["default"]
+		if !ok || got != want {
+			t.Errorf("%s: expected default %#v, got %#v", name, want, node)
+		}
+	}
+	if _, ok := props["offset"].(map[string]any)["default"]; ok {
+		t.Error("offset: expected no default")
+	}
+
+	out, err := json.Marshal(props["limit"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"default":10,"type":"integer"}` {
+		t.Errorf("limit: got %s", out)
+	}
+}
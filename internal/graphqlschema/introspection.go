@@ -0,0 +1,173 @@
+package graphqlschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// introspectionSchema is the "__schema" of an introspection query response.
+type introspectionSchema struct {
+	QueryType        *introspectionName  `json:"queryType"`
+	MutationType     *introspectionName  `json:"mutationType"`
+	SubscriptionType *introspectionName  `json:"subscriptionType"`
+	Types            []introspectionType `json:"types"`
+}
+
+type introspectionName struct {
+	Name string `json:"name"`
+}
+
+type introspectionType struct {
+	Kind          string               `json:"kind"`
+	Name          string               `json:"name"`
+	Fields        []introspectionField `json:"fields"`
+	InputFields   []introspectionValue `json:"inputFields"`
+	Interfaces    []introspectionName  `json:"interfaces"`
+	EnumValues    []introspectionName  `json:"enumValues"`
+	PossibleTypes []introspectionName  `json:"possibleTypes"`
+}
+
+type introspectionField struct {
+	Name string               `json:"name"`
+	Args []introspectionValue `json:"args"`
+	Type introspectionTypeRef `json:"type"`
+}
+
+// introspectionValue is an argument or input field.
+type introspectionValue struct {
+	Name         string               `json:"name"`
+	Type         introspectionTypeRef `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+// String renders the reference in SDL syntax, such as "[Pokemon!]!".
+func (t introspectionTypeRef) String() string {
+	switch {
+	case t.Kind == "NON_NULL" && t.OfType != nil:
+		return t.OfType.String() + "!"
+	case t.Kind == "LIST" && t.OfType != nil:
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+// BuildSchemaFromIntrospection is like BuildSchemaFromSDL but takes the type
+// system from the response to a GraphQL introspection query, so no SDL file
+// is needed. The response may be wrapped in "data" or be the bare
+// {"__schema": ...} object.
+func BuildSchemaFromIntrospection(introspectionJSON []byte, querySource string, overrides map[string]string, opts ...SchemaOption) (map[string]any, error) {
+	sdl, err := introspectionToSDL(introspectionJSON)
+	if err != nil {
+		return nil, newBuilder(overrides, opts).formatError(err)
+	}
+	return buildSchemaFromSources(querySource, []*ast.Source{{Name: "introspection.graphql", Input: sdl}}, overrides, opts)
+}
+
+// introspectionToSDL renders an introspection response as SDL. Built-in
+// scalars and introspection types are left out; descriptions and directives
+// are not needed for type resolution and are dropped.
+func introspectionToSDL(introspectionJSON []byte) (string, error) {
+	var response struct {
+		Data *struct {
+			Schema *introspectionSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *introspectionSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(introspectionJSON, &response); err != nil {
+		return "", fmt.Errorf("parsing introspection response: %w", err)
+	}
+	schema := response.Schema
+	if response.Data != nil && response.Data.Schema != nil {
+		schema = response.Data.Schema
+	}
+	if schema == nil || schema.QueryType == nil {
+		return "", errors.New("introspection response has no __schema with a queryType")
+	}
+
+	var sdl strings.Builder
+	sdl.WriteString("schema {\n  query: " + schema.QueryType.Name + "\n")
+	if schema.MutationType != nil {
+		sdl.WriteString("  mutation: " + schema.MutationType.Name + "\n")
+	}
+	if schema.SubscriptionType != nil {
+		sdl.WriteString("  subscription: " + schema.SubscriptionType.Name + "\n")
+	}
+	sdl.WriteString("}\n")
+
+	for _, t := range schema.Types {
+		if strings.HasPrefix(t.Name, "__") || scalarTypes[t.Name] != "" {
+			continue
+		}
+		sdl.WriteString("\n")
+		switch t.Kind {
+		case "SCALAR":
+			fmt.Fprintf(&sdl, "scalar %s\n", t.Name)
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if t.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			fmt.Fprintf(&sdl, "%s %s", keyword, t.Name)
+			if len(t.Interfaces) > 0 {
+				names := make([]string, len(t.Interfaces))
+				for i, iface := range t.Interfaces {
+					names[i] = iface.Name
+				}
+				sdl.WriteString(" implements " + strings.Join(names, " & "))
+			}
+			sdl.WriteString(" {\n")
+			for _, f := range t.Fields {
+				sdl.WriteString("  " + f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, len(f.Args))
+					for i, a := range f.Args {
+						args[i] = a.String()
+					}
+					sdl.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				sdl.WriteString(": " + f.Type.String() + "\n")
+			}
+			sdl.WriteString("}\n")
+		case "UNION":
+			names := make([]string, len(t.PossibleTypes))
+			for i, p := range t.PossibleTypes {
+				names[i] = p.Name
+			}
+			fmt.Fprintf(&sdl, "union %s = %s\n", t.Name, strings.Join(names, " | "))
+		case "ENUM":
+			fmt.Fprintf(&sdl, "enum %s {\n", t.Name)
+			for _, v := range t.EnumValues {
+				sdl.WriteString("  " + v.Name + "\n")
+			}
+			sdl.WriteString("}\n")
+		case "INPUT_OBJECT":
+			fmt.Fprintf(&sdl, "input %s {\n", t.Name)
+			for _, f := range t.InputFields {
+				sdl.WriteString("  " + f.String() + "\n")
+			}
+			sdl.WriteString("}\n")
+		default:
+			return "", fmt.Errorf("type %s has unknown kind %q", t.Name, t.Kind)
+		}
+	}
+	return sdl.String(), nil
+}
+
+// String renders the value as an SDL argument or input field definition.
+func (v introspectionValue) String() string {
+	s := v.Name + ": " + v.Type.String()
+	if v.DefaultValue != nil {
+		s += " = " + *v.DefaultValue
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package graphqlschema
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildSchemaFromIntrospection(t *testing.T) {
+	introspection, err := os.ReadFile("testdata/pokemon.introspection.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("resolves field types from the introspection response", func(t *testing.T) {
+		query := `query Q { pokemons(limit: 3) { name weight is_legendary kind captured_at stats { base_stat } } }`
+		schema, err := BuildSchemaFromIntrospection(introspection, query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pokemons := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemons"].(map[string]any)
+		if pokemons["type"] != "array" {
+			t.Fatalf("pokemons type: got %v, want array", pokemons["type"])
+		}
+		props := pokemons["items"].(map[string]any)["properties"].(map[string]any)
+		for field, want := range map[string]string{"name": "string", "weight": "number", "is_legendary": "boolean", "kind": "string", "captured_at": "string"} {
+			if got := props[field].(map[string]any)["type"]; got != want {
+				t.Errorf("%s type: got %v, want %s", field, got, want)
+			}
+		}
+		if got := props["kind"].(map[string]any)["enum"]; !slices.Equal(got.([]any), []any{"FIRE", "WATER", "GRASS"}) {
+			t.Errorf("kind enum: got %v", got)
+		}
+		if props["stats"].(map[string]any)["type"] != "array" {
+			t.Errorf("stats type: got %v, want array", props["stats"])
+		}
+	})
+
+	t.Run("validates the query against the introspected types", func(t *testing.T) {
+		_, err := BuildSchemaFromIntrospection(introspection, `query Q { pokemon(name: "pikachu") { nickname } }`, nil)
+		if err == nil || !strings.Contains(err.Error(), "nickname") {
+			t.Errorf("expected an unknown field error, got %v", err)
+		}
+	})
+
+	t.Run("accepts a bare __schema object", func(t *testing.T) {
+		var response struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(introspection, &response); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := BuildSchemaFromIntrospection(response.Data, `query Q { pokemon(name: "x") { name } }`, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("fails without __schema", func(t *testing.T) {
+		if _, err := BuildSchemaFromIntrospection([]byte(`{"data": {}}`), `query Q { pokemon { name } }`, nil); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package graphqlschema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Lint rule IDs, which can be passed to LintQuery to suppress a rule.
+const (
+	RuleOverFetch       = "OVER_FETCH"
+	RuleEmptyList       = "EMPTY_LIST"
+	RuleDuplicateField  = "DUPLICATE_FIELD"
+	RuleTypename        = "TYPENAME"
+	RuleMissingTypename = "MISSING_TYPENAME"
+)
+
+// lintRules lists every rule ID, in the order they are documented.
+var lintRules = []string{RuleOverFetch, RuleEmptyList, RuleDuplicateField, RuleTypename, RuleMissingTypename}
+
+// Severity is how serious a lint finding is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// maxSubFields is the number of direct sub-fields above which an object is
+// reported as possibly over-fetched.
+const maxSubFields = 20
+
+// LintFinding is one problem found in a query. Path is the field's dot-path
+// in the response, as used for overrides.
+type LintFinding struct {
+	Rule     string
+	Severity Severity
+	Path     string
+	Line     int
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%d: %s %s %s: %s", f.Line, f.Severity, f.Rule, f.Path, f.Message)
+}
+
+// LintOption configures LintQuery.
+type LintOption func(*linter)
+
+// WithRequiredTypename enables the MISSING_TYPENAME rule, which warns about
+// objects that do not select __typename. Clients that normalise their cache
+// by __typename, such as Apollo Client, may misbehave without it. The rule is
+// off by default because most queries do not need it.
+func WithRequiredTypename() LintOption {
+	return func(l *linter) {
+		l.requireTypename = true
+	}
+}
+
+// WithLintSDL gives LintQuery the GraphQL SDL the query runs against, so
+// list fields are known from their types. It enables the EMPTY_LIST rule,
+// which a field's name alone cannot decide: "status" and "address" look
+// like lists to BuildSchema's name heuristics but seldom are.
+func WithLintSDL(sdl string) LintOption {
+	return func(l *linter) {
+		l.schema, l.err = gqlparser.LoadSchema(stubDirectives, &ast.Source{Name: "schema.graphql", Input: sdl})
+	}
+}
+
+// LintQuery checks every operation in a query for likely mistakes, skipping
+// the rules listed in suppress, which must be known rule IDs. Without
+// WithLintSDL, lists are detected by field name, as in BuildSchema. Fields
+// within fragments are not checked.
+func LintQuery(querySource string, suppress []string, opts ...LintOption) ([]LintFinding, error) {
+	for _, rule := range suppress {
+		if !slices.Contains(lintRules, rule) {
+			return nil, fmt.Errorf("unknown lint rule %q (want %s)", rule, strings.Join(lintRules, ", "))
+		}
+	}
+	doc, err := parseQuery(querySource)
+	if err != nil {
+		return nil, err
+	}
+	l := &linter{suppress: suppress}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+	for _, op := range doc.Operations {
+		var root *ast.Definition
+		if l.schema != nil {
+			root = l.schema.Types[rootTypeName(l.schema, op.Operation)]
+		}
+		l.selectionSet(op.SelectionSet, "data", root)
+	}
+	return l.findings, nil
+}
+
+// rootTypeName returns the name of the schema's root type for an operation.
+func rootTypeName(schema *ast.Schema, operation ast.Operation) string {
+	var def *ast.Definition
+	switch operation {
+	case ast.Mutation:
+		def = schema.Mutation
+	case ast.Subscription:
+		def = schema.Subscription
+	default:
+		def = schema.Query
+	}
+	if def == nil {
+		return ""
+	}
+	return def.Name
+}
+
+type linter struct {
+	suppress        []string
+	requireTypename bool
+	findings        []LintFinding
+
+	// schema is the SDL given with WithLintSDL, or nil; err is the error
+	// loading it.
+	schema *ast.Schema
+	err    error
+}
+
+func (l *linter) report(rule string, severity Severity, path string, pos *ast.Position, format string, args ...any) {
+	if slices.Contains(l.suppress, rule) {
+		return
+	}
+	line := 0
+	if pos != nil {
+		line = pos.Line
+	}
+	l.findings = append(l.findings, LintFinding{
+		Rule:     rule,
+		Severity: severity,
+		Path:     path,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// selectionSet lints the fields selected from parent, the object type they
+// belong to, which is nil when it is unknown.
+func (l *linter) selectionSet(selectionSet ast.SelectionSet, path string, parent *ast.Definition) {
+	seen := map[string]bool{}
+	for _, sel := range selectionSet {
+		field, ok := sel.(*ast.Field)
+		if !ok {
+			continue
+		}
+		key := field.Alias
+		if key == "" {
+			key = field.Name
+		}
+		fieldPath := path + "." + key
+
+		if seen[key] {
+			l.report(RuleDuplicateField, SeverityError, fieldPath, field.Position, "%q is selected more than once", key)
+		}
+		seen[key] = true
+
+		if field.Name == "__typename" {
+			l.report(RuleTypename, SeverityInfo, fieldPath, field.Position, "__typename is selected; stubs fill it with a random string")
+			continue
+		}
+
+		isList := defaultListDetector.IsList(field.Name)
+		var fieldType *ast.Definition
+		emptyList := false
+		if l.schema != nil {
+			isList = false
+			if def := fieldDefinition(parent, field.Name); def != nil {
+				fieldType = l.schema.Types[def.Type.Name()]
+				isList = def.Type.Elem != nil
+				// Only a list of objects needs sub-fields; one of scalars cannot
+				// have them.
+				emptyList = isList && fieldType != nil && fieldType.IsCompositeType()
+			}
+		}
+		switch n := len(field.SelectionSet); {
+		case n == 0 && emptyList:
+			l.report(RuleEmptyList, SeverityWarning, fieldPath, field.Position, "list field selects no sub-fields")
+		case n > maxSubFields:
+			l.report(RuleOverFetch, SeverityWarning, fieldPath, field.Position, "selects %d fields (more than %d); possible over-fetching", n, maxSubFields)
+		}
+		if l.requireTypename && len(field.SelectionSet) > 0 && !selectsTypename(field.SelectionSet) {
+			l.report(RuleMissingTypename, SeverityWarning, fieldPath, field.Position, "does not select __typename, which cache-normalising clients need")
+		}
+
+		childPath := fieldPath
+		if isList && len(field.SelectionSet) > 0 {
+			childPath += ".items"
+		}
+		l.selectionSet(field.SelectionSet, childPath, fieldType)
+	}
+}
+
+// fieldDefinition returns the definition of the named field of parent, or
+// nil when either is unknown.
+func fieldDefinition(parent *ast.Definition, name string) *ast.FieldDefinition {
+	if parent == nil {
+		return nil
+	}
+	return parent.Fields.ForName(name)
+}
+
+// selectsTypename reports whether a selection set selects __typename directly.
+func selectsTypename(selectionSet ast.SelectionSet) bool {
+	for _, sel := range selectionSet {
+		if field, ok := sel.(*ast.Field); ok && field.Name == "__typename" {
+			return true
+		}
+	}
+	return false
+}
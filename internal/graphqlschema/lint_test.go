@@ -0,0 +1,113 @@
+package graphqlschema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLintQuery(t *testing.T) {
+	rules := func(findings []LintFinding) []string {
+		var ids []string
+		for _, f := range findings {
+			ids = append(ids, f.Rule+" "+f.Path)
+		}
+		return ids
+	}
+	lint := func(t *testing.T, query string, suppress ...string) []string {
+		t.Helper()
+		findings, err := LintQuery(query, suppress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rules(findings)
+	}
+
+	t.Run("reports nothing for a clean query", func(t *testing.T) {
+		if got := lint(t, "query Q { pokemons { name stats { base_stat } } }"); len(got) != 0 {
+			t.Errorf("expected no findings, got %v", got)
+		}
+	})
+
+	t.Run("warns about objects with more than 20 sub-fields", func(t *testing.T) {
+		var fields []string
+		for i := range 21 {
+			fields = append(fields, fmt.Sprintf("field_%d", i))
+		}
+		query := "query Q { pokemon { " + strings.Join(fields, " ") + " } }"
+		if got := lint(t, query); len(got) != 1 || got[0] != "OVER_FETCH data.pokemon" {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	t.Run("warns about lists of objects without sub-fields given the SDL", func(t *testing.T) {
+		const sdl = `type Query { pokemon: Pokemon } type Pokemon { moves: [Move] tags: [String] status: String } type Move { name: String }`
+		findings, err := LintQuery("query Q { pokemon { moves tags status } }", nil, WithLintSDL(sdl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rules(findings); len(got) != 1 || got[0] != "EMPTY_LIST data.pokemon.moves" {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	t.Run("does not guess lists from field names", func(t *testing.T) {
+		if got := lint(t, "query Q { pokemon { moves status address } }"); len(got) != 0 {
+			t.Errorf("expected no findings, got %v", got)
+		}
+	})
+
+	t.Run("reports fields selected twice", func(t *testing.T) {
+		findings, err := LintQuery("query Q {\n  pokemon {\n    name\n    name\n  }\n}", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(findings) != 1 || findings[0].Rule != RuleDuplicateField || findings[0].Severity != SeverityError || findings[0].Line != 4 {
+			t.Errorf("got %+v", findings)
+		}
+	})
+
+	t.Run("notes __typename selections", func(t *testing.T) {
+		if got := lint(t, "query Q { pokemons { __typename name } }"); len(got) != 1 || got[0] != "TYPENAME data.pokemons.items.__typename" {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	t.Run("skips suppressed rules", func(t *testing.T) {
+		if got := lint(t, "query Q { pokemon { __typename moves } }", RuleTypename, RuleEmptyList); len(got) != 0 {
+			t.Errorf("expected no findings, got %v", got)
+		}
+	})
+
+	t.Run("rejects unknown rules", func(t *testing.T) {
+		if _, err := LintQuery("query Q { pokemon { name } }", []string{"BOGUS"}); err == nil || !strings.Contains(err.Error(), `"BOGUS"`) {
+			t.Errorf("expected an error naming BOGUS, got %v", err)
+		}
+	})
+}
+
+func TestLintMissingTypename(t *testing.T) {
+	const query = "query Q { pokemons { __typename name stats { base_stat } } trainer { name } }"
+	findings, err := LintQuery(query, []string{RuleTypename}, WithRequiredTypename())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Rule+" "+f.Path)
+	}
+	want := []string{"MISSING_TYPENAME data.pokemons.items.stats", "MISSING_TYPENAME data.trainer"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	findings, err = LintQuery(query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range findings {
+		if f.Rule == RuleMissingTypename {
+			t.Errorf("expected MISSING_TYPENAME to be off by default, got %v", f)
+		}
+	}
+}
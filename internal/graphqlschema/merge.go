@@ -0,0 +1,36 @@
+package graphqlschema
+
+// MergeBaseSchema carries hand-written additions to an earlier schema, such
+// as descriptions and examples, over to a freshly built one. Each node of
+// fresh takes the keywords the base node at the same path has and it lacks;
+// keywords it was built with, such as an override's range or an SDL enum,
+// reflect the current query and overrides, so they win. Fields only in fresh
+// keep just what was built for them, and fields only in base are dropped.
+// fresh is modified and returned.
+func MergeBaseSchema(fresh, base map[string]any) map[string]any {
+	mergeNode(fresh, base)
+	return fresh
+}
+
+func mergeNode(node, base map[string]any) {
+	for key, v := range base {
+		if _, built := node[key]; !built {
+			node[key] = v
+		}
+	}
+	if props, ok := node["properties"].(map[string]any); ok {
+		baseProps, _ := base["properties"].(map[string]any)
+		for key, p := range props {
+			child, isNode := p.(map[string]any)
+			baseChild, inBase := baseProps[key].(map[string]any)
+			if isNode && inBase {
+				mergeNode(child, baseChild)
+			}
+		}
+	}
+	if items, ok := node["items"].(map[string]any); ok {
+		if baseItems, ok := base["items"].(map[string]any); ok {
+			mergeNode(items, baseItems)
+		}
+	}
+}
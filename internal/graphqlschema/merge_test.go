@@ -0,0 +1,86 @@
+package graphqlschema
+
+import "testing"
+
+func TestMergeBaseSchema(t *testing.T) {
+	base := map[string]any{
+		"$id":  "pokemon.schema.json",
+		"type": "object",
+		"properties": map[string]any{
+			"data": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"pokemons": map[string]any{
+						"type":        "array",
+						"description": "Every pokemon",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"name":    map[string]any{"type": "string", "description": "Species name", "examples": []any{"Pikachu"}},
+								"height":  map[string]any{"type": "string", "x-stub-value": 7.0},
+								"removed": map[string]any{"type": "string", "description": "No longer queried"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	fresh, err := BuildSchema(`query Q { pokemons { name height weight } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := MergeBaseSchema(fresh, base)
+
+	if merged["$id"] != "pokemon.schema.json" || merged["$schema"] != SchemaDraft {
+		t.Errorf("expected root keywords from both schemas, got %v", merged)
+	}
+	pokemons := merged["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemons"].(map[string]any)
+	if pokemons["description"] != "Every pokemon" {
+		t.Errorf("pokemons: expected the base description, got %v", pokemons)
+	}
+	props := pokemons["items"].(map[string]any)["properties"].(map[string]any)
+
+	t.Run("preserves keywords added to the base", func(t *testing.T) {
+		name := props["name"].(map[string]any)
+		if name["description"] != "Species name" || len(name["examples"].([]any)) != 1 {
+			t.Errorf("name: expected the base description and examples, got %v", name)
+		}
+	})
+
+	t.Run("keeps the freshly built type", func(t *testing.T) {
+		height := props["height"].(map[string]any)
+		if height["type"] != "integer" || height["x-stub-value"] != 7.0 {
+			t.Errorf("height: expected an integer with the base x-stub-value, got %v", height)
+		}
+	})
+
+	t.Run("gives new fields only their built schema", func(t *testing.T) {
+		if weight := props["weight"].(map[string]any); len(weight) != 1 || weight["type"] != "integer" {
+			t.Errorf("weight: expected just an inferred type, got %v", weight)
+		}
+	})
+
+	t.Run("keeps freshly built keywords the base disagrees with", func(t *testing.T) {
+		base := map[string]any{"properties": map[string]any{"data": map[string]any{"properties": map[string]any{
+			"pokemon": map[string]any{"properties": map[string]any{
+				"height": map[string]any{"type": "integer", "minimum": 1.0, "maximum": 100.0, "x-stub-overridden": true, "description": "Decimetres"},
+			}},
+		}}}}
+		fresh, err := BuildSchema(`query Q { pokemon { height } }`, map[string]string{"data.pokemon.height": "integer:1:10"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		merged := MergeBaseSchema(fresh, base)
+		height := merged["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)["height"].(map[string]any)
+		if height["maximum"] != 10.0 || height["description"] != "Decimetres" {
+			t.Errorf("height: expected the new maximum and the base description, got %v", height)
+		}
+	})
+
+	t.Run("drops fields no longer in the query", func(t *testing.T) {
+		if _, ok := props["removed"]; ok {
+			t.Error("expected removed to be dropped")
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package graphqlschema
+
+import (
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// WithOperationMetadata describes the operation at the root of the schema,
+// for caching layers that need to know what generated it:
+// "x-operation-name", "x-operation-type", "x-operation-variables" (the
+// number of variables), "x-total-fields" (as counted by ComputeMetrics) and
+// "x-schema-generated-at", an RFC 3339 timestamp.
+func WithOperationMetadata() SchemaOption {
+	return func(b *builder) {
+		b.operationMetadata = true
+	}
+}
+
+// WithoutGeneratedAt omits "x-schema-generated-at" from the operation
+// metadata, so the schema is the same on every run.
+func WithoutGeneratedAt() SchemaOption {
+	return func(b *builder) {
+		b.omitGeneratedAt = true
+	}
+}
+
+// addOperationMetadata adds the WithOperationMetadata keys to schema.
+func (b *builder) addOperationMetadata(schema map[string]any, operation *ast.OperationDefinition) {
+	schema["x-operation-name"] = operation.Name
+	schema["x-operation-type"] = string(operation.Operation)
+	schema["x-operation-variables"] = len(operation.VariableDefinitions)
+	schema["x-total-fields"] = ComputeMetrics(schema).TotalFields
+	if !b.omitGeneratedAt {
+		schema["x-schema-generated-at"] = time.Now().UTC().Format(time.RFC3339)
+	}
+}
@@ -0,0 +1,53 @@
+package graphqlschema
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOperationMetadata(t *testing.T) {
+	const query = `mutation Catch($name: String!, $ball: String) { catch(name: $name) { id pokemon { name } } }`
+
+	schema, err := BuildSchema(query, nil, WithOperationMetadata())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"x-operation-name":      "Catch",
+		"x-operation-type":      "mutation",
+		"x-operation-variables": 2,
+		"x-total-fields":        4,
+	}
+	for key, v := range want {
+		if schema[key] != v {
+			t.Errorf("%s: expected %v, got %v", key, v, schema[key])
+		}
+	}
+	generatedAt, _ := schema["x-schema-generated-at"].(string)
+	if _, err := time.Parse(time.RFC3339, generatedAt); err != nil {
+		t.Errorf("x-schema-generated-at: expected an RFC 3339 timestamp, got %q", generatedAt)
+	}
+
+	t.Run("omits the timestamp with WithoutGeneratedAt", func(t *testing.T) {
+		schema, err := BuildSchema(query, nil, WithOperationMetadata(), WithoutGeneratedAt())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := schema["x-schema-generated-at"]; ok {
+			t.Error("expected no x-schema-generated-at")
+		}
+		if schema["x-operation-name"] != "Catch" {
+			t.Errorf("expected the remaining metadata, got %v", schema)
+		}
+	})
+
+	t.Run("is omitted by default", func(t *testing.T) {
+		schema, err := BuildSchema(query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := schema["x-operation-name"]; ok {
+			t.Error("expected no operation metadata")
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package graphqlschema
+
+import "strings"
+
+// overriddenKeyword marks leaf schemas whose type came from an override or a
+// @stubType directive rather than inference or the SDL.
+const overriddenKeyword = "x-stub-overridden"
+
+// WithOverrideMarkers keeps "x-stub-overridden" on leaves whose type came
+// from an override or a @stubType directive, so ComputeMetrics can tell them
+// from inferred ones, even after a round trip through a schema file. Without
+// it the marker is left out of the schema.
+func WithOverrideMarkers() SchemaOption {
+	return func(b *builder) {
+		b.overrideMarkers = true
+	}
+}
+
+// SchemaMetrics summarises the shape of a schema built from a query. Field
+// counts cover every selected field below "data"; the Inferred counts cover
+// leaves whose type was not overridden.
+type SchemaMetrics struct {
+	TotalFields     int `json:"total_fields"`
+	MaxDepth        int `json:"max_depth"`
+	InferredInteger int `json:"inferred_integer"`
+	InferredBoolean int `json:"inferred_boolean"`
+	InferredNumber  int `json:"inferred_number"`
+	InferredString  int `json:"inferred_string"`
+	Overridden      int `json:"overridden"`
+	ListFields      int `json:"list_fields"`
+}
+
+// ComputeMetrics walks a schema returned by BuildSchema and counts its fields.
+// Depth counts query nesting, so "data.pokemon.name" is at depth 2; list
+// items do not add a level. "$ref"s into "$defs" are followed, so a
+// deduplicated schema has the same metrics as the original.
+func ComputeMetrics(schema map[string]any) SchemaMetrics {
+	var m SchemaMetrics
+	defs, _ := schema["$defs"].(map[string]any)
+	var walk func(node map[string]any, depth int)
+	walk = func(node map[string]any, depth int) {
+		if ref, ok := node["$ref"].(string); ok {
+			if def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any); ok {
+				walk(def, depth)
+			}
+			return
+		}
+		switch NodeType(node) {
+		case "object":
+			props, _ := node["properties"].(map[string]any)
+			for _, p := range props {
+				if ps, ok := p.(map[string]any); ok {
+					m.TotalFields++
+					m.MaxDepth = max(m.MaxDepth, depth+1)
+					walk(ps, depth+1)
+				}
+			}
+		case "array":
+			m.ListFields++
+			if items, ok := node["items"].(map[string]any); ok {
+				walk(items, depth)
+			}
+		default:
+			if node[overriddenKeyword] == true {
+				m.Overridden++
+				return
+			}
+			switch NodeType(node) {
+			case "integer":
+				m.InferredInteger++
+			case "boolean":
+				m.InferredBoolean++
+			case "number":
+				m.InferredNumber++
+			case "string":
+				m.InferredString++
+			}
+		}
+	}
+	if data, ok := schema["properties"].(map[string]any)["data"].(map[string]any); ok {
+		walk(data, 0)
+	}
+	return m
+}
+
+// StripOverrideMarkers removes the markers WithOverrideMarkers keeps from
+// schema, in place, so a schema built for ComputeMetrics can be written out
+// as if it was built without the option.
+func StripOverrideMarkers(schema map[string]any) {
+	var strip func(v any)
+	strip = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			delete(v, overriddenKeyword)
+			for _, child := range v {
+				strip(child)
+			}
+		case []any:
+			for _, child := range v {
+				strip(child)
+			}
+		}
+	}
+	strip(schema)
+}
+
+// NodeType returns a schema node's type, ignoring "null" in type arrays.
+func NodeType(node map[string]any) string {
+	switch t := node["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
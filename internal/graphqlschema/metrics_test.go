@@ -0,0 +1,81 @@
+package graphqlschema
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	query, err := os.ReadFile("testdata/pokemon_stats.graphql")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	overrides := map[string]string{"data.pokemon_v2_pokemon.height": "number"}
+	want := SchemaMetrics{
+		TotalFields:     17,
+		MaxDepth:        4,
+		InferredInteger: 4,
+		InferredBoolean: 1,
+		InferredString:  4,
+		Overridden:      1,
+		ListFields:      3,
+	}
+
+	t.Run("counts fields, depth and type sources", func(t *testing.T) {
+		schema, err := BuildSchema(string(query), overrides, WithOverrideMarkers())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ComputeMetrics(schema); got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("follows $refs in deduplicated schemas", func(t *testing.T) {
+		schema, err := BuildSchema(string(query), overrides, WithDeduplication(), WithOverrideMarkers())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ComputeMetrics(schema); got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("counts @stubType directives as overrides", func(t *testing.T) {
+		schema, err := BuildSchema(`query Q { pokemon { name @stubType(type: "integer") } }`, nil, WithOverrideMarkers())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ComputeMetrics(schema); got.Overridden != 1 || got.InferredInteger != 0 {
+			t.Errorf("expected one override and no inferred integers, got %+v", got)
+		}
+	})
+
+	t.Run("strips the markers with StripOverrideMarkers", func(t *testing.T) {
+		schema, err := BuildSchema(string(query), overrides, WithOverrideMarkers())
+		if err != nil {
+			t.Fatal(err)
+		}
+		StripOverrideMarkers(schema)
+		unmarked, err := BuildSchema(string(query), overrides)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(schema, unmarked) {
+			t.Errorf("got %v, want %v", schema, unmarked)
+		}
+	})
+
+	t.Run("leaves the marker out without WithOverrideMarkers", func(t *testing.T) {
+		schema, err := BuildSchema(`query Q { pokemon { name @stubType(type: "integer") height } }`, map[string]string{"data.pokemon.height": "integer:1:9"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out, _ := json.Marshal(schema); strings.Contains(string(out), overriddenKeyword) {
+			t.Errorf("expected no %s, got %s", overriddenKeyword, out)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package graphqlschema
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// maxRenameDistance is the largest edit distance between two field names for
+// an override to be carried over from one to the other.
+const maxRenameDistance = 2
+
+// OverrideMigration reports how an overrides file fits a changed query.
+type OverrideMigration struct {
+	// Renamed lists overrides whose field was renamed or moved, with the path
+	// they should now be keyed by.
+	Renamed []OverrideRename `json:"renamed"`
+	// Stale lists override keys that no longer match any field.
+	Stale []string `json:"stale"`
+	// Added lists new field paths that may need an override.
+	Added []string `json:"added"`
+}
+
+// OverrideRename suggests moving the override keyed From to To.
+type OverrideRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// MigrateOverrides compares the leaf paths of two schemas built by
+// BuildSchema, before and after a query changed, and reports which overrides
+// still apply. An override whose field is gone is renamed to a new path whose
+// field name is within an edit distance of 2, preferring the closest name and
+// then the closest path; otherwise it is stale. Wildcard overrides are never
+// renamed, only reported stale when they match no new path.
+func MigrateOverrides(oldSchema, newSchema map[string]any, overrides map[string]string) OverrideMigration {
+	oldPaths := SchemaToTypeMap(oldSchema)
+	newPaths := SchemaToTypeMap(newSchema)
+
+	var added []string
+	for _, path := range slices.Sorted(maps.Keys(newPaths)) {
+		if _, ok := oldPaths[path]; !ok {
+			added = append(added, path)
+		}
+	}
+
+	m := OverrideMigration{Renamed: []OverrideRename{}, Stale: []string{}, Added: []string{}}
+	taken := map[string]bool{}
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		if matchesAnyPath(key, newPaths) {
+			continue
+		}
+		if strings.Contains(key, "*") {
+			m.Stale = append(m.Stale, key)
+			continue
+		}
+		to := closestRename(key, added, taken)
+		if to == "" {
+			m.Stale = append(m.Stale, key)
+			continue
+		}
+		taken[to] = true
+		m.Renamed = append(m.Renamed, OverrideRename{From: key, To: to})
+	}
+	for _, path := range added {
+		if !taken[path] {
+			m.Added = append(m.Added, path)
+		}
+	}
+	return m
+}
+
+// Apply returns a copy of overrides with the suggested renames applied. Stale
+// overrides are kept, so nothing is lost without review.
+func (m OverrideMigration) Apply(overrides map[string]string) map[string]string {
+	migrated := maps.Clone(overrides)
+	for _, r := range m.Renamed {
+		migrated[r.To] = overrides[r.From]
+		delete(migrated, r.From)
+	}
+	return migrated
+}
+
+func matchesAnyPath(key string, paths map[string]string) bool {
+	if _, ok := paths[key]; ok {
+		return true
+	}
+	pattern := strings.Split(key, ".")
+	for path := range paths {
+		if matchOverridePath(pattern, strings.Split(path, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// closestRename returns the candidate path not yet taken whose field name is
+// closest to key's, or "" when none is within maxRenameDistance.
+func closestRename(key string, candidates []string, taken map[string]bool) string {
+	name := fieldName(key)
+	best, bestName, bestPath := "", maxRenameDistance+1, 0
+	for _, c := range candidates {
+		if taken[c] {
+			continue
+		}
+		d := levenshtein(name, fieldName(c))
+		if d > maxRenameDistance {
+			continue
+		}
+		p := levenshtein(key, c)
+		if d < bestName || (d == bestName && p < bestPath) {
+			best, bestName, bestPath = c, d, p
+		}
+	}
+	return best
+}
+
+// fieldName returns the last field in a path, skipping "items" segments.
+func fieldName(path string) string {
+	segments := strings.Split(path, ".")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] != "items" {
+			return segments[i]
+		}
+	}
+	return path
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
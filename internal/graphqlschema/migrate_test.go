@@ -0,0 +1,76 @@
+package graphqlschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrateOverrides(t *testing.T) {
+	build := func(t *testing.T, query string) map[string]any {
+		t.Helper()
+		schema, err := BuildSchema(query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return schema
+	}
+	oldSchema := build(t, `query Q { pokemon { name height base_experience stats { effort } } }`)
+	newSchema := build(t, `query Q { pokemon { names height weight info { stats { effort } } } }`)
+	overrides := map[string]string{
+		"data.pokemon.name":               "string",
+		"data.pokemon.height":             "integer",
+		"data.pokemon.base_experience":    "integer",
+		"data.pokemon.stats.items.effort": "integer",
+		"data.*.missing":                  "string",
+	}
+
+	m := MigrateOverrides(oldSchema, newSchema, overrides)
+
+	wantRenamed := []OverrideRename{
+		{From: "data.pokemon.name", To: "data.pokemon.names"},
+		{From: "data.pokemon.stats.items.effort", To: "data.pokemon.info.stats.items.effort"},
+	}
+	if !reflect.DeepEqual(m.Renamed, wantRenamed) {
+		t.Errorf("renamed: got %v, want %v", m.Renamed, wantRenamed)
+	}
+	if want := []string{"data.*.missing", "data.pokemon.base_experience"}; !reflect.DeepEqual(m.Stale, want) {
+		t.Errorf("stale: got %v, want %v", m.Stale, want)
+	}
+	if want := []string{"data.pokemon.weight"}; !reflect.DeepEqual(m.Added, want) {
+		t.Errorf("added: got %v, want %v", m.Added, want)
+	}
+
+	t.Run("Apply moves renamed overrides and keeps the rest", func(t *testing.T) {
+		got := m.Apply(overrides)
+		want := map[string]string{
+			"data.pokemon.names":                   "string",
+			"data.pokemon.height":                  "integer",
+			"data.pokemon.base_experience":         "integer",
+			"data.pokemon.info.stats.items.effort": "integer",
+			"data.*.missing":                       "string",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if _, ok := overrides["data.pokemon.names"]; ok {
+			t.Error("Apply modified its input")
+		}
+	})
+}
+
+func TestLevenshtein(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"name", "name", 0},
+		{"name", "names", 1},
+		{"height", "weight", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	} {
+		if got := levenshtein(tc.a, tc.b); got != tc.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package graphqlschema
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// NormalizeQuery re-serializes a query in canonical form: whitespace and
+// comments are normalized, and fields, fragments, and arguments are sorted
+// alphabetically. Logically identical queries normalize to the same string.
+// Operations keep their order, since BuildSchema uses the first one.
+func NormalizeQuery(source string) (string, error) {
+	doc, err := parseQuery(source)
+	if err != nil {
+		return "", err
+	}
+	for _, op := range doc.Operations {
+		sortSelectionSet(op.SelectionSet)
+	}
+	for _, frag := range doc.Fragments {
+		sortSelectionSet(frag.SelectionSet)
+	}
+	slices.SortStableFunc(doc.Fragments, func(a, b *ast.FragmentDefinition) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	var out strings.Builder
+	formatter.NewFormatter(&out).FormatQueryDocument(doc)
+	return out.String(), nil
+}
+
+// selectionKey orders selections: fields by response key, then fragment
+// spreads by name, then inline fragments by type condition.
+func selectionKey(sel ast.Selection) string {
+	switch s := sel.(type) {
+	case *ast.Field:
+		return "0" + s.Alias
+	case *ast.FragmentSpread:
+		return "1" + s.Name
+	case *ast.InlineFragment:
+		return "2" + s.TypeCondition
+	}
+	return ""
+}
+
+func sortSelectionSet(set ast.SelectionSet) {
+	slices.SortStableFunc(set, func(a, b ast.Selection) int {
+		return cmp.Compare(selectionKey(a), selectionKey(b))
+	})
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			slices.SortStableFunc(s.Arguments, func(a, b *ast.Argument) int {
+				return cmp.Compare(a.Name, b.Name)
+			})
+			sortSelectionSet(s.SelectionSet)
+		case *ast.InlineFragment:
+			sortSelectionSet(s.SelectionSet)
+		}
+	}
+}
+
+// maxNormalizedDocs bounds how many parsed documents normalizedDocs keeps.
+const maxNormalizedDocs = 128
+
+// normalizedDocs caches parsed documents by their normalized source,
+// evicting the oldest once it holds maxNormalizedDocs.
+var normalizedDocs = struct {
+	sync.Mutex
+	docs  map[string]*ast.QueryDocument
+	order []string
+}{docs: map[string]*ast.QueryDocument{}}
+
+// normalizedDoc returns the parsed document for normalized, from the cache
+// if it is there.
+func normalizedDoc(normalized string) (*ast.QueryDocument, error) {
+	normalizedDocs.Lock()
+	doc, ok := normalizedDocs.docs[normalized]
+	normalizedDocs.Unlock()
+	if ok {
+		return doc, nil
+	}
+	doc, err := parseQuery(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizedDocs.Lock()
+	defer normalizedDocs.Unlock()
+	if cached, ok := normalizedDocs.docs[normalized]; ok {
+		return cached, nil
+	}
+	if len(normalizedDocs.order) == maxNormalizedDocs {
+		delete(normalizedDocs.docs, normalizedDocs.order[0])
+		normalizedDocs.order = normalizedDocs.order[1:]
+	}
+	normalizedDocs.docs[normalized] = doc
+	normalizedDocs.order = append(normalizedDocs.order, normalized)
+	return doc, nil
+}
+
+// BuildSchemaFromNormalized is like BuildSchema for a query already passed
+// through NormalizeQuery. Because identical queries normalize identically,
+// the most recently parsed documents are cached and repeated calls skip
+// re-parsing.
+func BuildSchemaFromNormalized(normalized string, overrides map[string]string, opts ...SchemaOption) (map[string]any, error) {
+	b := newBuilder(overrides, opts)
+	doc, err := normalizedDoc(normalized)
+	if err != nil {
+		return nil, b.formatError(err)
+	}
+	schema, err := b.build(doc)
+	return schema, b.formatError(err)
+}
+
+// Fingerprint returns the hex SHA-256 of the normalized query, so it changes
+// only when the query changes meaning, not formatting.
+func Fingerprint(query string) (string, error) {
+	normalized, err := NormalizeQuery(query)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,121 @@
+package graphqlschema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	t.Run("produces identical output for equivalent queries", func(t *testing.T) {
+		pretty := `
+			# Fetch a pokemon
+			query GetPokemon($name: String!) {
+				pokemon(name: $name, limit: 1) {
+					name
+					height
+					stats { effort base_stat }
+				}
+			}`
+		minified := `query GetPokemon($name:String!){pokemon(limit:1,name:$name){stats{base_stat effort} height name}}`
+
+		a, err := NormalizeQuery(pretty)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := NormalizeQuery(minified)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a != b {
+			t.Errorf("normalized forms differ:\n%s\n---\n%s", a, b)
+		}
+	})
+
+	t.Run("sorts fields alphabetically", func(t *testing.T) {
+		got, err := NormalizeQuery(`query Q { pokemon { weight height name } }`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !(strings.Index(got, "height") < strings.Index(got, "name") && strings.Index(got, "name") < strings.Index(got, "weight")) {
+			t.Errorf("expected sorted fields, got:\n%s", got)
+		}
+	})
+
+	t.Run("distinguishes queries that select different fields", func(t *testing.T) {
+		a, _ := NormalizeQuery(`query Q { pokemon { name } }`)
+		b, _ := NormalizeQuery(`query Q { pokemon { height } }`)
+		if a == b {
+			t.Error("expected different normalized forms")
+		}
+	})
+
+	t.Run("returns parse errors", func(t *testing.T) {
+		if _, err := NormalizeQuery(`query Q { pokemon {`); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestBuildSchemaFromNormalized(t *testing.T) {
+	normalized, err := NormalizeQuery(`query Q { pokemon { height name } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		schema, err := BuildSchemaFromNormalized(normalized, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+		if props["height"].(map[string]any)["type"] != "integer" {
+			t.Errorf("height type: got %v", props["height"])
+		}
+	}
+}
+
+func TestNormalizedDocCacheIsBounded(t *testing.T) {
+	for i := range maxNormalizedDocs + 10 {
+		if _, err := BuildSchemaFromNormalized(fmt.Sprintf("query Q%d { pokemon { name } }", i), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	normalizedDocs.Lock()
+	defer normalizedDocs.Unlock()
+	if n := len(normalizedDocs.docs); n != maxNormalizedDocs {
+		t.Errorf("expected the cache to hold %d documents, got %d", maxNormalizedDocs, n)
+	}
+	if _, ok := normalizedDocs.docs["query Q0 { pokemon { name } }"]; ok {
+		t.Error("expected the oldest document to be evicted")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a, err := Fingerprint("query Q { pokemon { name height } }")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ignores formatting and field order", func(t *testing.T) {
+		b, err := Fingerprint("# reformatted\nquery Q {\n  pokemon {\n    height\n    name\n  }\n}\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a != b {
+			t.Errorf("expected equal fingerprints, got %s and %s", a, b)
+		}
+		if len(a) != 64 {
+			t.Errorf("expected a hex SHA-256, got %q", a)
+		}
+	})
+
+	t.Run("changes when the query changes", func(t *testing.T) {
+		b, err := Fingerprint("query Q { pokemon { name weight } }")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a == b {
+			t.Error("expected different fingerprints for different queries")
+		}
+	})
+}
@@ -0,0 +1,14 @@
+package graphqlschema
+
+// nullableKeyword records whether the GraphQL schema lets a leaf be null.
+const nullableKeyword = "x-nullable"
+
+// WithNullableAnnotations marks every leaf typed by the GraphQL schema with
+// "x-nullable": true for nullable types such as String, and false for
+// non-null ones such as String!. Leaves typed from field names are left
+// unmarked, as nothing is known about their nullability.
+func WithNullableAnnotations() SchemaOption {
+	return func(b *builder) {
+		b.nullableAnnotations = true
+	}
+}
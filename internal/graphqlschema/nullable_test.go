@@ -0,0 +1,37 @@
+package graphqlschema
+
+import "testing"
+
+func TestWithNullableAnnotations(t *testing.T) {
+	query := `query Q { pokemon(name: "pikachu") { id name height weight kind tags } }`
+	schema, err := BuildSchemaFromSDL(query, loadSDL(t), nil, WithNullableAnnotations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+	for field, want := range map[string]bool{
+		"id":     false,
+		"name":   false,
+		"height": true,
+		"weight": true,
+		"kind":   false,
+	} {
+		if got := props[field].(map[string]any)[nullableKeyword]; got != want {
+			t.Errorf("%s: got x-nullable %v, want %v", field, got, want)
+		}
+	}
+	if got := props["tags"].(map[string]any)["items"].(map[string]any)[nullableKeyword]; got != false {
+		t.Errorf("tags items: got x-nullable %v, want false for [String!]", got)
+	}
+
+	t.Run("leaves inferred leaves unannotated", func(t *testing.T) {
+		schema, err := BuildSchema(`query Q { pokemon { name } }`, nil, WithNullableAnnotations())
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)["name"].(map[string]any)
+		if _, ok := name[nullableKeyword]; ok {
+			t.Errorf("expected no x-nullable without an SDL, got %v", name)
+		}
+	})
+}
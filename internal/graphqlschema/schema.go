@@ -2,10 +2,16 @@ package graphqlschema
 
 import (
 	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/vektah/gqlparser/v2/ast"
-	"github.com/vektah/gqlparser/v2/parser"
 )
 
 var (
@@ -28,69 +34,581 @@ func inferType(fieldName string) string {
 	return "string"
 }
 
-func isListField(fieldName string) bool {
-	return listRE.MatchString(fieldName)
+// inferFormat returns the string format suggested by a field name: "cursor"
+// for pagination cursors such as end_cursor, endCursor, after and before.
+func inferFormat(fieldName string) (string, bool) {
+	name := strings.ToLower(fieldName)
+	if strings.HasSuffix(name, "cursor") || name == "after" || name == "before" {
+		return "cursor", true
+	}
+	return "", false
+}
+
+// stubTypeDirective returns the type given by a @stubType(type: "...") directive
+// on the field, if present.
+func stubTypeDirective(field *ast.Field) (string, bool) {
+	directive := field.Directives.ForName("stubType")
+	if directive == nil {
+		return "", false
+	}
+	arg := directive.Arguments.ForName("type")
+	if arg == nil || arg.Value == nil || arg.Value.Kind != ast.StringValue {
+		return "", false
+	}
+	return arg.Value.Raw, true
+}
+
+// ListDetector decides from a field's name whether it returns a list.
+type ListDetector interface {
+	IsList(fieldName string) bool
+}
+
+// RegexpListDetector treats fields whose names match Pattern as lists.
+type RegexpListDetector struct {
+	Pattern *regexp.Regexp
+}
+
+// IsList implements ListDetector.
+func (d RegexpListDetector) IsList(fieldName string) bool {
+	return d.Pattern.MatchString(fieldName)
+}
+
+// defaultListDetector recognises plural and connection-style field names.
+var defaultListDetector = RegexpListDetector{Pattern: listRE}
+
+// SchemaOption configures BuildSchema.
+type SchemaOption func(*builder)
+
+// WithListDetector replaces the default plural-name list detection with d.
+func WithListDetector(d ListDetector) SchemaOption {
+	return func(b *builder) {
+		b.listDetector = d
+	}
+}
+
+// WithFieldPaths annotates every schema node with "x-graphql-path", the
+// dot-path used to address it in an overrides file.
+func WithFieldPaths() SchemaOption {
+	return func(b *builder) {
+		b.fieldPaths = true
+	}
+}
+
+// WithFieldNames annotates every leaf schema node with "x-field-name", the
+// GraphQL field name, so the stub generator can pick values that suit it.
+func WithFieldNames() SchemaOption {
+	return func(b *builder) {
+		b.fieldNames = true
+	}
+}
+
+// WithRangeExamples adds "examples" to every numeric leaf with both a
+// "minimum" and a "maximum", listing the minimum, the midpoint and the
+// maximum, so documentation shows the valid range at a glance.
+func WithRangeExamples() SchemaOption {
+	return func(b *builder) {
+		b.rangeExamples = true
+	}
+}
+
+// WithOperationAliases overrides leaf types by field name within particular
+// operations: aliases maps an operation name to a map from field name to an
+// override value, such as {"GetPokemon": {"id": "integer"}}. Aliases for the
+// operation being built take precedence over the overrides map, while
+// @stubType directives still take precedence over both.
+func WithOperationAliases(aliases map[string]map[string]string) SchemaOption {
+	return func(b *builder) {
+		b.operationAliases = aliases
+	}
+}
+
+// WithStrictOverrides makes building fail when an override key matches no
+// leaf of the query, so stale overrides are caught instead of silently
+// ignored. The error lists every unused key. A key counts as used when it
+// matches a leaf even if an operation alias or another key set its type.
+func WithStrictOverrides() SchemaOption {
+	return func(b *builder) {
+		b.strictOverrides = true
+	}
+}
+
+// WithOverrideUsage records the override keys that match a leaf in used
+// rather than checking them under WithStrictOverrides, so a caller building
+// each operation of a document can check them once, with UnusedOverrides,
+// after building them all.
+func WithOverrideUsage(used map[string]bool) SchemaOption {
+	return func(b *builder) {
+		b.usedOverrides = used
+		b.sharedUsage = true
+	}
+}
+
+// UnusedOverrides returns the sorted override keys missing from used.
+func UnusedOverrides(overrides map[string]string, used map[string]bool) []string {
+	var unused []string
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		if !used[key] {
+			unused = append(unused, key)
+		}
+	}
+	return unused
+}
+
+// WithSchemaKeyword controls whether the root schema declares "$schema".
+// It is included by default; omit it when the schema will be embedded as a
+// property of another schema.
+func WithSchemaKeyword(include bool) SchemaOption {
+	return func(b *builder) {
+		b.omitSchemaKeyword = !include
+	}
+}
+
+// WithoutSchemaKeyword is shorthand for WithSchemaKeyword(false).
+func WithoutSchemaKeyword() SchemaOption {
+	return WithSchemaKeyword(false)
+}
+
+// builder holds the configuration for a single BuildSchema call.
+type builder struct {
+	overrides    map[string]string
+	wildcards    []string
+	listDetector ListDetector
+	deduplicate  bool
+	fieldPaths   bool
+	fieldNames   bool
+	explain      bool
+
+	// strictOverrides rejects override keys missing from usedOverrides, the
+	// keys that matched a leaf, unless sharedUsage leaves that to the caller.
+	strictOverrides bool
+	usedOverrides   map[string]bool
+	sharedUsage     bool
+
+	// overrideMarkers keeps overriddenKeyword on leaves, where it is
+	// otherwise only used while building them.
+	overrideMarkers bool
+
+	// rangeExamples adds examples spanning numeric leaves' ranges.
+	rangeExamples bool
+
+	// injectTypename gives every object below "data" a constant "__typename".
+	injectTypename bool
+
+	// nullableAnnotations marks SDL-typed leaves with "x-nullable".
+	nullableAnnotations bool
+
+	// errorTemplate renders returned errors, naming the query sourceName.
+	errorTemplate *template.Template
+	sourceName    string
+
+	// operationMetadata describes the operation at the root, with a
+	// generation timestamp unless omitGeneratedAt is set.
+	operationMetadata bool
+	omitGeneratedAt   bool
+
+	// omitSchemaKeyword drops "$schema" from the root schema.
+	omitSchemaKeyword bool
+
+	// operationName selects the operation to build; the first is used when
+	// it is empty.
+	operationName string
+
+	// operationAliases holds WithOperationAliases, and aliases the field
+	// types for the operation being built.
+	operationAliases map[string]map[string]string
+	aliases          map[string]string
+
+	// mutationInput adds the schema of a mutation's variables under "input",
+	// and variables adds the schema of any operation's under VariablesKeyword.
+	mutationInput bool
+	variables     bool
+
+	// err is the first invalid override found while building.
+	err error
+
+	// schema is the SDL the query was validated against, or nil when field
+	// types are inferred from names.
+	schema *ast.Schema
 }
 
-func selectionSetToSchema(selectionSet ast.SelectionSet, overrides map[string]string, currentPath string) map[string]any {
-	properties := map[string]any{}
+func (b *builder) selectionSetToSchema(selectionSet ast.SelectionSet, currentPath string) map[string]any {
+	properties := make(map[string]any, len(selectionSet))
+	var variants, required []any
 
 	for _, sel := range selectionSet {
+		if fragment, ok := sel.(*ast.InlineFragment); ok && fragment.TypeCondition != "" {
+			variants = append(variants, b.fragmentSchema(fragment, currentPath))
+			continue
+		}
 		field, ok := sel.(*ast.Field)
 		if !ok {
-			continue // skip fragments
+			continue // skip fragment spreads and untyped inline fragments
 		}
 
+		// The response, and so the override path, is keyed by the alias;
+		// inference still looks at the underlying field name.
+		key := field.Alias
+		if key == "" {
+			key = field.Name
+		}
 		name := field.Name
-		fieldPath := currentPath + "." + name
+		fieldPath := currentPath + "." + key
+
+		if field.Definition != nil {
+			node := b.typeToSchema(field, field.Definition.Type, fieldPath)
+			if description := extractDescription(field.Definition); description != "" && len(field.SelectionSet) == 0 {
+				node["description"] = description
+			}
+			properties[key] = node
+			if field.Definition.Type.NonNull && !slices.Contains(required, any(key)) {
+				required = append(required, key)
+			}
+			continue
+		}
 
 		if len(field.SelectionSet) > 0 {
 			childPath := fieldPath
-			if isListField(name) {
+			isList := b.listDetector.IsList(name)
+			if isList {
 				childPath = fieldPath + ".items"
 			}
-			childSchema := selectionSetToSchema(field.SelectionSet, overrides, childPath)
-			if isListField(name) {
-				properties[name] = map[string]any{"type": "array", "items": childSchema}
+			childSchema := b.selectionSetToSchema(field.SelectionSet, childPath)
+			b.addTypename(childSchema, field, childPath)
+			if isList {
+				properties[key] = b.annotate(map[string]any{"type": "array", "items": childSchema}, fieldPath)
 			} else {
-				properties[name] = childSchema
+				properties[key] = childSchema
 			}
 		} else {
-			t := inferType(name)
-			if overriddenType, ok := overrides[fieldPath]; ok {
-				t = overriddenType
-			}
-			properties[name] = map[string]any{"type": t}
+			properties[key] = b.leafSchema(field, inferType(name), b.inferredReason(name), fieldPath)
 		}
 	}
 
-	return map[string]any{"type": "object", "properties": properties}
+	node := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		// Non-null fields of the SDL are always in the response.
+		node["required"] = required
+	}
+	if len(variants) > 0 {
+		node["anyOf"] = variants
+	}
+	return b.annotate(node, currentPath)
+}
+
+// leafSchema returns the schema for a scalar field, applying overrides and
+// @stubType directives on top of the given type. reason explains the type for
+// WithExplanations.
+func (b *builder) leafSchema(field *ast.Field, t, reason, fieldPath string) map[string]any {
+	node := map[string]any{"type": t}
+	if format, ok := inferFormat(field.Name); ok && t == "string" {
+		node["format"] = format
+	}
+	if b.fieldNames {
+		node["x-field-name"] = field.Name
+	}
+	b.markOverrides(fieldPath)
+	if alias, ok := b.aliases[field.Name]; ok {
+		b.applyOverride(node, fieldPath, alias)
+		if node[overriddenKeyword] == true {
+			reason = fmt.Sprintf("operation alias %q applied to %s", alias, field.Name)
+		}
+	} else if override, ok := b.lookupOverride(fieldPath); ok {
+		b.applyOverride(node, fieldPath, override)
+		if node[overriddenKeyword] == true {
+			reason = fmt.Sprintf("override %q applied from the overrides file", override)
+		}
+	}
+	if directiveType, ok := stubTypeDirective(field); ok {
+		node["type"] = directiveType
+		node[overriddenKeyword] = true
+		reason = "set by @stubType directive"
+	}
+	if b.explain {
+		node[reasonKeyword] = reason
+	}
+	if !b.overrideMarkers {
+		delete(node, overriddenKeyword)
+	}
+	if b.rangeExamples {
+		addRangeExamples(node)
+	}
+	return b.annotate(node, fieldPath)
 }
 
+// addRangeExamples sets node's "examples" to its minimum, midpoint and
+// maximum when it has both bounds. Integer midpoints are rounded down.
+func addRangeExamples(node map[string]any) {
+	minimum, hasMin := node["minimum"].(float64)
+	maximum, hasMax := node["maximum"].(float64)
+	if !hasMin || !hasMax {
+		return
+	}
+	mid := minimum + (maximum-minimum)/2
+	if node["type"] == "integer" {
+		mid = math.Floor(mid)
+	}
+	node["examples"] = []any{minimum, mid, maximum}
+}
+
+// inferredReason explains the type inferred from a field's name, or returns
+// "" when explanations are off.
+func (b *builder) inferredReason(fieldName string) string {
+	if !b.explain {
+		return ""
+	}
+	return explainInferred(fieldName)
+}
+
+// valuesKeyword lists the values a field's stubs cycle through.
+const valuesKeyword = "x-stub-values"
+
+// nullProbKeyword holds a field's probability of being generated as null.
+const nullProbKeyword = "x-stub-null-prob"
+
+// applyOverride applies an overrides-file value to a leaf schema node. The
+// value is a type ("integer"), a numeric type with an inclusive range
+// ("integer:1:100", "number:0.5:1.0"), or a null probability ("null:0.8")
+// that keeps the node's type. "values:a,b,c" makes the field a string that
+// cycles through the listed values. Nodes whose type is overridden are
+// marked with "x-stub-overridden", kept for ComputeMetrics under
+// WithOverrideMarkers.
+func (b *builder) applyOverride(node map[string]any, fieldPath, override string) {
+	parts := strings.Split(override, ":")
+	switch {
+	case parts[0] == "values":
+		_, list, _ := strings.Cut(override, ":")
+		if list == "" {
+			b.fail("override for %s: %q must list at least one value", fieldPath, override)
+			return
+		}
+		var values []any
+		for _, v := range strings.Split(list, ",") {
+			values = append(values, v)
+		}
+		node["type"] = "string"
+		node[valuesKeyword] = values
+		node[overriddenKeyword] = true
+	case len(parts) == 1:
+		node["type"] = override
+		node[overriddenKeyword] = true
+	case parts[0] == "null" && len(parts) == 2:
+		p, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || p < 0 || p > 1 {
+			b.fail("override for %s: null probability %q must be a number between 0 and 1", fieldPath, parts[1])
+			return
+		}
+		node[nullProbKeyword] = p
+	case (parts[0] == "integer" || parts[0] == "number") && len(parts) == 3:
+		minimum, errMin := strconv.ParseFloat(parts[1], 64)
+		maximum, errMax := strconv.ParseFloat(parts[2], 64)
+		if errMin != nil || errMax != nil || minimum > maximum {
+			b.fail("override for %s: %q must give a numeric minimum no greater than the maximum", fieldPath, override)
+			return
+		}
+		node["type"] = parts[0]
+		node["minimum"] = minimum
+		node["maximum"] = maximum
+		node[overriddenKeyword] = true
+	default:
+		b.fail("override for %s: unrecognised value %q", fieldPath, override)
+	}
+}
+
+// fail records the first error found while building.
+func (b *builder) fail(format string, args ...any) {
+	if b.err == nil {
+		b.err = fmt.Errorf(format, args...)
+	}
+}
+
+// annotate adds builder-configured metadata to a schema node and returns it.
+func (b *builder) annotate(node map[string]any, path string) map[string]any {
+	if b.fieldPaths {
+		node["x-graphql-path"] = path
+	}
+	return node
+}
+
+// lookupOverride returns the override for fieldPath. An exact key wins;
+// otherwise the first matching wildcard key in sorted order is used.
+func (b *builder) lookupOverride(fieldPath string) (string, bool) {
+	if t, ok := b.overrides[fieldPath]; ok {
+		return t, true
+	}
+	path := strings.Split(fieldPath, ".")
+	for _, key := range b.wildcards {
+		if matchOverridePath(strings.Split(key, "."), path) {
+			return b.overrides[key], true
+		}
+	}
+	return "", false
+}
+
+// markOverrides records every override key matching the leaf at fieldPath as
+// used, including those shadowed by an alias or an earlier wildcard.
+func (b *builder) markOverrides(fieldPath string) {
+	if _, ok := b.overrides[fieldPath]; ok {
+		b.usedOverrides[fieldPath] = true
+	}
+	path := strings.Split(fieldPath, ".")
+	for _, key := range b.wildcards {
+		if matchOverridePath(strings.Split(key, "."), path) {
+			b.usedOverrides[key] = true
+		}
+	}
+}
+
+// checkUnusedOverrides fails the build under WithStrictOverrides when some
+// override keys matched no leaf.
+func (b *builder) checkUnusedOverrides() {
+	if !b.strictOverrides || b.sharedUsage {
+		return
+	}
+	if unused := UnusedOverrides(b.overrides, b.usedOverrides); len(unused) > 0 {
+		b.fail("overrides match no field of the query: %s", strings.Join(unused, ", "))
+	}
+}
+
+// matchOverridePath reports whether a wildcard override key matches a field
+// path. "*" matches exactly one segment, and "items" segments in the path are
+// transparent: the key may skip them, so "data.*.id" matches both
+// "data.pokemon.id" and "data.pokemons.items.id".
+func matchOverridePath(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if path[0] == "items" && pattern[0] != "items" && matchOverridePath(pattern, path[1:]) {
+		return true
+	}
+	if pattern[0] == "*" || pattern[0] == path[0] {
+		return matchOverridePath(pattern[1:], path[1:])
+	}
+	return false
+}
+
+// SchemaDraft is the JSON Schema draft that built schemas declare in "$schema".
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
 // BuildSchema parses a GraphQL query string and returns a JSON Schema as a nested map.
 // The overrides parameter maps dot-path field paths to JSON Schema type strings.
-func BuildSchema(querySource string, overrides map[string]string) (map[string]any, error) {
-	if overrides == nil {
-		overrides = map[string]string{}
+// encoding/json writes map keys in sorted order, so the marshaled schema is
+// byte-identical between runs and needs no ordering option.
+func BuildSchema(querySource string, overrides map[string]string, opts ...SchemaOption) (map[string]any, error) {
+	b := newBuilder(overrides, opts)
+	doc, err := parseQuery(querySource)
+	if err != nil {
+		return nil, b.formatError(err)
+	}
+	schema, err := b.build(doc)
+	return schema, b.formatError(err)
+}
+
+// BuildSchemaForOperation is like BuildSchema but describes the named
+// operation rather than the first one in the document.
+func BuildSchemaForOperation(querySource, operationName string, overrides map[string]string, opts ...SchemaOption) (map[string]any, error) {
+	return BuildSchema(querySource, overrides, append(opts, WithOperationName(operationName))...)
+}
+
+// WithOperationName builds the schema for the named operation rather than
+// the first one in the document.
+func WithOperationName(name string) SchemaOption {
+	return func(b *builder) {
+		b.operationName = name
 	}
+}
 
-	doc, err := parser.ParseQuery(&ast.Source{Input: querySource})
+// OperationNames returns the names of the operations in a query document, in
+// order. Anonymous operations have an empty name.
+func OperationNames(querySource string) ([]string, error) {
+	doc, err := parseQuery(querySource)
 	if err != nil {
 		return nil, err
 	}
+	names := make([]string, len(doc.Operations))
+	for i, op := range doc.Operations {
+		names[i] = op.Name
+	}
+	return names, nil
+}
 
+// OperationType returns whether the named operation, or the first one when
+// name is empty, is a "query", "mutation" or "subscription".
+func OperationType(querySource, name string) (string, error) {
+	doc, err := parseQuery(querySource)
+	if err != nil {
+		return "", err
+	}
+	if len(doc.Operations) == 0 {
+		return "", errors.New("no operation definition found in query")
+	}
+	operation := doc.Operations[0]
+	if name != "" {
+		if operation = doc.Operations.ForName(name); operation == nil {
+			return "", fmt.Errorf("no operation named %q found in query", name)
+		}
+	}
+	return string(operation.Operation), nil
+}
+
+func newBuilder(overrides map[string]string, opts []SchemaOption) *builder {
+	if overrides == nil {
+		overrides = map[string]string{}
+	}
+	b := &builder{overrides: overrides, usedOverrides: map[string]bool{}, listDetector: defaultListDetector}
+	for _, opt := range opts {
+		opt(b)
+	}
+	for key := range overrides {
+		if strings.Contains(key, "*") {
+			b.wildcards = append(b.wildcards, key)
+		}
+	}
+	slices.Sort(b.wildcards)
+	return b
+}
+
+func (b *builder) build(doc *ast.QueryDocument) (map[string]any, error) {
 	if len(doc.Operations) == 0 {
 		return nil, errors.New("no operation definition found in query")
 	}
 
 	operation := doc.Operations[0]
-	dataSchema := selectionSetToSchema(operation.SelectionSet, overrides, "data")
-
-	return map[string]any{
-		"$schema": "http://json-schema.org/draft-07/schema#",
-		"type":    "object",
-		"properties": map[string]any{
-			"data": dataSchema,
-		},
-	}, nil
+	if b.operationName != "" {
+		if operation = doc.Operations.ForName(b.operationName); operation == nil {
+			return nil, fmt.Errorf("no operation named %q found in query", b.operationName)
+		}
+	}
+	b.aliases = b.operationAliases[operation.Name]
+	dataSchema := b.selectionSetToSchema(operation.SelectionSet, "data")
+	b.checkUnusedOverrides()
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	properties := map[string]any{"data": dataSchema}
+	if b.mutationInput && operation.Operation == ast.Mutation {
+		properties["input"] = b.variablesSchema(operation.VariableDefinitions)
+	}
+	schema := map[string]any{
+		"$schema":    SchemaDraft,
+		"type":       "object",
+		"properties": properties,
+		"required":   []any{"data"},
+	}
+	if b.variables && len(operation.VariableDefinitions) > 0 {
+		schema[VariablesKeyword] = b.variablesSchema(operation.VariableDefinitions)
+	}
+	if b.omitSchemaKeyword {
+		delete(schema, "$schema")
+	}
+	if b.operationMetadata {
+		b.addOperationMetadata(schema, operation)
+	}
+	if b.deduplicate {
+		schema = deduplicateSchema(schema)
+	}
+	return schema, nil
 }
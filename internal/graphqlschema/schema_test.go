@@ -1,8 +1,12 @@
 package graphqlschema
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -17,6 +21,13 @@ func inferredType(t *testing.T, fieldName string) string {
 	return thing["properties"].(map[string]any)[fieldName].(map[string]any)["type"].(string)
 }
 
+// collectionDetector treats any field whose name mentions a collection as a list.
+type collectionDetector struct{}
+
+func (collectionDetector) IsList(fieldName string) bool {
+	return strings.Contains(fieldName, "collection")
+}
+
 func TestBuildSchema(t *testing.T) {
 	t.Run("wraps output in a JSON Schema envelope", func(t *testing.T) {
 		schema, err := BuildSchema("query GetPokemon { pokemon { name } }", nil)
@@ -150,6 +161,24 @@ func TestBuildSchema(t *testing.T) {
 		})
 	})
 
+	t.Run("custom list detector", func(t *testing.T) {
+		query := `query Q {
+			pokemon_collection { name }
+			moves { name }
+		}`
+		schema, err := BuildSchema(query, nil, WithListDetector(collectionDetector{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)
+		if got := props["pokemon_collection"].(map[string]any)["type"]; got != "array" {
+			t.Errorf("pokemon_collection type: got %v, want array", got)
+		}
+		if got := props["moves"].(map[string]any)["type"]; got != "object" {
+			t.Errorf("moves type: got %v, want object because the default detector is replaced", got)
+		}
+	})
+
 	t.Run("overrides", func(t *testing.T) {
 		t.Run("applies overrides to leaf field types on list fields", func(t *testing.T) {
 			query := `query Q {
@@ -213,6 +242,145 @@ func TestBuildSchema(t *testing.T) {
 			}
 		})
 
+		t.Run("wildcard matches both list items and object fields", func(t *testing.T) {
+			query := `query Q {
+				pokemons { id }
+				pokemon { id }
+			}`
+			schema, err := BuildSchema(query, map[string]string{"data.*.id": "string"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			dataProps := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)
+			listID := dataProps["pokemons"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)["id"].(map[string]any)
+			if listID["type"] != "string" {
+				t.Errorf("pokemons.items.id type: got %v, want string", listID["type"])
+			}
+			objectID := dataProps["pokemon"].(map[string]any)["properties"].(map[string]any)["id"].(map[string]any)
+			if objectID["type"] != "string" {
+				t.Errorf("pokemon.id type: got %v, want string", objectID["type"])
+			}
+		})
+
+		t.Run("wildcard matches exactly one segment", func(t *testing.T) {
+			query := `query Q { pokemon { stat { id } } }`
+			schema, err := BuildSchema(query, map[string]string{"data.*.id": "string"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)["stat"].(map[string]any)["properties"].(map[string]any)["id"].(map[string]any)
+			if id["type"] != "integer" {
+				t.Errorf("pokemon.stat.id type: got %v, want inferred integer", id["type"])
+			}
+		})
+
+		t.Run("exact keys take precedence over wildcards", func(t *testing.T) {
+			query := `query Q { pokemon { id } }`
+			overrides := map[string]string{"data.*.id": "string", "data.pokemon.id": "number"}
+			schema, err := BuildSchema(query, overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)["id"].(map[string]any)
+			if id["type"] != "number" {
+				t.Errorf("pokemon.id type: got %v, want number", id["type"])
+			}
+		})
+
+		t.Run("null: overrides keep the type and set a null probability", func(t *testing.T) {
+			query := `query Q { pokemon { description height } }`
+			overrides := map[string]string{"data.pokemon.description": "null:0.8"}
+			schema, err := BuildSchema(query, overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+			description := props["description"].(map[string]any)
+			if description["type"] != "string" || description["x-stub-null-prob"] != 0.8 {
+				t.Errorf("description: expected string with null probability 0.8, got %v", description)
+			}
+			if _, ok := props["height"].(map[string]any)["x-stub-null-prob"]; ok {
+				t.Error("height: expected no null probability")
+			}
+		})
+
+		t.Run("rejects null probabilities outside 0 to 1", func(t *testing.T) {
+			for _, value := range []string{"null:1.5", "null:often"} {
+				overrides := map[string]string{"data.pokemon.description": value}
+				if _, err := BuildSchema(`query Q { pokemon { description } }`, overrides); err == nil {
+					t.Errorf("%s: expected error, got nil", value)
+				}
+			}
+		})
+
+		t.Run("type:min:max overrides set a numeric range", func(t *testing.T) {
+			query := `query Q { pokemon { height rate } }`
+			overrides := map[string]string{
+				"data.pokemon.height": "integer:1:100",
+				"data.pokemon.rate":   "number:0.5:1.0",
+			}
+			schema, err := BuildSchema(query, overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+			height := props["height"].(map[string]any)
+			if height["type"] != "integer" || height["minimum"] != 1.0 || height["maximum"] != 100.0 {
+				t.Errorf("height: expected integer from 1 to 100, got %v", height)
+			}
+			rate := props["rate"].(map[string]any)
+			if rate["type"] != "number" || rate["minimum"] != 0.5 || rate["maximum"] != 1.0 {
+				t.Errorf("rate: expected number from 0.5 to 1.0, got %v", rate)
+			}
+		})
+
+		t.Run("adds range examples with WithRangeExamples", func(t *testing.T) {
+			query := `query Q { pokemon { height rate weight } }`
+			overrides := map[string]string{
+				"data.pokemon.height": "integer:1:100",
+				"data.pokemon.rate":   "number:0:1",
+			}
+			schema, err := BuildSchema(query, overrides, WithRangeExamples())
+			if err != nil {
+				t.Fatal(err)
+			}
+			props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+			if got, _ := props["height"].(map[string]any)["examples"].([]any); !slices.Equal(got, []any{1.0, 50.0, 100.0}) {
+				t.Errorf("height: expected examples [1 50 100], got %v", got)
+			}
+			if got, _ := props["rate"].(map[string]any)["examples"].([]any); !slices.Equal(got, []any{0.0, 0.5, 1.0}) {
+				t.Errorf("rate: expected examples [0 0.5 1], got %v", got)
+			}
+			if _, ok := props["weight"].(map[string]any)["examples"]; ok {
+				t.Error("weight: expected no examples without a range")
+			}
+		})
+
+		t.Run("rejects malformed ranges", func(t *testing.T) {
+			for _, value := range []string{"integer:100:1", "integer:low:high", "string:1:2", "integer:1"} {
+				overrides := map[string]string{"data.pokemon.height": value}
+				if _, err := BuildSchema(`query Q { pokemon { height } }`, overrides); err == nil {
+					t.Errorf("%s: expected error, got nil", value)
+				}
+			}
+		})
+
+		t.Run("values: overrides list the values to cycle through", func(t *testing.T) {
+			overrides := map[string]string{"data.pokemon.height": "values:Tall,Short:ish"}
+			schema, err := BuildSchema(`query Q { pokemon { height } }`, overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			height := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)["height"].(map[string]any)
+			values, _ := height["x-stub-values"].([]any)
+			if height["type"] != "string" || !slices.Equal(values, []any{"Tall", "Short:ish"}) {
+				t.Errorf("height: expected a string cycling through Tall and Short:ish, got %v", height)
+			}
+			if _, err := BuildSchema(`query Q { pokemon { height } }`, map[string]string{"data.pokemon.height": "values:"}); err == nil {
+				t.Error("expected error for an empty value list, got nil")
+			}
+		})
+
 		t.Run("falls back to inferred type when field is not in overrides", func(t *testing.T) {
 			query := `query Q { thing { is_hidden name } }`
 			overrides := map[string]string{"data.thing.is_hidden": "string"}
@@ -225,6 +393,57 @@ func TestBuildSchema(t *testing.T) {
 				t.Errorf("name type: got %v", props["name"].(map[string]any)["type"])
 			}
 		})
+
+		t.Run("keys aliased fields by their alias", func(t *testing.T) {
+			query := `query Q { stats: pokemon_v2_pokemonstats { base_stat } }`
+			baseStat := func(t *testing.T, overrides map[string]string) any {
+				t.Helper()
+				schema, err := BuildSchema(query, overrides)
+				if err != nil {
+					t.Fatal(err)
+				}
+				props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)
+				stats, ok := props["stats"].(map[string]any)
+				if !ok {
+					t.Fatalf("expected an aliased stats property, got %v", props)
+				}
+				items := stats["items"].(map[string]any)["properties"].(map[string]any)
+				return items["base_stat"].(map[string]any)["type"]
+			}
+			if got := baseStat(t, map[string]string{"data.stats.items.base_stat": "string"}); got != "string" {
+				t.Errorf("alias path: got %v, want string", got)
+			}
+			if got := baseStat(t, map[string]string{"data.pokemon_v2_pokemonstats.items.base_stat": "string"}); got != "integer" {
+				t.Errorf("field name path: got %v, want integer", got)
+			}
+		})
+	})
+
+	t.Run("stubType directive", func(t *testing.T) {
+		leafType := func(t *testing.T, query string, overrides map[string]string) any {
+			t.Helper()
+			schema, err := BuildSchema(query, overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+			return props["base_experience"].(map[string]any)["type"]
+		}
+
+		t.Run("overrides the inferred type", func(t *testing.T) {
+			got := leafType(t, `query Q { pokemon { base_experience @stubType(type: "string") } }`, nil)
+			if got != "string" {
+				t.Errorf("base_experience type: got %v, want string", got)
+			}
+		})
+
+		t.Run("takes precedence over file-based overrides", func(t *testing.T) {
+			overrides := map[string]string{"data.pokemon.base_experience": "boolean"}
+			got := leafType(t, `query Q { pokemon { base_experience @stubType(type: "number") } }`, overrides)
+			if got != "number" {
+				t.Errorf("base_experience type: got %v, want number", got)
+			}
+		})
 	})
 
 	t.Run("correctly handles the full pokemon_stats query", func(t *testing.T) {
@@ -277,3 +496,342 @@ func TestBuildSchema(t *testing.T) {
 		}
 	})
 }
+
+func BenchmarkBuildSchema(b *testing.B) {
+	query, err := os.ReadFile("testdata/pokemon_stats.graphql")
+	if err != nil {
+		b.Fatalf("reading fixture: %v", err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := BuildSchema(string(query), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildSchemaLarge(b *testing.B) {
+	var query strings.Builder
+	query.WriteString("query Large {\n")
+	for i := range 50 {
+		fmt.Fprintf(&query, "  field_%d {\n", i)
+		for j := range 10 {
+			fmt.Fprintf(&query, "    sub_field_%d\n", j)
+		}
+		query.WriteString("  }\n")
+	}
+	query.WriteString("}\n")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := BuildSchema(query.String(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFieldPaths(t *testing.T) {
+	query, err := os.ReadFile("testdata/pokemon_stats.graphql")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	schema, err := BuildSchema(string(query), nil, WithFieldPaths())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("annotates every leaf with the path used for overrides", func(t *testing.T) {
+		want := map[string]bool{
+			"data.pokemon_v2_pokemon.name":                                                      true,
+			"data.pokemon_v2_pokemon.base_experience":                                           true,
+			"data.pokemon_v2_pokemon.height":                                                    true,
+			"data.pokemon_v2_pokemon.weight":                                                    true,
+			"data.pokemon_v2_pokemon.pokemon_v2_pokemonstats.items.base_stat":                   true,
+			"data.pokemon_v2_pokemon.pokemon_v2_pokemonstats.items.effort":                      true,
+			"data.pokemon_v2_pokemon.pokemon_v2_pokemonstats.items.pokemon_v2_stat.name":        true,
+			"data.pokemon_v2_pokemon.pokemon_v2_pokemontypes.items.pokemon_v2_type.name":        true,
+			"data.pokemon_v2_pokemon.pokemon_v2_pokemonabilities.items.pokemon_v2_ability.name": true,
+			"data.pokemon_v2_pokemon.pokemon_v2_pokemonabilities.items.is_hidden":               true,
+		}
+		got := map[string]bool{}
+		var walk func(node map[string]any)
+		walk = func(node map[string]any) {
+			if props, ok := node["properties"].(map[string]any); ok {
+				for _, p := range props {
+					walk(p.(map[string]any))
+				}
+				return
+			}
+			if items, ok := node["items"].(map[string]any); ok {
+				walk(items)
+				return
+			}
+			path, ok := node["x-graphql-path"].(string)
+			if !ok {
+				t.Errorf("leaf without x-graphql-path: %v", node)
+			}
+			got[path] = true
+		}
+		walk(schema["properties"].(map[string]any)["data"].(map[string]any))
+		for path := range want {
+			if !got[path] {
+				t.Errorf("missing leaf path %s", path)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("got %d leaf paths, want %d: %v", len(got), len(want), got)
+		}
+	})
+
+	t.Run("annotates object and array nodes", func(t *testing.T) {
+		data := schema["properties"].(map[string]any)["data"].(map[string]any)
+		if data["x-graphql-path"] != "data" {
+			t.Errorf("data path: got %v", data["x-graphql-path"])
+		}
+		stats := data["properties"].(map[string]any)["pokemon_v2_pokemon"].(map[string]any)["properties"].(map[string]any)["pokemon_v2_pokemonstats"].(map[string]any)
+		if stats["x-graphql-path"] != "data.pokemon_v2_pokemon.pokemon_v2_pokemonstats" {
+			t.Errorf("stats path: got %v", stats["x-graphql-path"])
+		}
+		if items := stats["items"].(map[string]any); items["x-graphql-path"] != "data.pokemon_v2_pokemon.pokemon_v2_pokemonstats.items" {
+			t.Errorf("stats items path: got %v", items["x-graphql-path"])
+		}
+	})
+
+	t.Run("omits annotations by default", func(t *testing.T) {
+		schema, err := BuildSchema(string(query), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := schema["properties"].(map[string]any)["data"].(map[string]any)["x-graphql-path"]; ok {
+			t.Error("expected no x-graphql-path annotation")
+		}
+	})
+}
+
+func TestMarshaledSchemaIsStable(t *testing.T) {
+	const query = "query Q { pokemon { weight name height stats { effort base_stat } } }"
+	marshal := func(t *testing.T) string {
+		t.Helper()
+		schema, err := BuildSchema(query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(out)
+	}
+
+	first := marshal(t)
+	for range 10 {
+		if got := marshal(t); got != first {
+			t.Fatalf("expected byte-identical output, got:\n%s\n---\n%s", first, got)
+		}
+	}
+	if !(strings.Index(first, `"height"`) < strings.Index(first, `"name"`) && strings.Index(first, `"name"`) < strings.Index(first, `"weight"`)) {
+		t.Errorf("expected properties in alphabetical order, got:\n%s", first)
+	}
+}
+
+func TestSchemaKeyword(t *testing.T) {
+	const query = "query Q { pokemon { name } }"
+
+	t.Run("declares draft-07 by default", func(t *testing.T) {
+		schema, err := BuildSchema(query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := schema["$schema"]; got != "http://json-schema.org/draft-07/schema#" {
+			t.Errorf("expected draft-07 $schema, got %v", got)
+		}
+	})
+
+	t.Run("omits $schema when disabled", func(t *testing.T) {
+		for name, opt := range map[string]SchemaOption{
+			"WithSchemaKeyword(false)": WithSchemaKeyword(false),
+			"WithoutSchemaKeyword":     WithoutSchemaKeyword(),
+		} {
+			schema, err := BuildSchema(query, nil, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := schema["$schema"]; ok {
+				t.Errorf("%s: expected no $schema, got %v", name, schema["$schema"])
+			}
+		}
+	})
+}
+
+func TestBuildSchemaForOperation(t *testing.T) {
+	const query = `query GetPokemon { pokemon { name } }
+query GetTrainer { trainer { badge_count } }`
+
+	t.Run("lists operation names in order", func(t *testing.T) {
+		names, err := OperationNames(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(names, []string{"GetPokemon", "GetTrainer"}) {
+			t.Errorf("expected both operations, got %v", names)
+		}
+	})
+
+	t.Run("builds the named operation", func(t *testing.T) {
+		schema, err := BuildSchemaForOperation(query, "GetTrainer", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)
+		if _, ok := data["trainer"]; !ok {
+			t.Errorf("expected trainer, got %v", data)
+		}
+		if _, ok := data["pokemon"]; ok {
+			t.Error("expected no fields from GetPokemon")
+		}
+	})
+
+	t.Run("fails for unknown operation names", func(t *testing.T) {
+		if _, err := BuildSchemaForOperation(query, "GetMove", nil); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("reports operation types", func(t *testing.T) {
+		const doc = query + "\nsubscription OnBattle { battle { turn } }"
+		for name, want := range map[string]string{"": "query", "OnBattle": "subscription"} {
+			got, err := OperationType(doc, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("%q: got %q, want %q", name, got, want)
+			}
+		}
+		if _, err := OperationType(doc, "GetMove"); err == nil {
+			t.Error("expected an error for an unknown operation")
+		}
+	})
+}
+
+func TestFieldNames(t *testing.T) {
+	schema, err := BuildSchema(`query Q { pokemons { created_at sprite_url } }`, nil, WithFieldNames())
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemons"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)
+	for _, name := range []string{"created_at", "sprite_url"} {
+		if got := item[name].(map[string]any)["x-field-name"]; got != name {
+			t.Errorf("%s: expected x-field-name %q, got %v", name, name, got)
+		}
+	}
+}
+
+func TestCursorFormat(t *testing.T) {
+	schema, err := BuildSchema(`query Q { pokemons { pageInfo { endCursor has_next_page } edges { cursor } } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pokemons := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemons"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)
+	pageInfo := pokemons["pageInfo"].(map[string]any)["properties"].(map[string]any)
+	if got := pageInfo["endCursor"].(map[string]any)["format"]; got != "cursor" {
+		t.Errorf("endCursor: expected format cursor, got %v", got)
+	}
+	if _, ok := pageInfo["has_next_page"].(map[string]any)["format"]; ok {
+		t.Error("has_next_page: expected no format")
+	}
+	edge := pokemons["edges"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)
+	if got := edge["cursor"].(map[string]any)["format"]; got != "cursor" {
+		t.Errorf("cursor: expected format cursor, got %v", got)
+	}
+}
+
+func TestOperationAliases(t *testing.T) {
+	const query = `query GetPokemon { pokemon { id name } }
+query GetTrainer { trainer { id name } }`
+	aliases := WithOperationAliases(map[string]map[string]string{
+		"GetPokemon": {"id": "string", "name": "null:0.5"},
+	})
+	overrides := map[string]string{"data.*.id": "number"}
+	leaf := func(t *testing.T, operation, object, field string) map[string]any {
+		t.Helper()
+		schema, err := BuildSchemaForOperation(query, operation, overrides, aliases)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)[object].(map[string]any)["properties"].(map[string]any)[field].(map[string]any)
+	}
+
+	t.Run("applies to the named operation over the overrides", func(t *testing.T) {
+		if id := leaf(t, "GetPokemon", "pokemon", "id"); id["type"] != "string" {
+			t.Errorf("id: expected the aliased string type, got %v", id)
+		}
+		if name := leaf(t, "GetPokemon", "pokemon", "name"); name["type"] != "string" || name["x-stub-null-prob"] != 0.5 {
+			t.Errorf("name: expected a null probability, got %v", name)
+		}
+	})
+
+	t.Run("leaves other operations alone", func(t *testing.T) {
+		if id := leaf(t, "GetTrainer", "trainer", "id"); id["type"] != "number" {
+			t.Errorf("id: expected the overridden number type, got %v", id)
+		}
+		if name := leaf(t, "GetTrainer", "trainer", "name"); name["x-stub-null-prob"] != nil {
+			t.Errorf("name: expected no null probability, got %v", name)
+		}
+	})
+}
+
+func TestWithStrictOverrides(t *testing.T) {
+	const query = `query Q { pokemon { name stats { base_stat } } }`
+
+	t.Run("succeeds when every override is used", func(t *testing.T) {
+		overrides := map[string]string{
+			"data.pokemon.name":        "integer",
+			"data.*.stats.*.base_stat": "number",
+		}
+		if _, err := BuildSchema(query, overrides, WithStrictOverrides()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("fails listing the unused overrides", func(t *testing.T) {
+		overrides := map[string]string{
+			"data.pokemon.name":   "integer",
+			"data.pokemon.height": "integer",
+			"data.trainer.*":      "string",
+		}
+		_, err := BuildSchema(query, overrides, WithStrictOverrides())
+		if err == nil || !strings.Contains(err.Error(), "data.pokemon.height, data.trainer.*") {
+			t.Errorf("expected the unused paths in the error, got %v", err)
+		}
+		if _, err := BuildSchema(query, overrides); err != nil {
+			t.Errorf("expected unused overrides to be ignored without the option, got %v", err)
+		}
+	})
+
+	t.Run("counts keys shadowed by an alias or another key as used", func(t *testing.T) {
+		overrides := map[string]string{
+			"data.pokemon.name":        "integer",
+			"data.*.name":              "number",
+			"data.*.stats.*.base_stat": "number",
+		}
+		aliases := map[string]map[string]string{"Q": {"base_stat": "string"}}
+		if _, err := BuildSchema(query, overrides, WithStrictOverrides(), WithOperationAliases(aliases)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("leaves the check to the caller with WithOverrideUsage", func(t *testing.T) {
+		const split = `query A { pokemon { name } } query B { trainer { age } }`
+		overrides := map[string]string{"data.pokemon.name": "integer", "data.trainer.age": "string", "data.gym.city": "string"}
+		used := map[string]bool{}
+		for _, name := range []string{"A", "B"} {
+			if _, err := BuildSchema(split, overrides, WithStrictOverrides(), WithOperationName(name), WithOverrideUsage(used)); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		if unused := UnusedOverrides(overrides, used); !slices.Equal(unused, []string{"data.gym.city"}) {
+			t.Errorf("expected only data.gym.city unused, got %v", unused)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package graphqlschema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// stubDirectives declares the directives this tool understands so queries
+// using them still validate against a user's SDL.
+var stubDirectives = &ast.Source{
+	Name:    "stub-directives.graphql",
+	Input:   `directive @stubType(type: String!) on FIELD`,
+	BuiltIn: true,
+}
+
+// scalarTypes maps GraphQL's built-in scalars to JSON Schema types.
+var scalarTypes = map[string]string{
+	"Int":     "integer",
+	"Float":   "number",
+	"Boolean": "boolean",
+	"String":  "string",
+	"ID":      "string",
+}
+
+// BuildSchemaFromSDL is like BuildSchema but validates the query against the
+// GraphQL SDL and takes field types from it instead of inferring them from
+// field names. Custom scalars still fall back to name-based inference, and
+// overrides and @stubType directives still apply to leaf fields.
+func BuildSchemaFromSDL(querySource, sdl string, overrides map[string]string, opts ...SchemaOption) (map[string]any, error) {
+	return buildSchemaFromSources(querySource, []*ast.Source{{Name: "schema.graphql", Input: sdl}}, overrides, opts)
+}
+
+// BuildSchemaFromSDLFiles is like BuildSchemaFromSDL but merges several SDL
+// files, such as federated subgraph schemas, into one schema first. Types
+// defined in one file may be extended in another with "extend type".
+func BuildSchemaFromSDLFiles(querySource string, sdlFiles []string, overrides map[string]string, opts ...SchemaOption) (map[string]any, error) {
+	sources := make([]*ast.Source, len(sdlFiles))
+	for i, path := range sdlFiles {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, newBuilder(overrides, opts).formatError(fmt.Errorf("reading GraphQL schema: %w", err))
+		}
+		sources[i] = &ast.Source{Name: path, Input: string(data)}
+	}
+	return buildSchemaFromSources(querySource, sources, overrides, opts)
+}
+
+func buildSchemaFromSources(querySource string, sources []*ast.Source, overrides map[string]string, opts []SchemaOption) (map[string]any, error) {
+	b := newBuilder(overrides, opts)
+	schema, err := gqlparser.LoadSchema(append([]*ast.Source{stubDirectives}, sources...)...)
+	if err != nil {
+		return nil, b.formatError(err)
+	}
+	// Parse first so syntax errors are located like those of BuildSchema.
+	if _, err := parseQuery(querySource); err != nil {
+		return nil, b.formatError(err)
+	}
+	doc, errs := gqlparser.LoadQuery(schema, querySource)
+	if len(errs) > 0 {
+		return nil, b.formatError(errs)
+	}
+	b.schema = schema
+	result, err := b.build(doc)
+	return result, b.formatError(err)
+}
+
+// extractDescription returns the description documenting a field in the SDL,
+// or "" when it has none.
+func extractDescription(fieldDef *ast.FieldDefinition) string {
+	return strings.TrimSpace(fieldDef.Description)
+}
+
+// typeToSchema converts a field's SDL type into a JSON Schema node. Lists
+// become arrays whose items are addressed with an "items" path segment.
+func (b *builder) typeToSchema(field *ast.Field, t *ast.Type, fieldPath string) map[string]any {
+	if t.Elem != nil {
+		return b.annotate(map[string]any{"type": "array", "items": b.typeToSchema(field, t.Elem, fieldPath+".items")}, fieldPath)
+	}
+	if len(field.SelectionSet) > 0 {
+		return b.addTypename(b.selectionSetToSchema(field.SelectionSet, fieldPath), field, fieldPath)
+	}
+
+	def := b.schema.Types[t.NamedType]
+	isEnum := def != nil && def.Kind == ast.Enum
+	jsonType, ok := scalarTypes[t.NamedType]
+	reason := fmt.Sprintf("declared as %s in the GraphQL schema", t.NamedType)
+	switch {
+	case isEnum:
+		jsonType = "string"
+		reason = fmt.Sprintf("enum %s in the GraphQL schema", t.NamedType)
+	case !ok:
+		jsonType = inferType(field.Name)
+		if b.explain {
+			reason = fmt.Sprintf("custom scalar %s: %s", t.NamedType, explainInferred(field.Name))
+		}
+	}
+	node := b.leafSchema(field, jsonType, reason, fieldPath)
+	if b.nullableAnnotations {
+		node[nullableKeyword] = !t.NonNull
+	}
+	if isEnum && node["type"] == "string" {
+		values := make([]any, len(def.EnumValues))
+		for i, v := range def.EnumValues {
+			values[i] = v.Name
+		}
+		node["enum"] = values
+	}
+	return node
+}
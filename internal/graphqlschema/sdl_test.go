@@ -0,0 +1,154 @@
+package graphqlschema
+
+import (
+	"os"
+	"testing"
+)
+
+func loadSDL(t *testing.T) string {
+	t.Helper()
+	sdl, err := os.ReadFile("testdata/pokemon.graphqls")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	return string(sdl)
+}
+
+func TestBuildSchemaFromSDL(t *testing.T) {
+	t.Run("takes leaf types from the SDL instead of field names", func(t *testing.T) {
+		query := `query Q { pokemon(name: "pikachu") { id name height weight is_legendary rate } }`
+		schema, err := BuildSchemaFromSDL(query, loadSDL(t), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+		for field, want := range map[string]string{
+			"id":           "string",
+			"name":         "string",
+			"height":       "integer",
+			"weight":       "number",
+			"is_legendary": "boolean",
+			"rate":         "integer",
+		} {
+			if got := props[field].(map[string]any)["type"]; got != want {
+				t.Errorf("%s type: got %v, want %s", field, got, want)
+			}
+		}
+	})
+
+	t.Run("documents leaves with their SDL descriptions", func(t *testing.T) {
+		query := `query Q { pokemon(name: "pikachu") { name weight height } }`
+		schema, err := BuildSchemaFromSDL(query, loadSDL(t), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+		for field, want := range map[string]string{
+			"name":   "The Pokemon's name, in lowercase.",
+			"weight": "Weight in hectograms.",
+		} {
+			if got := props[field].(map[string]any)["description"]; got != want {
+				t.Errorf("%s description: got %q, want %q", field, got, want)
+			}
+		}
+		if _, ok := props["height"].(map[string]any)["description"]; ok {
+			t.Errorf("height: expected no description, got %v", props["height"])
+		}
+	})
+
+	t.Run("takes list-ness from the SDL instead of field names", func(t *testing.T) {
+		query := `query Q { pokemons { stats { base_stat } tags } }`
+		schema, err := BuildSchemaFromSDL(query, loadSDL(t), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pokemons := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemons"].(map[string]any)
+		if pokemons["type"] != "array" {
+			t.Fatalf("pokemons type: got %v, want array", pokemons["type"])
+		}
+		items := pokemons["items"].(map[string]any)["properties"].(map[string]any)
+		tags := items["tags"].(map[string]any)
+		if tags["type"] != "array" || tags["items"].(map[string]any)["type"] != "string" {
+			t.Errorf("tags: got %v, want array of string", tags)
+		}
+		stats := items["stats"].(map[string]any)
+		baseStat := stats["items"].(map[string]any)["properties"].(map[string]any)["base_stat"].(map[string]any)
+		if baseStat["type"] != "integer" {
+			t.Errorf("base_stat type: got %v, want integer", baseStat["type"])
+		}
+	})
+
+	t.Run("emits enum values for enum fields", func(t *testing.T) {
+		schema, err := BuildSchemaFromSDL(`query Q { pokemon(name: "x") { kind } }`, loadSDL(t), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kind := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)["kind"].(map[string]any)
+		if kind["type"] != "string" {
+			t.Errorf("kind type: got %v, want string", kind["type"])
+		}
+		if enum, _ := kind["enum"].([]any); len(enum) != 3 || enum[0] != "FIRE" {
+			t.Errorf("kind enum: got %v", kind["enum"])
+		}
+	})
+
+	t.Run("applies overrides and directives on top of SDL types", func(t *testing.T) {
+		query := `query Q { pokemon(name: "x") { height name @stubType(type: "integer") } }`
+		overrides := map[string]string{"data.pokemon.height": "string"}
+		schema, err := BuildSchemaFromSDL(query, loadSDL(t), overrides)
+		if err != nil {
+			t.Fatal(err)
+		}
+		props := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemon"].(map[string]any)["properties"].(map[string]any)
+		if got := props["height"].(map[string]any)["type"]; got != "string" {
+			t.Errorf("height type: got %v, want string", got)
+		}
+		if got := props["name"].(map[string]any)["type"]; got != "integer" {
+			t.Errorf("name type: got %v, want integer", got)
+		}
+	})
+
+	t.Run("returns validation errors for fields missing from the SDL", func(t *testing.T) {
+		if _, err := BuildSchemaFromSDL(`query Q { pokemon(name: "x") { nickname } }`, loadSDL(t), nil); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestBuildSchemaFromSDLFiles(t *testing.T) {
+	files := []string{"testdata/subgraphs/pokemon.graphqls", "testdata/subgraphs/moves.graphqls"}
+
+	t.Run("resolves types from every subgraph", func(t *testing.T) {
+		query := `query Q { pokemon(name: "pikachu") { id weight } moves { name accuracy } }`
+		schema, err := BuildSchemaFromSDLFiles(query, files, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)
+		pokemon := data["pokemon"].(map[string]any)["properties"].(map[string]any)
+		if pokemon["weight"].(map[string]any)["type"] != "number" {
+			t.Errorf("pokemon.weight: expected number from the pokemon subgraph, got %v", pokemon["weight"])
+		}
+		moves := data["moves"].(map[string]any)
+		if moves["type"] != "array" {
+			t.Fatalf("moves: expected array from the moves subgraph, got %v", moves["type"])
+		}
+		move := moves["items"].(map[string]any)["properties"].(map[string]any)
+		if move["accuracy"].(map[string]any)["type"] != "number" {
+			t.Errorf("moves.items.accuracy: expected number, got %v", move["accuracy"])
+		}
+	})
+
+	t.Run("fails when a subgraph is missing", func(t *testing.T) {
+		query := `query Q { moves { name } }`
+		if _, err := BuildSchemaFromSDLFiles(query, files[:1], nil); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("reports unreadable files", func(t *testing.T) {
+		if _, err := BuildSchemaFromSDLFiles(`query Q { pokemon { id } }`, []string{"testdata/missing.graphqls"}, nil); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package graphqlschema
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// StreamedLeaf is one line written by StreamSchema.
+type StreamedLeaf struct {
+	Path   string         `json:"path"`
+	Schema map[string]any `json:"schema"`
+}
+
+// StreamSchema writes each leaf of a schema built by BuildSchema as a line of
+// JSON, {"path": "data.pokemon.name", "schema": {"type": "string"}}, with
+// prefix before every path. Lines are written as the schema is walked, in
+// sorted path order, so large schemas need not be encoded whole, and the
+// output can be read a line at a time with tools such as jq. "$ref"s into
+// "$defs" are followed.
+func StreamSchema(schema map[string]any, prefix string, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	defs, _ := schema["$defs"].(map[string]any)
+	var walk func(node map[string]any, path string) error
+	walk = func(node map[string]any, path string) error {
+		if ref, ok := node["$ref"].(string); ok {
+			if def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any); ok {
+				return walk(def, path)
+			}
+			return nil
+		}
+		switch NodeType(node) {
+		case "object":
+			props, _ := node["properties"].(map[string]any)
+			for _, key := range slices.Sorted(maps.Keys(props)) {
+				if ps, ok := props[key].(map[string]any); ok {
+					if err := walk(ps, joinPath(path, key)); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		case "array":
+			if items, ok := node["items"].(map[string]any); ok {
+				return walk(items, joinPath(path, "items"))
+			}
+			return nil
+		case "":
+			return nil
+		}
+		return enc.Encode(StreamedLeaf{Path: path, Schema: node})
+	}
+	return walk(schema, prefix)
+}
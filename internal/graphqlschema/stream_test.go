@@ -0,0 +1,55 @@
+package graphqlschema
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"maps"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestStreamSchema(t *testing.T) {
+	query, err := os.ReadFile("testdata/pokemon_stats.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := BuildSchema(string(query), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := StreamSchema(schema, "", &buf); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var leaf StreamedLeaf
+		if err := json.Unmarshal(scanner.Bytes(), &leaf); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		if leaf.Schema["type"] != SchemaToTypeMap(schema)[leaf.Path] {
+			t.Errorf("%s: streamed type %v differs from the schema", leaf.Path, leaf.Schema["type"])
+		}
+		paths = append(paths, leaf.Path)
+	}
+
+	want := slices.Sorted(maps.Keys(SchemaToTypeMap(schema)))
+	if !slices.Equal(paths, want) {
+		t.Errorf("expected the schema's leaf paths in order\nwant %v\n got %v", want, paths)
+	}
+
+	t.Run("prefixes paths", func(t *testing.T) {
+		var buf bytes.Buffer
+		leaf := map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}}
+		if err := StreamSchema(leaf, "data.pokemon", &buf); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != `{"path":"data.pokemon.name","schema":{"type":"string"}}`+"\n" {
+			t.Errorf("got %q", got)
+		}
+	})
+}
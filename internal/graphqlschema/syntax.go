@@ -0,0 +1,43 @@
+package graphqlschema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// QuerySyntaxError is a syntax error in a GraphQL query, located by its
+// 1-based line and column.
+type QuerySyntaxError struct {
+	Message      string
+	Line, Column int
+}
+
+// Error implements error, as "line 5, col 12: Expected Name, found {".
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("line %d, col %d: %s", e.Line, e.Column, e.Message)
+}
+
+// parseQuery parses a GraphQL query document, reporting located syntax
+// errors as a *QuerySyntaxError.
+func parseQuery(querySource string) (*ast.QueryDocument, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: querySource})
+	if err != nil {
+		return nil, syntaxError(err)
+	}
+	return doc, nil
+}
+
+// syntaxError converts a gqlparser error carrying a location into a
+// *QuerySyntaxError, and returns other errors unchanged.
+func syntaxError(err error) error {
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) || len(gqlErr.Locations) == 0 {
+		return err
+	}
+	loc := gqlErr.Locations[0]
+	return &QuerySyntaxError{Message: gqlErr.Message, Line: loc.Line, Column: loc.Column}
+}
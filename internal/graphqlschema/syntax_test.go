@@ -0,0 +1,39 @@
+package graphqlschema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuerySyntaxError(t *testing.T) {
+	const query = "query Q {\n  pokemon {\n    name\n  }\n  {\n}\n"
+	want := QuerySyntaxError{Message: "Expected Name, found {", Line: 5, Column: 3}
+
+	for name, build := range map[string]func() error{
+		"BuildSchema": func() error {
+			_, err := BuildSchema(query, nil)
+			return err
+		},
+		"BuildSchemaFromSDL": func() error {
+			_, err := BuildSchemaFromSDL(query, "type Query { pokemon: Pokemon } type Pokemon { name: String }", nil)
+			return err
+		},
+		"LintQuery": func() error {
+			_, err := LintQuery(query, nil)
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var syntaxErr *QuerySyntaxError
+			if err := build(); !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a *QuerySyntaxError, got %T: %v", err, err)
+			}
+			if *syntaxErr != want {
+				t.Errorf("got %+v, want %+v", *syntaxErr, want)
+			}
+			if got := syntaxErr.Error(); got != "line 5, col 3: Expected Name, found {" {
+				t.Errorf("unexpected message %q", got)
+			}
+		})
+	}
+}
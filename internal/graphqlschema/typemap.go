@@ -0,0 +1,46 @@
+package graphqlschema
+
+import "strings"
+
+// SchemaToTypeMap flattens a schema built by BuildSchema into a map from each
+// leaf's dot-path to its type, such as "data.pokemon.name": "string". The map
+// is in the overrides format, so it can seed an overrides file. "$ref"s into
+// "$defs" are followed.
+func SchemaToTypeMap(schema map[string]any) map[string]string {
+	types := map[string]string{}
+	defs, _ := schema["$defs"].(map[string]any)
+	var walk func(node map[string]any, path string)
+	walk = func(node map[string]any, path string) {
+		if ref, ok := node["$ref"].(string); ok {
+			if def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any); ok {
+				walk(def, path)
+			}
+			return
+		}
+		switch t := NodeType(node); t {
+		case "object":
+			props, _ := node["properties"].(map[string]any)
+			for key, p := range props {
+				if ps, ok := p.(map[string]any); ok {
+					walk(ps, joinPath(path, key))
+				}
+			}
+		case "array":
+			if items, ok := node["items"].(map[string]any); ok {
+				walk(items, joinPath(path, "items"))
+			}
+		case "":
+		default:
+			types[path] = t
+		}
+	}
+	walk(schema, "")
+	return types
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,47 @@
+package graphqlschema
+
+import (
+	"maps"
+	"os"
+	"testing"
+)
+
+func TestSchemaToTypeMap(t *testing.T) {
+	schema, err := BuildSchema(`query Q { pokemon { name base_experience is_default stats { base_stat } } }`, map[string]string{
+		"data.pokemon.name": "integer",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("maps every leaf path to its type", func(t *testing.T) {
+		want := map[string]string{
+			"data.pokemon.name":                  "integer",
+			"data.pokemon.base_experience":       "integer",
+			"data.pokemon.is_default":            "boolean",
+			"data.pokemon.stats.items.base_stat": "integer",
+		}
+		if got := SchemaToTypeMap(schema); !maps.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("round-trips through the overrides", func(t *testing.T) {
+		query, err := os.ReadFile("testdata/pokemon_stats.graphql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		original, err := BuildSchema(string(query), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		types := SchemaToTypeMap(original)
+		rebuilt, err := BuildSchema(string(query), types)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := SchemaToTypeMap(rebuilt); !maps.Equal(got, types) {
+			t.Errorf("types changed:\ngot  %v\nwant %v", got, types)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package graphqlschema
+
+import (
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// WithInjectedTypename adds a "__typename" property to every object below
+// "data", holding a constant so clients that normalise their cache by
+// __typename, such as Apollo Client, can use the stubs. The constant is the
+// field's type from the SDL when there is one, and otherwise the field name
+// in PascalCase, so "pokemon_v2_pokemon" becomes "PokemonV2Pokemon".
+func WithInjectedTypename() SchemaOption {
+	return func(b *builder) {
+		b.injectTypename = true
+	}
+}
+
+// addTypename adds the WithInjectedTypename property to the object schema
+// built for field at path, replacing any selected __typename, and returns the
+// node. An object with inline fragment variants is left as is, since each
+// variant already holds its type condition as __typename.
+func (b *builder) addTypename(node map[string]any, field *ast.Field, path string) map[string]any {
+	if !b.injectTypename {
+		return node
+	}
+	if _, ok := node["anyOf"]; ok {
+		// Each fragment variant already has its own __typename.
+		return node
+	}
+	typename := pascalCase(field.Name)
+	if field.Definition != nil {
+		typename = field.Definition.Type.Name()
+	}
+	property := map[string]any{"type": "string", "const": typename}
+	node["properties"].(map[string]any)["__typename"] = b.annotate(property, path+".__typename")
+	return node
+}
+
+// pascalCase joins the words of a snake_case or camelCase name, capitalising
+// the first letter of each.
+func pascalCase(name string) string {
+	var sb strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word != "" {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package graphqlschema
+
+import "testing"
+
+func TestInjectedTypename(t *testing.T) {
+	typename := func(t *testing.T, node any) any {
+		t.Helper()
+		property, ok := node.(map[string]any)["properties"].(map[string]any)["__typename"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected a __typename property in %v", node)
+		}
+		if property["type"] != "string" {
+			t.Errorf("expected a string __typename, got %v", property)
+		}
+		return property["const"]
+	}
+
+	t.Run("uses the PascalCased field name", func(t *testing.T) {
+		schema, err := BuildSchema(`query Q { pokemon_v2_pokemon { __typename name abilities { name } } }`, nil, WithInjectedTypename())
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := schema["properties"].(map[string]any)["data"].(map[string]any)
+		if _, ok := data["properties"].(map[string]any)["__typename"]; ok {
+			t.Error("expected no __typename on data")
+		}
+		pokemon := data["properties"].(map[string]any)["pokemon_v2_pokemon"]
+		if got := typename(t, pokemon); got != "PokemonV2Pokemon" {
+			t.Errorf("expected PokemonV2Pokemon, got %v", got)
+		}
+		abilities := pokemon.(map[string]any)["properties"].(map[string]any)["abilities"].(map[string]any)["items"]
+		if got := typename(t, abilities); got != "Abilities" {
+			t.Errorf("expected Abilities, got %v", got)
+		}
+	})
+
+	t.Run("uses the SDL type", func(t *testing.T) {
+		sdl := `type Pokemon { name: String } type Query { pokemons: [Pokemon] }`
+		schema, err := BuildSchemaFromSDL(`query Q { pokemons { name } }`, sdl, nil, WithInjectedTypename())
+		if err != nil {
+			t.Fatal(err)
+		}
+		items := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["pokemons"].(map[string]any)["items"]
+		if got := typename(t, items); got != "Pokemon" {
+			t.Errorf("expected Pokemon, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+// Package harexport renders stubs as HAR (HTTP Archive) 1.2 entries, each
+// recording a GraphQL POST answered with a stub, for tools that replay
+// recorded traffic.
+package harexport
+
+import (
+	"encoding/json"
+	"runtime/debug"
+	"time"
+)
+
+// Endpoint is the URL the recorded GraphQL requests are sent to.
+const Endpoint = "http://localhost/graphql"
+
+// Version is the HAR version written.
+const Version = "1.2"
+
+// modulePath identifies this tool among the modules of a build.
+const modulePath = "github.com/ohdyno/generate-graphql-query-stubs"
+
+// creatorVersion returns the version of this tool the binary was built with,
+// or "dev" when it was built from a checkout.
+func creatorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	for _, m := range append([]*debug.Module{&info.Main}, info.Deps...) {
+		if m.Path == modulePath && m.Version != "" && m.Version != "(devel)" {
+			return m.Version
+		}
+	}
+	return "dev"
+}
+
+// HAR is a HAR document.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the recorded entries.
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator names the application that wrote the HAR.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is one request and its response.
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            int      `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    PostData    `json:"postData"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// PostData is the body of a request.
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Content is the body of a response.
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// NameValue is a header, cookie or query parameter.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Timings breaks down the time of an entry. The recorded requests are not
+// real, so every phase is zero.
+type Timings struct {
+	Send    int `json:"send"`
+	Wait    int `json:"wait"`
+	Receive int `json:"receive"`
+}
+
+// New returns a HAR document with an entry per stub, each a GraphQL POST to
+// Endpoint answered with the stub as JSON. When operationName is not empty,
+// the requests name it as their "operationName". Entries are stamped with
+// started.
+func New(stubs []any, operationName string, started time.Time) (HAR, error) {
+	body := map[string]any{}
+	if operationName != "" {
+		body["operationName"] = operationName
+	}
+	request, err := json.Marshal(body)
+	if err != nil {
+		return HAR{}, err
+	}
+
+	entries := make([]Entry, len(stubs))
+	for i, stub := range stubs {
+		text, err := json.Marshal(stub)
+		if err != nil {
+			return HAR{}, err
+		}
+		entries[i] = Entry{
+			StartedDateTime: started.UTC().Format(time.RFC3339),
+			Request: Request{
+				Method:      "POST",
+				URL:         Endpoint,
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []NameValue{},
+				Headers:     []NameValue{{Name: "Content-Type", Value: "application/json"}},
+				QueryString: []NameValue{},
+				PostData:    PostData{MimeType: "application/json", Text: string(request)},
+				HeadersSize: -1,
+				BodySize:    len(request),
+			},
+			Response: Response{
+				Status:      200,
+				StatusText:  "OK",
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []NameValue{},
+				Headers:     []NameValue{{Name: "Content-Type", Value: "application/json"}},
+				Content:     Content{Size: len(text), MimeType: "application/json", Text: string(text)},
+				HeadersSize: -1,
+				BodySize:    len(text),
+			},
+		}
+	}
+	return HAR{Log: Log{
+		Version: Version,
+		Creator: Creator{Name: "generate-graphql-query-stubs", Version: creatorVersion()},
+		Entries: entries,
+	}}, nil
+}
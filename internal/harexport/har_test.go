@@ -0,0 +1,68 @@
+package harexport
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	stubs := []any{
+		map[string]any{"data": map[string]any{"pokemon": map[string]any{"name": "pikachu"}}},
+		map[string]any{"data": map[string]any{"pokemon": map[string]any{"name": "eevee"}}},
+	}
+	har, err := New(stubs, "GetPokemon", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(har)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	log := doc["log"].(map[string]any)
+	if log["version"] != "1.2" {
+		t.Errorf("version: got %v", log["version"])
+	}
+	// A test binary is built from the checkout, which has no version.
+	if creator := log["creator"].(map[string]any); creator["version"] != "dev" {
+		t.Errorf("creator.version: got %v, want the tool's version", creator["version"])
+	}
+	entries := log["entries"].([]any)
+	if len(entries) != 2 {
+		t.Fatalf("expected an entry per stub, got %d", len(entries))
+	}
+	entry := entries[0].(map[string]any)
+	if entry["startedDateTime"] != "2024-01-02T03:04:05Z" {
+		t.Errorf("startedDateTime: got %v", entry["startedDateTime"])
+	}
+
+	request := entry["request"].(map[string]any)
+	if request["method"] != "POST" || request["url"] != Endpoint {
+		t.Errorf("unexpected request %v", request)
+	}
+	if text := request["postData"].(map[string]any)["text"]; text != `{"operationName":"GetPokemon"}` {
+		t.Errorf("postData.text: got %v", text)
+	}
+
+	response := entry["response"].(map[string]any)
+	if response["status"] != float64(200) {
+		t.Errorf("status: got %v", response["status"])
+	}
+	content := response["content"].(map[string]any)
+	if content["mimeType"] != "application/json" {
+		t.Errorf("mimeType: got %v", content["mimeType"])
+	}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(content["text"].(string)), &body); err != nil {
+		t.Fatalf("content.text is not JSON-encoded: %v", err)
+	}
+	name := body["data"].(map[string]any)["pokemon"].(map[string]any)["name"]
+	if name != "pikachu" {
+		t.Errorf("expected the first stub, got %v", body)
+	}
+}
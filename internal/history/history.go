@@ -0,0 +1,84 @@
+// Package history keeps a versioned log of the schemas generated for a query.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is one version of a query's schema.
+type Entry struct {
+	Timestamp        time.Time      `json:"timestamp"`
+	QueryFingerprint string         `json:"query_fingerprint"`
+	Schema           map[string]any `json:"schema"`
+}
+
+// Load reads a history file. A missing file is an empty history.
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Save writes entries to a history file. It writes through a temporary file
+// and a rename, so an interrupted run leaves the previous history intact. The
+// file keeps its mode, or is 0644 when new.
+func Save(path string, entries []Entry) error {
+	out, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(out, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Append adds e to the end of entries and reports whether it did. Appending
+// is idempotent: nothing is added when the latest entry has the same query
+// fingerprint.
+func Append(entries []Entry, e Entry) ([]Entry, bool) {
+	if n := len(entries); n > 0 && entries[n-1].QueryFingerprint == e.QueryFingerprint {
+		return entries, false
+	}
+	return append(entries, e), true
+}
+
+// Prune drops the oldest entries so at most max remain.
+func Prune(entries []Entry, max int) []Entry {
+	if max < 0 || len(entries) <= max {
+		return entries
+	}
+	return entries[len(entries)-max:]
+}
@@ -0,0 +1,85 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func entry(fingerprint string) Entry {
+	return Entry{
+		Timestamp:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		QueryFingerprint: fingerprint,
+		Schema:           map[string]any{"type": "object"},
+	}
+}
+
+func TestAppend(t *testing.T) {
+	entries, added := Append(nil, entry("a"))
+	if !added || len(entries) != 1 {
+		t.Fatalf("expected the first entry to be added, got %v", entries)
+	}
+
+	t.Run("skips an entry matching the latest fingerprint", func(t *testing.T) {
+		if got, added := Append(entries, entry("a")); added || len(got) != 1 {
+			t.Errorf("expected no new entry, got %v", got)
+		}
+	})
+
+	t.Run("adds an entry for a changed query", func(t *testing.T) {
+		if got, added := Append(entries, entry("b")); !added || len(got) != 2 {
+			t.Errorf("expected a second entry, got %v", got)
+		}
+	})
+}
+
+func TestPrune(t *testing.T) {
+	entries := []Entry{entry("a"), entry("b"), entry("c")}
+	got := Prune(entries, 2)
+	if len(got) != 2 || got[0].QueryFingerprint != "b" || got[1].QueryFingerprint != "c" {
+		t.Errorf("expected the two newest entries, got %v", got)
+	}
+	if got := Prune(entries, 10); len(got) != 3 {
+		t.Errorf("expected nothing pruned, got %v", got)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema-history.json")
+
+	entries, err := Load(path)
+	if err != nil || entries != nil {
+		t.Fatalf("expected an empty history for a missing file, got %v, %v", entries, err)
+	}
+
+	if err := Save(path, []Entry{entry("a"), entry("b")}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].QueryFingerprint != "b" || !entries[0].Timestamp.Equal(entry("a").Timestamp) {
+		t.Errorf("expected saved entries back, got %v", entries)
+	}
+	t.Run("replaces the file atomically, keeping its mode", func(t *testing.T) {
+		if err := os.Chmod(path, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := Save(path, []Entry{entry("c")}); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("expected mode 0600 to be kept, got %v", info.Mode().Perm())
+		}
+		files, _ := os.ReadDir(filepath.Dir(path))
+		if len(files) != 1 {
+			t.Errorf("expected no temporary files left behind, got %v", files)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package jsonschemastub
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+)
+
+// arraySizeRule is a WithSemanticArraySizes rule: arrays whose field name
+// matches pattern have between min and max items.
+type arraySizeRule struct {
+	pattern  string
+	min, max int
+}
+
+// WithSemanticArraySizes sets the length of arrays by their field name, as
+// real data often fixes it: a Pokémon has 6 stats and up to 4 moves. rules
+// maps a field name, or a glob pattern in the syntax of path.Match such as
+// "*_stats", to an inclusive [min, max] length, like
+// {"stats": {6, 6}, "abilities": {1, 3}}. A matching rule replaces the
+// default length; an array's own "minItems" and "maxItems" still win. An
+// exact name wins over patterns, which are tried in sorted order.
+func WithSemanticArraySizes(rules map[string][2]int) GenOption {
+	return func(g *Generator) {
+		g.arraySizes = nil
+		for _, pattern := range slices.Sorted(maps.Keys(rules)) {
+			size := rules[pattern]
+			if _, err := path.Match(pattern, ""); err != nil {
+				g.err = fmt.Errorf("array size for %q: %w", pattern, err)
+				return
+			}
+			if size[0] < 0 || size[0] > size[1] {
+				g.err = fmt.Errorf("array size for %q: minimum %d must be between 0 and the maximum %d", pattern, size[0], size[1])
+				return
+			}
+			g.arraySizes = append(g.arraySizes, arraySizeRule{pattern: pattern, min: size[0], max: size[1]})
+		}
+	}
+}
+
+// semanticArraySize returns the WithSemanticArraySizes range for the array
+// at the current path, named by its last segment other than "items".
+func (g *Generator) semanticArraySize() (min, max int, ok bool) {
+	if len(g.arraySizes) == 0 {
+		return 0, 0, false
+	}
+	name := ""
+	for i := len(g.path) - 1; i >= 0 && name == ""; i-- {
+		if g.path[i] != "items" {
+			name = g.path[i]
+		}
+	}
+	if name == "" {
+		return 0, 0, false
+	}
+	for _, rule := range g.arraySizes {
+		if rule.pattern == name {
+			return rule.min, rule.max, true
+		}
+	}
+	for _, rule := range g.arraySizes {
+		if matched, _ := path.Match(rule.pattern, name); matched {
+			return rule.min, rule.max, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,66 @@
+package jsonschemastub
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSemanticArraySizes(t *testing.T) {
+	list := func() map[string]any {
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	}
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pokemons": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"stats":     list(),
+						"abilities": list(),
+						"moves":     map[string]any{"type": "array", "maxItems": 2.0, "items": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+	}
+	g := NewGenerator(WithSemanticArraySizes(map[string][2]int{
+		"stats":     {6, 6},
+		"abilities": {1, 3},
+		"*s":        {4, 4},
+	}))
+	for range 20 {
+		stub, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pokemons := stub.(map[string]any)["pokemons"].([]any)
+		if len(pokemons) != 4 {
+			t.Errorf("expected the *s pattern to give 4 pokemons, got %d", len(pokemons))
+		}
+		for _, p := range pokemons {
+			pokemon := p.(map[string]any)
+			if n := len(pokemon["stats"].([]any)); n != 6 {
+				t.Errorf("expected 6 stats, got %d", n)
+			}
+			if n := len(pokemon["abilities"].([]any)); n < 1 || n > 3 {
+				t.Errorf("expected 1 to 3 abilities, got %d", n)
+			}
+			if n := len(pokemon["moves"].([]any)); n != 2 {
+				t.Errorf("expected maxItems to cap the 4 moves at 2, got %d", n)
+			}
+		}
+	}
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		for want, rules := range map[string]map[string][2]int{
+			"syntax error":              {"[": {1, 2}},
+			"minimum 3 must be between": {"stats": {3, 2}},
+		} {
+			if _, err := NewGenerator(WithSemanticArraySizes(rules)).Generate(schema); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("expected %q error, got %v", want, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package jsonschemastub
+
+import (
+	"fmt"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/dictionary"
+)
+
+// WithDictionary makes every property whose name matches a key of the
+// dictionary JSON file take a random value from that key's list. Keys are
+// field names or glob patterns such as "*_name"; see dictionary.Load.
+func WithDictionary(file string) GenOption {
+	return func(g *Generator) {
+		m, err := dictionary.Load(file)
+		if err != nil {
+			g.err = fmt.Errorf("dictionary: %w", err)
+			return
+		}
+		g.dictionary = m
+	}
+}
+
+// dictionaryValues returns the dictionary values for the property named
+// segment. Array items are not properties, so they are never matched.
+func (g *Generator) dictionaryValues(segment string) ([]any, bool) {
+	if g.dictionary == nil || segment == "items" {
+		return nil, false
+	}
+	return g.dictionary.Match(segment)
+}
@@ -0,0 +1,42 @@
+package jsonschemastub
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDictionary(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":         map[string]any{"type": "string"},
+			"primary_type": map[string]any{"type": "string"},
+			"count":        map[string]any{"type": "integer"},
+		},
+	}
+	g := NewGenerator(WithDictionary("testdata/dictionary.json"))
+	for range 20 {
+		stub, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := stub.(map[string]any)
+		if !slices.Contains([]any{"Pikachu", "Charizard"}, obj["name"]) {
+			t.Errorf("expected a dictionary name, got %v", obj["name"])
+		}
+		if !slices.Contains([]any{"fire", "water"}, obj["primary_type"]) {
+			t.Errorf("expected a dictionary type, got %v", obj["primary_type"])
+		}
+		if _, ok := obj["count"].(int); !ok {
+			t.Errorf("expected a generated integer count, got %#v", obj["count"])
+		}
+	}
+
+	t.Run("reports an unreadable dictionary", func(t *testing.T) {
+		_, err := NewGenerator(WithDictionary("testdata/missing.json")).Generate(schema)
+		if err == nil || !strings.Contains(err.Error(), "dictionary") {
+			t.Errorf("expected a dictionary error, got %v", err)
+		}
+	})
+}
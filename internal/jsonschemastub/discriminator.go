@@ -0,0 +1,36 @@
+package jsonschemastub
+
+import "maps"
+
+// graphqlTypeKeyword names the GraphQL type of an object variant, as
+// graphqlschema records an inline fragment's type condition.
+const graphqlTypeKeyword = "x-graphql-type"
+
+// WithTypenameDiscriminator generates objects whose schema lists variants
+// under "anyOf", such as those graphqlschema builds from inline fragments on
+// an interface or union, as one variant picked at random. The variant's
+// fields are added to the object along with a "__typename" holding the
+// variant's "x-graphql-type", so clients can tell the variants apart.
+// Without it, "anyOf" is ignored.
+func WithTypenameDiscriminator() GenOption {
+	return func(g *Generator) {
+		g.typenameDiscriminator = true
+	}
+}
+
+// addVariant adds the fields of a random "anyOf" variant of schema, and its
+// __typename, to the object generated for schema.
+func (g *Generator) addVariant(schema, object map[string]any) {
+	variants, _ := schema["anyOf"].([]any)
+	if !g.typenameDiscriminator || len(variants) == 0 {
+		return
+	}
+	variant, ok := variants[g.rand.Intn(len(variants))].(map[string]any)
+	if !ok {
+		return
+	}
+	maps.Copy(object, g.generateObject(variant))
+	if typename, ok := variant[graphqlTypeKeyword].(string); ok {
+		object["__typename"] = typename
+	}
+}
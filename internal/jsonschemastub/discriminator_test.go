@@ -0,0 +1,77 @@
+package jsonschemastub
+
+import (
+	"testing"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+)
+
+func TestTypenameDiscriminator(t *testing.T) {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"name": map[string]any{"type": "string"}},
+		"anyOf": []any{
+			map[string]any{
+				"type":           "object",
+				"x-graphql-type": "Fire",
+				"properties":     map[string]any{"damage": map[string]any{"type": "integer"}},
+			},
+			map[string]any{
+				"type":           "object",
+				"x-graphql-type": "Water",
+				"properties":     map[string]any{"depth": map[string]any{"type": "integer"}},
+			},
+		},
+	}
+
+	g := NewGenerator(WithTypenameDiscriminator())
+	seen := map[any]bool{}
+	for range 50 {
+		stub, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := stub.(map[string]any)
+		seen[obj["__typename"]] = true
+		field := map[any]string{"Fire": "damage", "Water": "depth"}[obj["__typename"]]
+		if _, ok := obj[field]; field == "" || !ok || len(obj) != 3 {
+			t.Errorf("expected name, __typename and one variant's field, got %v", obj)
+		}
+	}
+	if !seen["Fire"] || !seen["Water"] {
+		t.Errorf("expected both variants to be picked, got %v", seen)
+	}
+
+	t.Run("ignores variants by default", func(t *testing.T) {
+		stub, err := NewGenerator().Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj := stub.(map[string]any); len(obj) != 1 {
+			t.Errorf("expected only the name, got %v", obj)
+		}
+	})
+}
+
+func TestTypenameDiscriminatorValidatesAgainstBuiltSchema(t *testing.T) {
+	schema, err := graphqlschema.BuildSchema(`query Q { attack { name ... on Fire { damage } ... on Water { depth } } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, g := range map[string]*Generator{
+		"with variants":    NewGenerator(WithTypenameDiscriminator()),
+		"without variants": NewGenerator(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			for range 20 {
+				stub, err := g.Generate(schema)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := ValidateStub(schema, stub); err != nil {
+					t.Fatalf("stub %v fails its schema: %v", stub, err)
+				}
+			}
+		})
+	}
+}
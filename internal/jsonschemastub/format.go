@@ -0,0 +1,69 @@
+package jsonschemastub
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// FormatValidator checks that a string is valid for a custom format that
+// JSON Schema validators do not know about.
+type FormatValidator interface {
+	Validate(format, value string) error
+}
+
+// FormatValidatorFunc adapts a function to a FormatValidator.
+type FormatValidatorFunc func(format, value string) error
+
+// Validate implements FormatValidator.
+func (f FormatValidatorFunc) Validate(format, value string) error {
+	return f(format, value)
+}
+
+var (
+	formatValidatorsMu sync.RWMutex
+	formatValidators   = map[string]FormatValidator{
+		"pokemon-name": FormatValidatorFunc(validatePokemonName),
+		"pokemon-type": FormatValidatorFunc(validatePokemonType),
+	}
+)
+
+// RegisterFormatValidator makes ValidateStub check strings whose "format" or
+// "x-stub-format" is format with v, replacing any validator already
+// registered for it.
+func RegisterFormatValidator(format string, v FormatValidator) {
+	formatValidatorsMu.Lock()
+	defer formatValidatorsMu.Unlock()
+	formatValidators[format] = v
+}
+
+func lookupFormatValidator(format string) (FormatValidator, bool) {
+	formatValidatorsMu.RLock()
+	defer formatValidatorsMu.RUnlock()
+	v, ok := formatValidators[format]
+	return v, ok
+}
+
+var pokemonNameRE = regexp.MustCompile(`^[a-z]+(-[a-z]+)*$`)
+
+// validatePokemonName accepts lowercase names with hyphens, as in "mr-mime".
+func validatePokemonName(format, value string) error {
+	if !pokemonNameRE.MatchString(value) {
+		return fmt.Errorf("%q is not a valid %s: want lowercase letters and hyphens", value, format)
+	}
+	return nil
+}
+
+var pokemonTypes = []string{
+	"bug", "dark", "dragon", "electric", "fairy", "fighting", "fire", "flying", "ghost",
+	"grass", "ground", "ice", "normal", "poison", "psychic", "rock", "steel", "water",
+}
+
+// validatePokemonType accepts the eighteen Pokemon type names, in lowercase.
+func validatePokemonType(format, value string) error {
+	if !slices.Contains(pokemonTypes, value) {
+		return fmt.Errorf("%q is not a valid %s", value, format)
+	}
+	return nil
+}
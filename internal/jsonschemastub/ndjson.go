@@ -0,0 +1,39 @@
+package jsonschemastub
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxNDJSONLine is the longest schema line ProcessNDJSON accepts.
+const maxNDJSONLine = 16 << 20
+
+// ProcessNDJSON reads one JSON Schema per line from r and writes one stub per
+// line to w, in the same order. Blank lines are skipped. It stops at the first
+// schema that cannot be parsed or generated, reporting its line number.
+func ProcessNDJSON(r io.Reader, w io.Writer, g *Generator) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxNDJSONLine)
+	enc := json.NewEncoder(w)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var schema map[string]any
+		if err := json.Unmarshal(text, &schema); err != nil {
+			return fmt.Errorf("line %d: parsing JSON schema: %w", line, err)
+		}
+		stub, err := g.Generate(schema)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := enc.Encode(stub); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
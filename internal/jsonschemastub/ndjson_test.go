@@ -0,0 +1,50 @@
+package jsonschemastub
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProcessNDJSON(t *testing.T) {
+	t.Run("writes one stub per schema line", func(t *testing.T) {
+		in := strings.Join([]string{
+			`{"type": "string"}`,
+			`{"type": "integer"}`,
+			``,
+			`{"type": "object", "properties": {"name": {"type": "string"}}}`,
+		}, "\n")
+		var out bytes.Buffer
+		if err := ProcessNDJSON(strings.NewReader(in), &out, NewGenerator(WithSeed(1))); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("expected 3 lines, got %d:\n%s", len(lines), out.String())
+		}
+		var stubs [3]any
+		for i, line := range lines {
+			if err := json.Unmarshal([]byte(line), &stubs[i]); err != nil {
+				t.Fatalf("line %d is not JSON: %v", i+1, err)
+			}
+		}
+		if _, ok := stubs[0].(string); !ok {
+			t.Errorf("line 1: expected a string, got %T", stubs[0])
+		}
+		if _, ok := stubs[1].(float64); !ok {
+			t.Errorf("line 2: expected a number, got %T", stubs[1])
+		}
+		if _, ok := stubs[2].(map[string]any)["name"]; !ok {
+			t.Errorf("line 3: expected an object with name, got %v", stubs[2])
+		}
+	})
+
+	t.Run("reports the line of an invalid schema", func(t *testing.T) {
+		in := "{\"type\": \"string\"}\nnot json\n"
+		err := ProcessNDJSON(strings.NewReader(in), &bytes.Buffer{}, NewGenerator())
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("expected an error for line 2, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package jsonschemastub
+
+import (
+	"fmt"
+	"strings"
+)
+
+// nullableKeyword marks whether a field may be null, as written by
+// graphqlschema.WithNullableAnnotations.
+const nullableKeyword = "x-nullable"
+
+// WithRequiredValidation makes Generate fail when a field marked
+// "x-nullable": false could be generated as null, through its
+// "x-stub-null-prob" or WithNullableTypes. Without it such fields are quietly
+// never null.
+func WithRequiredValidation() GenOption {
+	return func(g *Generator) {
+		g.requiredValidation = true
+	}
+}
+
+// nonNullChance returns p, the chance of generating nil for schema, unless
+// schema is marked non-null; then it is 0, or an error under
+// WithRequiredValidation when p is positive.
+func (g *Generator) nonNullChance(schema map[string]any, p float64) float64 {
+	if p == 0 || schema[nullableKeyword] != false {
+		return p
+	}
+	if g.requiredValidation {
+		g.failRef(fmt.Errorf("%q: is not nullable (x-nullable is false) but would be null with probability %g", strings.Join(g.path, "."), p))
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package jsonschemastub
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateNonNullable(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":   map[string]any{"type": "string", "x-nullable": false, "x-stub-null-prob": 1.0},
+			"height": map[string]any{"type": "integer", "x-nullable": true, "x-stub-null-prob": 1.0},
+		},
+	}
+
+	t.Run("never generates null for non-nullable fields", func(t *testing.T) {
+		stub, err := NewGenerator().Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := stub.(map[string]any)
+		if obj["name"] == nil {
+			t.Error("expected a name, got null")
+		}
+		if obj["height"] != nil {
+			t.Errorf("expected a null height, got %v", obj["height"])
+		}
+	})
+
+	t.Run("fails under WithRequiredValidation", func(t *testing.T) {
+		_, err := NewGenerator(WithRequiredValidation()).Generate(schema)
+		if err == nil || !strings.Contains(err.Error(), `"name": is not nullable`) {
+			t.Errorf("expected a non-nullable error, got %v", err)
+		}
+	})
+
+	t.Run("allows non-nullable fields without a null chance", func(t *testing.T) {
+		schema := map[string]any{"type": []any{"string", "null"}, "x-nullable": false}
+		if _, err := NewGenerator(WithRequiredValidation()).Generate(schema); err != nil {
+			t.Errorf("expected no error without WithNullableTypes, got %v", err)
+		}
+		if _, err := NewGenerator(WithRequiredValidation(), WithNullableTypes()).Generate(schema); err == nil {
+			t.Error("expected an error with WithNullableTypes")
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package jsonschemastub
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// GeneratorPool generates stubs safely from many goroutines. Each goroutine
+// borrows its own Generator; new generators are seeded from a shared counter
+// so their random sequences are independent. Warnings are discarded.
+type GeneratorPool struct {
+	pool     sync.Pool
+	nextSeed atomic.Int64
+}
+
+// NewGeneratorPool returns a pool whose generators are configured by opts and
+// seeded with seed+1, seed+2, and so on.
+func NewGeneratorPool(seed int64, opts ...GenOption) *GeneratorPool {
+	p := &GeneratorPool{}
+	p.nextSeed.Store(seed)
+	p.pool.New = func() any {
+		return NewGenerator(append(slices.Clone(opts), WithSeed(p.nextSeed.Add(1)))...)
+	}
+	return p
+}
+
+// Generate produces a stub value matching the given JSON Schema. It is safe
+// for concurrent use.
+func (p *GeneratorPool) Generate(schema map[string]any) (any, error) {
+	g := p.pool.Get().(*Generator)
+	defer func() {
+		g.warnings = nil
+		p.pool.Put(g)
+	}()
+	return g.Generate(schema)
+}
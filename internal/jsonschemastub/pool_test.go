@@ -0,0 +1,42 @@
+package jsonschemastub
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGeneratorPool(t *testing.T) {
+	t.Run("generates from 100 goroutines concurrently", func(t *testing.T) {
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":  map[string]any{"type": "string"},
+				"stats": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+			},
+		}
+		pool := NewGeneratorPool(1)
+
+		var wg sync.WaitGroup
+		results := make([]any, 100)
+		errs := make([]error, 100)
+		for i := range results {
+			wg.Go(func() {
+				results[i], errs[i] = pool.Generate(schema)
+			})
+		}
+		wg.Wait()
+
+		for i, result := range results {
+			if errs[i] != nil {
+				t.Fatalf("goroutine %d: %v", i, errs[i])
+			}
+			stub, ok := result.(map[string]any)
+			if !ok {
+				t.Fatalf("goroutine %d: expected object, got %T", i, result)
+			}
+			if _, ok := stub["name"].(string); !ok {
+				t.Errorf("goroutine %d: name: expected string, got %T", i, stub["name"])
+			}
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package jsonschemastub
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// recordedValue is a line of a recording: a generated value, or the length
+// of a generated array, at a field path.
+type recordedValue struct {
+	Path   string `json:"path"`
+	Value  any    `json:"value,omitempty"`
+	Length *int   `json:"length,omitempty"`
+}
+
+// WithRecording writes every leaf value the generator produces, and the
+// length of every array, to w as JSON Lines records such as
+// {"path":"pokemons.items.name","value":"pikachu"}. Replaying the records with
+// WithReplay reproduces the stubs, even after the generator itself changes.
+func WithRecording(w io.Writer) GenOption {
+	return func(g *Generator) {
+		g.recorder = json.NewEncoder(w)
+	}
+}
+
+// WithReplay returns the values of a WithRecording recording read from r
+// instead of generating them. Each path's values are used in the order they
+// were recorded; once a path's are used up, or for paths the recording does
+// not have, values are generated as usual.
+func WithReplay(r io.Reader) GenOption {
+	return func(g *Generator) {
+		g.replayValues = map[string][]any{}
+		g.replayLengths = map[string][]int{}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1<<20)
+		for line := 1; scanner.Scan(); line++ {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			dec := json.NewDecoder(strings.NewReader(scanner.Text()))
+			// Numbers stay as written, so replayed output is byte-identical.
+			dec.UseNumber()
+			var rec recordedValue
+			if err := dec.Decode(&rec); err != nil {
+				g.err = fmt.Errorf("replay: line %d: %w", line, err)
+				return
+			}
+			if rec.Length != nil {
+				g.replayLengths[rec.Path] = append(g.replayLengths[rec.Path], *rec.Length)
+			} else {
+				g.replayValues[rec.Path] = append(g.replayValues[rec.Path], rec.Value)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			g.err = fmt.Errorf("replay: %w", err)
+		}
+	}
+}
+
+// replayed returns the next recorded value at path.
+func (g *Generator) replayed(path string) (any, bool) {
+	return nextReplayed(g.replayValues, path)
+}
+
+// replayedLength returns the next recorded length of the array at path.
+func (g *Generator) replayedLength(path string) (int, bool) {
+	return nextReplayed(g.replayLengths, path)
+}
+
+func nextReplayed[T any](recorded map[string][]T, path string) (T, bool) {
+	queue := recorded[path]
+	if len(queue) == 0 {
+		var zero T
+		return zero, false
+	}
+	recorded[path] = queue[1:]
+	return queue[0], true
+}
+
+// record writes v, the value at path, to the WithRecording writer when it is
+// a leaf. Objects and arrays are rebuilt from their leaves and lengths.
+func (g *Generator) record(path string, v any) {
+	if g.recorder == nil {
+		return
+	}
+	switch v.(type) {
+	case map[string]any, []any:
+		return
+	}
+	g.writeRecord(recordedValue{Path: path, Value: v})
+}
+
+// recordLength writes the length of the array at path to the WithRecording
+// writer.
+func (g *Generator) recordLength(path string, length int) {
+	if g.recorder != nil {
+		g.writeRecord(recordedValue{Path: path, Length: &length})
+	}
+}
+
+func (g *Generator) writeRecord(rec recordedValue) {
+	if err := g.recorder.Encode(rec); err != nil {
+		g.failRef(fmt.Errorf("recording: %w", err))
+	}
+}
@@ -0,0 +1,85 @@
+package jsonschemastub
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":   map[string]any{"type": "string"},
+			"weight": map[string]any{"type": "number"},
+			"legend": map[string]any{"type": "boolean"},
+			"pokemons": map[string]any{
+				"type":     "array",
+				"maxItems": 5.0,
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"id":    map[string]any{"type": "integer"},
+						"moves": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+	}
+	generate := func(t *testing.T, g *Generator) []byte {
+		t.Helper()
+		var out bytes.Buffer
+		for range 3 {
+			stub, err := g.Generate(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			line, _ := json.Marshal(stub)
+			out.Write(append(line, '\n'))
+		}
+		return out.Bytes()
+	}
+
+	var recording bytes.Buffer
+	recorded := generate(t, NewGenerator(WithSeed(1), WithRecording(&recording)))
+	replayed := generate(t, NewGenerator(WithSeed(2), WithReplay(bytes.NewReader(recording.Bytes()))))
+	if !bytes.Equal(recorded, replayed) {
+		t.Errorf("replayed stubs differ from the recorded ones:\nrecorded: %s\nreplayed: %s", recorded, replayed)
+	}
+
+	t.Run("records a line per leaf and array", func(t *testing.T) {
+		first := strings.SplitN(recording.String(), "\n", 2)[0]
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(first), &rec); err != nil {
+			t.Fatalf("recording is not JSON Lines: %v", err)
+		}
+		if _, ok := rec["path"]; !ok {
+			t.Errorf("expected a path in %s", first)
+		}
+	})
+
+	t.Run("generates what the recording lacks", func(t *testing.T) {
+		g := NewGenerator(WithReplay(strings.NewReader(`{"path":"name","value":"pikachu"}` + "\n")))
+		for i, want := range []bool{true, false} {
+			stub, err := g.Generate(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj := stub.(map[string]any)
+			if (obj["name"] == "pikachu") != want {
+				t.Errorf("stub %d: unexpected name %v", i, obj["name"])
+			}
+			if _, ok := obj["legend"].(bool); !ok {
+				t.Errorf("stub %d: expected a generated legend, got %v", i, obj["legend"])
+			}
+		}
+	})
+
+	t.Run("reports a malformed recording", func(t *testing.T) {
+		_, err := NewGenerator(WithReplay(strings.NewReader("{\"path\":\"name\"}\nnot json\n"))).Generate(schema)
+		if err == nil || !strings.Contains(err.Error(), "replay: line 2") {
+			t.Errorf("expected a line 2 error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package jsonschemastub
+
+import (
+	"fmt"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/refdata"
+)
+
+// referenceCSVs caches the CSV files read by WithReferenceCSV across
+// generators.
+var referenceCSVs = refdata.NewCSVValuePool()
+
+// WithReferenceCSV makes the field at fieldPath, a dot-path from the root
+// using "items" for array items, take a random value from the named column
+// of a CSV file whose first row is a header. Call it once per field.
+func WithReferenceCSV(fieldPath, csvFile, columnName string) GenOption {
+	return func(g *Generator) {
+		values, err := referenceCSVs.Column(csvFile, columnName)
+		if err != nil {
+			g.err = fmt.Errorf("reference CSV for %s: %w", fieldPath, err)
+			return
+		}
+		if g.references == nil {
+			g.references = map[string][]string{}
+		}
+		g.references[fieldPath] = values
+	}
+}
@@ -0,0 +1,54 @@
+package jsonschemastub
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReferenceCSV(t *testing.T) {
+	names := []string{"Pikachu", "Chikorita", "Treecko", "Turtwig", "Snivy"}
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pokemons": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"name":       map[string]any{"type": "string"},
+						"generation": map[string]any{"type": "string"},
+					},
+				},
+			},
+			"trainer": map[string]any{"type": "string"},
+		},
+	}
+	g := NewGenerator(
+		WithReferenceCSV("pokemons.items.name", "testdata/pokemon.csv", "name"),
+		WithReferenceCSV("pokemons.items.generation", "testdata/pokemon.csv", "generation"),
+	)
+	for range 20 {
+		stub, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range stub.(map[string]any)["pokemons"].([]any) {
+			pokemon := item.(map[string]any)
+			if !slices.Contains(names, pokemon["name"].(string)) {
+				t.Errorf("expected a name from the CSV, got %v", pokemon["name"])
+			}
+			if !slices.Contains([]string{"1", "2", "3", "4", "5"}, pokemon["generation"].(string)) {
+				t.Errorf("expected a generation from the CSV, got %v", pokemon["generation"])
+			}
+		}
+		if slices.Contains(names, stub.(map[string]any)["trainer"].(string)) {
+			t.Error("expected other fields to be generated as usual")
+		}
+	}
+
+	t.Run("reports unreadable columns", func(t *testing.T) {
+		if _, err := NewGenerator(WithReferenceCSV("name", "testdata/pokemon.csv", "species")).Generate(schema); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
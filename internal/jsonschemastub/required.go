@@ -0,0 +1,74 @@
+package jsonschemastub
+
+import "slices"
+
+// WithRequiredOnly makes Generate produce minimal stubs: objects keep only the
+// properties their schema lists in "required". The kept values are the ones a
+// full stub from the same seed would have, as the full stub is generated and
+// then pruned with RequiredOnly.
+func WithRequiredOnly() GenOption {
+	return func(g *Generator) {
+		g.requiredOnly = true
+	}
+}
+
+// RequiredOnly returns a copy of value, generated from schema, without the
+// object properties that are not listed in their schema's "required".
+// Objects with no "properties", such as additionalProperties maps, are kept
+// whole. "$ref"s resolve against schema.
+func RequiredOnly(schema map[string]any, value any) any {
+	return prune(schema, value, schema)
+}
+
+func prune(schema map[string]any, value any, root map[string]any) any {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := resolveRef(ref, root)
+		if err != nil {
+			return value
+		}
+		schema = resolved
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, ok := schema["properties"].(map[string]any)
+		if !ok {
+			return v
+		}
+		required, _ := schema["required"].([]any)
+		result := make(map[string]any, len(required))
+		for key, item := range v {
+			if !slices.Contains(required, any(key)) {
+				continue
+			}
+			ps, _ := properties[key].(map[string]any)
+			result[key] = prune(ps, item, root)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = prune(itemSchema(schema, i), item, root)
+		}
+		return result
+	}
+	return value
+}
+
+// itemSchema returns the schema of the array item at index i.
+func itemSchema(schema map[string]any, i int) map[string]any {
+	if prefix, ok := schema["prefixItems"].([]any); ok && i < len(prefix) {
+		ps, _ := prefix[i].(map[string]any)
+		return ps
+	}
+	if tuple, ok := schema["items"].([]any); ok {
+		if i < len(tuple) {
+			ps, _ := tuple[i].(map[string]any)
+			return ps
+		}
+		additional, _ := schema["additionalItems"].(map[string]any)
+		return additional
+	}
+	items, _ := schema["items"].(map[string]any)
+	return items
+}
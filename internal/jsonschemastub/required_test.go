@@ -0,0 +1,110 @@
+package jsonschemastub
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+)
+
+func TestRequiredOnlyBuiltSchema(t *testing.T) {
+	const sdl = `type Query { pokemon: Pokemon! trainer: Trainer } type Pokemon { name: String! height: Int } type Trainer { name: String }`
+	schema, err := graphqlschema.BuildSchemaFromSDL(`query Q { pokemon { name height } trainer { name } }`, sdl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minimal, err := NewGenerator(WithSeed(1), WithRequiredOnly()).Generate(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := minimal.(map[string]any)["data"].(map[string]any)
+	pokemon, ok := data["pokemon"].(map[string]any)
+	if !ok || len(data) != 1 || len(pokemon) != 1 || pokemon["name"] == nil {
+		t.Errorf("expected only the non-null data.pokemon.name, got %v", minimal)
+	}
+}
+
+func TestRequiredOnly(t *testing.T) {
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"pokemon"},
+		"properties": map[string]any{
+			"pokemon": map[string]any{
+				"type":     "object",
+				"required": []any{"name", "stats"},
+				"properties": map[string]any{
+					"name":   map[string]any{"type": "string"},
+					"height": map[string]any{"type": "integer"},
+					"stats": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type":       "object",
+							"required":   []any{"base_stat"},
+							"properties": map[string]any{"base_stat": map[string]any{"type": "integer"}, "effort": map[string]any{"type": "integer"}},
+						},
+					},
+				},
+			},
+			"generation": map[string]any{"type": "integer"},
+		},
+	}
+
+	full, err := NewGenerator(WithSeed(1)).Generate(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minimal, err := NewGenerator(WithSeed(1), WithRequiredOnly()).Generate(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pokemon := minimal.(map[string]any)["pokemon"].(map[string]any)
+	if len(minimal.(map[string]any)) != 1 || len(pokemon) != 2 {
+		t.Errorf("expected only required properties, got %v", minimal)
+	}
+	for _, stat := range pokemon["stats"].([]any) {
+		if len(stat.(map[string]any)) != 1 {
+			t.Errorf("expected only base_stat, got %v", stat)
+		}
+	}
+	if !isSubset(minimal, full) {
+		t.Errorf("minimal stub %v is not a subset of full stub %v", minimal, full)
+	}
+
+	t.Run("keeps values without a properties schema whole", func(t *testing.T) {
+		bag := map[string]any{"red-apple": 1, "blue-pear": 2}
+		got := RequiredOnly(map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}}, bag)
+		if !reflect.DeepEqual(got, bag) {
+			t.Errorf("got %v, want %v", got, bag)
+		}
+	})
+}
+
+// isSubset reports whether every object key in a has the same value in b.
+func isSubset(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !isSubset(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !isSubset(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,47 @@
+package jsonschemastub
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WithMaxOutputBytes makes the generator keep stubs to about n bytes of
+// compact JSON. The limit is soft: the size so far is estimated after each
+// value, and once it reaches n, arrays stop gaining items and properties not
+// listed in "required" are omitted, so a stub may still end somewhat over.
+func WithMaxOutputBytes(n int) GenOption {
+	return func(g *Generator) {
+		if n <= 0 {
+			g.err = fmt.Errorf("max output bytes: %d must be positive", n)
+			return
+		}
+		g.maxBytes = n
+	}
+}
+
+// overBudget reports whether the stub being generated has reached the
+// WithMaxOutputBytes limit.
+func (g *Generator) overBudget() bool {
+	return g.maxBytes > 0 && g.size >= g.maxBytes
+}
+
+// account adds the serialized size of v, generated for a property or array
+// item, to the size of the stub. The contents of objects and arrays are
+// accounted for as they are generated, so only their brackets are added.
+func (g *Generator) account(segment string, v any) {
+	if g.maxBytes == 0 {
+		return
+	}
+	if segment == "items" {
+		g.size++ // comma
+	} else {
+		g.size += len(segment) + 4 // quotes, colon and comma
+	}
+	switch v.(type) {
+	case map[string]any, []any:
+		g.size += 2
+	default:
+		out, _ := json.Marshal(v)
+		g.size += len(out)
+	}
+}
@@ -0,0 +1,56 @@
+package jsonschemastub
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMaxOutputBytes(t *testing.T) {
+	stat := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":      map[string]any{"type": "string"},
+			"base_stat": map[string]any{"type": "integer"},
+			"effort":    map[string]any{"type": "integer"},
+		},
+	}
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"id"},
+		"properties": map[string]any{
+			"id":     map[string]any{"type": "integer"},
+			"name":   map[string]any{"type": "string"},
+			"stats":  map[string]any{"type": "array", "minItems": 20.0, "maxItems": 20.0, "items": stat},
+			"zmoves": map[string]any{"type": "array", "minItems": 20.0, "maxItems": 20.0, "items": stat},
+		},
+	}
+	size := func(t *testing.T, opts ...GenOption) (int, map[string]any) {
+		t.Helper()
+		stub, err := NewGenerator(append(opts, WithSeed(1))...).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, _ := json.Marshal(stub)
+		return len(out), stub.(map[string]any)
+	}
+
+	if n, _ := size(t); n < 1000 {
+		t.Fatalf("expected an unlimited stub over 1000 bytes, got %d", n)
+	}
+	n, stub := size(t, WithMaxOutputBytes(100))
+	if n >= 200 {
+		t.Errorf("expected a stub under 200 bytes with a 100-byte limit, got %d", n)
+	}
+	if _, ok := stub["id"]; !ok {
+		t.Errorf("expected required properties to be kept, got %v", stub)
+	}
+	if zmoves, ok := stub["zmoves"]; ok {
+		t.Errorf("expected properties past the limit to be omitted, got zmoves %v", zmoves)
+	}
+
+	t.Run("rejects non-positive limits", func(t *testing.T) {
+		if _, err := NewGenerator(WithMaxOutputBytes(0)).Generate(schema); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -1,63 +1,570 @@
 package jsonschemastub
 
 import (
+	"cmp"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"math/rand"
+	"slices"
 	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/dictionary"
 )
 
-var words = []string{
-	"azure", "blaze", "cedar", "dusk", "ember", "frost", "gale", "haze",
-	"iris", "jade", "kite", "lark", "mist", "nova", "onyx", "pine",
-	"quill", "rune", "sage", "thorn", "umber", "vale", "wren", "zeal",
+//go:embed words/*.txt
+var wordFiles embed.FS
+
+// wordLists maps a locale tag to the words used to build generated strings.
+var wordLists = loadWordLists()
+
+func loadWordLists() map[string][]string {
+	lists := map[string][]string{}
+	entries, _ := wordFiles.ReadDir("words")
+	for _, entry := range entries {
+		data, _ := wordFiles.ReadFile("words/" + entry.Name())
+		lists[strings.TrimSuffix(entry.Name(), ".txt")] = strings.Fields(string(data))
+	}
+	return lists
+}
+
+// Generator produces stub values from JSON Schemas using its own random source.
+// A Generator is not safe for concurrent use.
+type Generator struct {
+	rand     *rand.Rand
+	words    []string
+	warnings []string
+
+	// err is a configuration error reported by the next call to Generate.
+	err error
+
+	// ignoreUnknownKeywords controls whether unrecognised top-level keywords
+	// produce a warning (true) or an error (false).
+	ignoreUnknownKeywords bool
+
+	// nullableTypes enables nil values for union types that include "null".
+	nullableTypes bool
+
+	// typenameDiscriminator generates one "anyOf" variant of objects, with
+	// its __typename.
+	typenameDiscriminator bool
+
+	// requiredValidation fails generation when a field marked non-null could
+	// be nil.
+	requiredValidation bool
+
+	// firstEnumValue always picks the first "enum" value instead of a random one.
+	firstEnumValue bool
+
+	// useDefaults generates a schema's "default" value when it has one.
+	useDefaults bool
+
+	// preferExamples picks from a schema's "examples" when it has any.
+	preferExamples bool
+
+	// depth is the nesting level of the object or array being generated;
+	// minDepth and maxDepth bound it, with zero meaning no bound.
+	depth, minDepth, maxDepth int
+
+	// dateFrom and dateTo bound generated dates; both zero means fixed dates.
+	dateFrom, dateTo time.Time
+
+	// draft is the JSON Schema draft of the document being generated.
+	draft string
+
+	// nameAware picks string formats from "x-field-name" metadata.
+	nameAware bool
+
+	// groupSeparator separates thousands in "formatted-number" strings, and
+	// currencySymbol prefixes "currency" strings.
+	groupSeparator string
+	currencySymbol string
+
+	// path holds the property names, and "items" for array items, leading to
+	// the value being generated.
+	path []string
+
+	// stats records per-path timings under WithGenerationStats, and deepest
+	// is the deepest nesting level reached since the current field began.
+	stats   map[string]*GenerationStat
+	deepest int
+
+	// requiredOnly prunes stubs to the properties listed in "required".
+	requiredOnly bool
+
+	// values holds the last value generated at each path, for
+	// "x-stub-same-as" to reuse.
+	values map[string]any
+
+	// cycles tracks each path's position in its "x-stub-values", and
+	// shuffleValues shuffles those lists before they are first used.
+	cycles        map[string]*valueCycle
+	shuffleValues bool
+
+	// objects holds the objects being generated, innermost last, whose
+	// properties "x-stub-template"s render; templates caches their parsed
+	// templates, nil for invalid ones.
+	objects   []map[string]any
+	templates map[string]*template.Template
+
+	// uniqueIDs keeps ID fields unique within arrays; usedIDs holds, for each
+	// array being generated, innermost last, the IDs used at each path.
+	uniqueIDs bool
+	usedIDs   []map[string]map[any]bool
+
+	// references holds the WithReferenceCSV values for each field path.
+	references map[string][]string
+
+	// dictionary holds the WithDictionary values for field names.
+	dictionary *dictionary.DictionaryMatcher
+
+	// recorder writes generated values for WithRecording; replayValues and
+	// replayLengths queue the values and array lengths read by WithReplay.
+	recorder      *json.Encoder
+	replayValues  map[string][]any
+	replayLengths map[string][]int
+
+	// maxBytes is the WithMaxOutputBytes limit, zero for none, and size the
+	// estimated size of the stub generated so far.
+	maxBytes, size int
+
+	// sequences counts the integers generated at each path under
+	// WithSequentialIntegers, and is nil without it.
+	sequences map[string]int
+
+	// minAdditionalProperties and maxAdditionalProperties bound the number
+	// of entries generated for "additionalProperties" maps.
+	minAdditionalProperties, maxAdditionalProperties int
+
+	// maxArrayItems caps array lengths when positive; see WithMaxArrayItems.
+	maxArrayItems int
+
+	// arraySizes holds the WithSemanticArraySizes rules, sorted by pattern.
+	arraySizes []arraySizeRule
+
+	// root is the document being generated, against which "$ref"s resolve.
+	// refDepth counts the "$ref"s being followed, and refErr holds the first
+	// one that could not be.
+	root     map[string]any
+	refDepth int
+	refErr   error
+}
+
+// GenOption configures a Generator.
+type GenOption func(*Generator)
+
+// WithSeed makes the generator produce the same values for the same seed.
+func WithSeed(seed int64) GenOption {
+	return func(g *Generator) {
+		g.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithStrictKeywords makes Generate fail on schemas whose top-level keywords
+// it does not recognise, instead of warning and ignoring them. Use it to catch
+// schemas from newer drafts whose keyword semantics the generator may not share.
+func WithStrictKeywords() GenOption {
+	return func(g *Generator) {
+		g.ignoreUnknownKeywords = false
+	}
+}
+
+// nullableChance is the probability of generating nil for a nullable union type.
+const nullableChance = 0.3
+
+// WithNullableTypes makes the generator produce nil for 30% of values whose
+// type is a union including "null", such as ["integer", "null"]. Unlike a
+// null probability applied to optional properties, this follows the types
+// declared by the schema, including those of array items.
+func WithNullableTypes() GenOption {
+	return func(g *Generator) {
+		g.nullableTypes = true
+	}
+}
+
+// WithFirstEnumValue makes enums always generate their first value, keeping
+// snapshot tests stable while other values stay random.
+func WithFirstEnumValue() GenOption {
+	return func(g *Generator) {
+		g.firstEnumValue = true
+	}
+}
+
+// WithUseDefaults generates a schema's "default" value, where it has one,
+// instead of a random value, as a client omitting the field would get.
+func WithUseDefaults() GenOption {
+	return func(g *Generator) {
+		g.useDefaults = true
+	}
+}
+
+// WithPreferExamples generates a random one of a schema's "examples", where
+// it has any, instead of a new value, so hand-crafted example data is kept.
+func WithPreferExamples() GenOption {
+	return func(g *Generator) {
+		g.preferExamples = true
+	}
+}
+
+// pickEnum returns a random enum value, or the first with WithFirstEnumValue.
+func (g *Generator) pickEnum(enum []any) any {
+	if g.firstEnumValue {
+		return enum[0]
+	}
+	return enum[g.rand.Intn(len(enum))]
+}
+
+// WithMaxStubDepth stops generation from recursing into objects and arrays
+// nested deeper than n levels below the root; they are generated empty.
+func WithMaxStubDepth(n int) GenOption {
+	return func(g *Generator) {
+		g.maxDepth = n
+	}
+}
+
+// WithMinStubDepth guarantees content in the first n levels below the root:
+// arrays there have at least one item and nullable values are never nil.
+func WithMinStubDepth(n int) GenOption {
+	return func(g *Generator) {
+		g.minDepth = n
+	}
+}
+
+// enter records descent into an object or array, the root being at depth 0.
+// It reports false, without descending, when the value lies beyond the
+// maximum depth.
+func (g *Generator) enter() bool {
+	if g.maxDepth > 0 && g.depth > g.maxDepth {
+		return false
+	}
+	g.depth++
+	g.deepest = max(g.deepest, g.depth)
+	return true
 }
 
-func pick(arr []string) string {
-	return arr[rand.Intn(len(arr))]
+func (g *Generator) leave() {
+	g.depth--
 }
 
-func randInt(min, max int) int {
-	return rand.Intn(max-min+1) + min
+// belowMinDepth reports whether the current position must be filled in to
+// satisfy WithMinStubDepth.
+func (g *Generator) belowMinDepth() bool {
+	return g.minDepth > 0 && g.depth <= g.minDepth
 }
 
-func randFloat(min, max float64) float64 {
-	v := rand.Float64()*(max-min) + min
+// GenerationStat is how a field path was generated: how often, in how many
+// nanoseconds in total including nested values, and how many levels deep its
+// nested objects and arrays went at most.
+type GenerationStat struct {
+	Count    int
+	TotalNs  int64
+	MaxDepth int
+}
+
+// WithGenerationStats records a GenerationStat for every field path, such as
+// "data.pokemon.items.name", available from Stats. It is a diagnostic for
+// finding the fields that make large schemas slow to generate.
+func WithGenerationStats() GenOption {
+	return func(g *Generator) {
+		g.stats = map[string]*GenerationStat{}
+	}
+}
+
+// Stats returns the statistics recorded under WithGenerationStats, keyed by
+// field path, or nil without it.
+func (g *Generator) Stats() map[string]GenerationStat {
+	if g.stats == nil {
+		return nil
+	}
+	stats := make(map[string]GenerationStat, len(g.stats))
+	for path, stat := range g.stats {
+		stats[path] = *stat
+	}
+	return stats
+}
+
+// generateAt generates the value of a property, or of array items when
+// segment is "items", tracking its path.
+func (g *Generator) generateAt(segment string, schema map[string]any) any {
+	g.path = append(g.path, segment)
+	defer func() { g.path = g.path[:len(g.path)-1] }()
+	path := strings.Join(g.path, ".")
+	var v any
+	if recorded, ok := g.replayed(path); ok {
+		v = recorded
+	} else if values, ok := g.references[path]; ok {
+		v = g.pick(values)
+	} else if values, ok := g.dictionaryValues(segment); ok {
+		v = values[g.rand.Intn(len(values))]
+	} else if g.stats == nil {
+		v = g.generate(schema)
+	} else {
+		v = g.generateWithStats(path, schema)
+	}
+	v = g.uniqueID(path, schema, v)
+	g.record(path, v)
+	g.values[path] = v
+	g.account(segment, v)
+	return v
+}
+
+// generateWithStats generates the value at path, recording its statistics.
+func (g *Generator) generateWithStats(path string, schema map[string]any) any {
+	outer := g.deepest
+	g.deepest = g.depth
+	start := time.Now()
+	v := g.generate(schema)
+	elapsed := time.Since(start)
+
+	stat := g.stats[path]
+	if stat == nil {
+		stat = &GenerationStat{}
+		g.stats[path] = stat
+	}
+	stat.Count++
+	stat.TotalNs += elapsed.Nanoseconds()
+	stat.MaxDepth = max(stat.MaxDepth, g.deepest-g.depth)
+	g.deepest = max(outer, g.deepest)
+	return v
+}
+
+// WithMaxArrayItems caps the number of items generated in any array at n,
+// whatever its "maxItems" or the default. Generate fails on arrays whose
+// "minItems" is above the cap. Tuples keep their declared length.
+func WithMaxArrayItems(n int) GenOption {
+	return func(g *Generator) {
+		if n < 1 {
+			g.err = fmt.Errorf("max array items: %d must be at least 1", n)
+			return
+		}
+		g.maxArrayItems = n
+	}
+}
+
+// WithAdditionalPropertiesCount sets how many entries, between min and max
+// inclusive, are generated for objects that have no "properties" but an
+// "additionalProperties" schema. The default is 2 to 5.
+func WithAdditionalPropertiesCount(min, max int) GenOption {
+	return func(g *Generator) {
+		if min < 0 || min > max {
+			g.err = fmt.Errorf("additional properties count: minimum %d must be between 0 and the maximum %d", min, max)
+			return
+		}
+		g.minAdditionalProperties, g.maxAdditionalProperties = min, max
+	}
+}
+
+// WithDateRange makes "date" and "date-time" strings uniformly distributed
+// between from and to instead of fixed. Both bounds must be set, and to must
+// not be before from.
+func WithDateRange(from, to time.Time) GenOption {
+	return func(g *Generator) {
+		switch {
+		case from.IsZero() || to.IsZero():
+			g.err = errors.New("date range: both the start and the end must be set")
+			return
+		case to.Before(from):
+			g.err = fmt.Errorf("date range: end %s is before start %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+			return
+		}
+		g.dateFrom, g.dateTo = from, to
+	}
+}
+
+// WithLocale builds generated strings from the word list for a BCP 47
+// language tag such as "en" or "fr". Regional variants like "fr-CA" fall
+// back to their base language. The default is "en".
+func WithLocale(locale string) GenOption {
+	return func(g *Generator) {
+		base, _, _ := strings.Cut(strings.ToLower(locale), "-")
+		words, ok := wordLists[base]
+		if !ok {
+			g.err = fmt.Errorf("unsupported locale %q", locale)
+			return
+		}
+		g.words = words
+		g.groupSeparator = groupSeparators[base]
+	}
+}
+
+// groupSeparators are the thousands separators used by the
+// "formatted-number" format, by locale.
+var groupSeparators = map[string]string{"en": ",", "fr": " "}
+
+// WithNameAwareGeneration picks a string format from a field's name when the
+// schema gives none, using the "x-field-name" that graphqlschema.WithFieldNames
+// adds: names ending in "_at" or "_time" get date-times, "_url" or "_uri"
+// get URIs, "email" gets an email address, and pagination cursors such as
+// end_cursor, after and before get "cursor" strings.
+func WithNameAwareGeneration() GenOption {
+	return func(g *Generator) {
+		g.nameAware = true
+	}
+}
+
+// formatForName returns the string format suggested by a field name.
+func formatForName(name string) (string, bool) {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, "_at"), strings.HasSuffix(name, "_time"):
+		return "date-time", true
+	case strings.HasSuffix(name, "_url"), strings.HasSuffix(name, "_uri"):
+		return "uri", true
+	case name == "email":
+		return "email", true
+	case strings.HasSuffix(name, "cursor"), name == "after", name == "before":
+		return "cursor", true
+	}
+	return "", false
+}
+
+// WithCurrencySymbol sets the symbol prefixed to "currency" strings, "$" by
+// default.
+func WithCurrencySymbol(symbol string) GenOption {
+	return func(g *Generator) {
+		g.currencySymbol = symbol
+	}
+}
+
+// NewGenerator returns a Generator configured by opts. Without WithSeed the
+// generator is seeded from the current time.
+func NewGenerator(opts ...GenOption) *Generator {
+	g := &Generator{
+		rand:                    rand.New(rand.NewSource(time.Now().UnixNano())),
+		words:                   wordLists["en"],
+		groupSeparator:          groupSeparators["en"],
+		currencySymbol:          "$",
+		minAdditionalProperties: 2,
+		maxAdditionalProperties: 5,
+		ignoreUnknownKeywords:   true,
+		values:                  map[string]any{},
+		cycles:                  map[string]*valueCycle{},
+		templates:               map[string]*template.Template{},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Warnings returns the problems noticed while generating, such as schema
+// constraints that cannot be satisfied. Generation proceeds best-effort.
+func (g *Generator) Warnings() []string {
+	return g.warnings
+}
+
+func (g *Generator) warnf(format string, args ...any) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
+func (g *Generator) pick(arr []string) string {
+	return arr[g.rand.Intn(len(arr))]
+}
+
+func (g *Generator) randInt(min, max int) int {
+	return g.rand.Intn(max-min+1) + min
+}
+
+func (g *Generator) randFloat(min, max float64) float64 {
+	v := g.rand.Float64()*(max-min) + min
 	f, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", v), 64)
 	return f
 }
 
-func generateString(schema map[string]any) string {
+func (g *Generator) hasDateRange() bool {
+	return !g.dateFrom.IsZero() || !g.dateTo.IsZero()
+}
+
+// randTime returns a UTC time in the configured date range, to the second.
+func (g *Generator) randTime() time.Time {
+	from, to := g.dateFrom.Unix(), g.dateTo.Unix()
+	return time.Unix(from+g.rand.Int63n(to-from+1), 0).UTC()
+}
+
+func (g *Generator) generateString(schema map[string]any) string {
+	if values, ok := schema[valuesKeyword].([]any); ok && len(values) > 0 {
+		return g.nextValue(values)
+	}
+	if text, ok := schema[templateKeyword].(string); ok {
+		if s, ok := g.renderTemplate(text); ok {
+			return s
+		}
+	}
 	if enum, ok := schema["enum"].([]any); ok {
-		return enum[rand.Intn(len(enum))].(string)
+		return g.pickEnum(enum).(string)
 	}
-	if format, ok := schema["format"].(string); ok {
+	format, ok := schema["format"].(string)
+	if !ok && g.nameAware {
+		if name, isNamed := schema["x-field-name"].(string); isNamed {
+			format, ok = formatForName(name)
+		}
+	}
+	if ok {
 		switch format {
 		case "date":
+			if g.hasDateRange() {
+				return g.randTime().Format(time.DateOnly)
+			}
 			return "2024-01-01"
 		case "date-time":
+			if g.hasDateRange() {
+				return g.randTime().Format(time.RFC3339)
+			}
 			return "2024-01-01T00:00:00Z"
 		case "email":
-			return pick(words) + "@example.com"
+			return g.pick(g.words) + "@example.com"
 		case "uri":
-			return "https://example.com/" + pick(words)
+			return "https://example.com/" + g.pick(g.words)
+		case "currency":
+			return g.currencySymbol + strconv.FormatFloat(g.randFloat(1, 1000), 'f', 2, 64)
+		case "percentage":
+			return strconv.FormatFloat(g.randFloat(0, 100), 'f', 1, 64) + "%"
+		case "formatted-number":
+			return groupDigits(g.randInt(1000, 9999999), g.groupSeparator)
+		case "cursor":
+			// Opaque like real cursors: a base64-encoded offset.
+			return base64.StdEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(g.randInt(0, 999))))
 		}
 	}
-	return pick(words) + "-" + pick(words)
+	return g.pick(g.words) + "-" + g.pick(g.words)
 }
 
-func generateInteger(schema map[string]any) int {
+// groupDigits formats n with sep between each group of three digits.
+func groupDigits(n int, sep string) string {
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+func (g *Generator) generateInteger(schema map[string]any) int {
 	min := 1
 	max := 255
 	if v, ok := schema["minimum"].(float64); ok {
 		min = int(v)
 	}
-	if v, ok := schema["maximum"].(float64); ok {
+	v, bounded := schema["maximum"].(float64)
+	if bounded {
 		max = int(v)
 	}
-	return randInt(min, max)
+	if g.sequences != nil {
+		return g.nextInSequence(min, max, bounded)
+	}
+	return g.randInt(min, max)
 }
 
-func generateNumber(schema map[string]any) float64 {
+func (g *Generator) generateNumber(schema map[string]any) float64 {
 	min := 0.1
 	max := 2.0
 	if v, ok := schema["minimum"].(float64); ok {
@@ -66,10 +573,37 @@ func generateNumber(schema map[string]any) float64 {
 	if v, ok := schema["maximum"].(float64); ok {
 		max = v
 	}
-	return randFloat(min, max)
+	return g.randFloat(min, max)
+}
+
+// detectDraft reports which JSON Schema draft a document is written against,
+// based on its $schema URI. Documents without a recognised URI are treated
+// as draft-07, the draft BuildSchema emits.
+func detectDraft(schema map[string]any) string {
+	uri, _ := schema["$schema"].(string)
+	if strings.Contains(uri, "2020-12") {
+		return "2020-12"
+	}
+	return "draft-07"
 }
 
-func generateArray(schema map[string]any) []any {
+func (g *Generator) generateArray(schema map[string]any) []any {
+	// prefixItems is a draft 2020-12 keyword, but an array described by
+	// nothing else, such as a [timestamp, value] pair nested in a draft-07
+	// time series, is generated as the tuple it was evidently meant to be.
+	if prefix, ok := schema["prefixItems"].([]any); ok && (g.draft == "2020-12" || schema["items"] == nil) {
+		return g.generateTuple(schema, prefix)
+	}
+
+	if tuple, ok := schema["items"].([]any); ok {
+		additional, _ := schema["additionalItems"].(map[string]any)
+		count := len(tuple)
+		if v, ok := schema["maxItems"].(float64); ok && additional != nil && int(v) > count {
+			count = int(v)
+		}
+		return g.generateHeterogeneousArray(tuple, additional, count)
+	}
+
 	itemSchema := map[string]any{}
 	if items, ok := schema["items"].(map[string]any); ok {
 		itemSchema = items
@@ -77,45 +611,187 @@ func generateArray(schema map[string]any) []any {
 
 	minItems := 1
 	maxItems := 3
+	semantic := false
+	if min, max, ok := g.semanticArraySize(); ok {
+		minItems, maxItems, semantic = min, max, true
+	}
 	if v, ok := schema["minItems"].(float64); ok {
 		minItems = int(v)
+		if semantic {
+			maxItems = max(maxItems, minItems)
+		}
 	}
 	if v, ok := schema["maxItems"].(float64); ok {
 		maxItems = int(v)
+		if semantic {
+			minItems = min(minItems, maxItems)
+		}
+	}
+	if g.maxArrayItems > 0 && maxItems > g.maxArrayItems {
+		if minItems > g.maxArrayItems {
+			g.failRef(fmt.Errorf("array at %q: minItems %d exceeds the maximum of %d array items", strings.Join(g.path, "."), minItems, g.maxArrayItems))
+			return nil
+		}
+		maxItems = g.maxArrayItems
 	}
 
-	length := randInt(minItems, maxItems)
+	path := strings.Join(g.path, ".")
+	length, ok := g.replayedLength(path)
+	if !ok {
+		length = g.randInt(minItems, maxItems)
+		if length == 0 && g.belowMinDepth() {
+			length = 1
+		}
+	}
+	g.recordLength(path, length)
+	// The length is known up front, so size the slice once rather than
+	// appending. Under WithMaxOutputBytes the array ends early at the limit.
+	defer g.enterArray()()
 	result := make([]any, length)
 	for i := range result {
-		result[i] = Generate(itemSchema)
+		if g.overBudget() {
+			return result[:i]
+		}
+		result[i] = g.generateAt("items", itemSchema)
+	}
+	return result
+}
+
+// generateHeterogeneousArray produces count values for a draft-07 tuple,
+// where "items" is an array of schemas. Position i uses itemSchemas[i], and
+// positions past the end use additional; without it the result stops at
+// len(itemSchemas).
+func (g *Generator) generateHeterogeneousArray(itemSchemas []any, additional map[string]any, count int) []any {
+	result := make([]any, 0, count)
+	for i := 0; i < count; i++ {
+		switch {
+		case i < len(itemSchemas):
+			is, _ := itemSchemas[i].(map[string]any)
+			result = append(result, g.generateAt("items", is))
+		case additional != nil:
+			result = append(result, g.generateAt("items", additional))
+		default:
+			return result
+		}
+	}
+	return result
+}
+
+// generateTuple produces one value per prefixItems schema. When the array
+// also has an items schema, up to maxItems further values are appended.
+func (g *Generator) generateTuple(schema map[string]any, prefix []any) []any {
+	result := make([]any, 0, len(prefix))
+	for _, p := range prefix {
+		ps, _ := p.(map[string]any)
+		result = append(result, g.generateAt("items", ps))
+	}
+	rest, ok := schema["items"].(map[string]any)
+	if !ok {
+		return result
+	}
+	maxItems := len(prefix)
+	if v, ok := schema["maxItems"].(float64); ok {
+		maxItems = int(v)
+	}
+	for len(result) < maxItems {
+		result = append(result, g.generateAt("items", rest))
 	}
 	return result
 }
 
-func generateObject(schema map[string]any) map[string]any {
+func (g *Generator) generateObject(schema map[string]any) map[string]any {
 	result := map[string]any{}
 	properties, ok := schema["properties"].(map[string]any)
 	if !ok {
+		if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+			return g.generatePropertyBag(additional)
+		}
 		return result
 	}
-	for key, propSchema := range properties {
-		if ps, ok := propSchema.(map[string]any); ok {
-			result[key] = Generate(ps)
+	// Visit keys in a fixed order so a seeded generator is reproducible, with
+	// the properties copying a sibling's value last so it exists, and
+	// templated properties after the siblings they render.
+	keys := slices.Sorted(maps.Keys(properties))
+	slices.SortStableFunc(keys, func(a, b string) int {
+		return cmp.Compare(hasSameAs(properties[a]), hasSameAs(properties[b]))
+	})
+	keys = g.dependencyOrder(keys, properties)
+	g.objects = append(g.objects, result)
+	defer func() { g.objects = g.objects[:len(g.objects)-1] }()
+	required, _ := schema["required"].([]any)
+	for _, key := range keys {
+		if g.overBudget() && !slices.Contains(required, any(key)) {
+			continue
+		}
+		if ps, ok := properties[key].(map[string]any); ok {
+			result[key] = g.generateAt(key, ps)
 		}
 	}
+	g.addVariant(schema, result)
 	return result
 }
 
-// Generate produces a stub value matching the given JSON Schema.
-func Generate(schema map[string]any) any {
-	if schema == nil {
-		return nil
+// generatePropertyBag produces an open map, for an object schema with no
+// "properties" but an "additionalProperties" schema: between the minimum and
+// maximum set by WithAdditionalPropertiesCount of word-pair keys, each with a
+// value matching additional.
+func (g *Generator) generatePropertyBag(additional map[string]any) map[string]any {
+	n := g.randInt(g.minAdditionalProperties, g.maxAdditionalProperties)
+	if n == 0 && g.belowMinDepth() {
+		n = 1
+	}
+	result := make(map[string]any, n)
+	for range n {
+		base := g.pick(g.words) + "-" + g.pick(g.words)
+		key := base
+		for i := 2; ; i++ {
+			if _, taken := result[key]; !taken {
+				break
+			}
+			key = base + "-" + strconv.Itoa(i)
+		}
+		result[key] = g.generateAt(key, additional)
 	}
+	return result
+}
 
-	if enum, ok := schema["enum"].([]any); ok {
-		return enum[rand.Intn(len(enum))]
+// sameAsKeyword names the path of a previously generated value that a field
+// reuses instead of generating its own, keeping related values consistent.
+// Paths are dot-separated property names, with "items" for array items, such
+// as "data.trainer.pokemon_count".
+const sameAsKeyword = "x-stub-same-as"
+
+// hasSameAs returns 1 for property schemas with "x-stub-same-as", else 0.
+func hasSameAs(schema any) int {
+	if ps, ok := schema.(map[string]any); ok {
+		if _, ok := ps[sameAsKeyword]; ok {
+			return 1
+		}
+	}
+	return 0
+}
+
+// maxExclusionAttempts bounds how often a value rejected by "not" is regenerated
+// before falling back to a numbered suffix.
+const maxExclusionAttempts = 100
+
+// generateStringExcluding produces a string that is not one of the excluded values.
+func (g *Generator) generateStringExcluding(schema map[string]any, excluded []any) string {
+	isExcluded := func(s string) bool { return slices.Contains(excluded, any(s)) }
+	s := g.generateString(schema)
+	for i := 0; i < maxExclusionAttempts && isExcluded(s); i++ {
+		s = g.generateString(schema)
 	}
+	base := s
+	for n := 2; isExcluded(s); n++ {
+		s = base + "-" + strconv.Itoa(n)
+	}
+	return s
+}
 
+// schemaType returns the schema's type, preferring the first non-null entry
+// of a union type.
+func schemaType(schema map[string]any) string {
 	var t string
 	switch v := schema["type"].(type) {
 	case string:
@@ -131,23 +807,239 @@ func Generate(schema map[string]any) any {
 			t, _ = v[0].(string)
 		}
 	}
+	return t
+}
+
+// isNullable reports whether the schema's type is a union that includes "null".
+func isNullable(schema map[string]any) bool {
+	types, ok := schema["type"].([]any)
+	return ok && slices.Contains(types, any("null"))
+}
+
+// knownKeywords are every draft-07 keyword, which the generator either
+// understands or can safely ignore, and the 2020-12 keywords it understands.
+// Extension keywords prefixed with "x-" are always accepted.
+var knownKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$ref": true, "$comment": true, "$vocabulary": true,
+	"$defs": true, "definitions": true, "title": true, "description": true, "examples": true,
+	"default": true, "readOnly": true, "writeOnly": true,
+	"type": true, "enum": true, "const": true, "format": true,
+	"contentMediaType": true, "contentEncoding": true,
+	"allOf": true, "anyOf": true, "oneOf": true, "not": true, "if": true, "then": true, "else": true,
+	"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true, "multipleOf": true,
+	"minLength": true, "maxLength": true, "pattern": true,
+	"items": true, "prefixItems": true, "additionalItems": true, "contains": true,
+	"minItems": true, "maxItems": true, "uniqueItems": true,
+	"properties": true, "additionalProperties": true, "patternProperties": true, "required": true,
+	"minProperties": true, "maxProperties": true, "dependencies": true, "propertyNames": true,
+}
+
+// unknownKeywords returns the schema's top-level keywords that the generator
+// does not recognise, in sorted order.
+func unknownKeywords(schema map[string]any) []string {
+	var unknown []string
+	for _, key := range slices.Sorted(maps.Keys(schema)) {
+		if !knownKeywords[key] && !strings.HasPrefix(key, "x-") {
+			unknown = append(unknown, key)
+		}
+	}
+	return unknown
+}
+
+// Generate produces a stub value matching the given JSON Schema.
+func (g *Generator) Generate(schema map[string]any) (any, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	if unknown := unknownKeywords(schema); len(unknown) > 0 {
+		if !g.ignoreUnknownKeywords {
+			return nil, fmt.Errorf("unrecognised schema keywords: %s", strings.Join(unknown, ", "))
+		}
+		g.warnf("ignoring unrecognised schema keywords: %s", strings.Join(unknown, ", "))
+	}
+	g.draft = detectDraft(schema)
+	g.root, g.refErr = schema, nil
+	g.values = map[string]any{}
+	g.size = 0
+	v := g.generate(schema)
+	if g.refErr != nil {
+		return nil, g.refErr
+	}
+	if g.requiredOnly {
+		v = RequiredOnly(schema, v)
+	}
+	return v, nil
+}
+
+// maxRefDepth bounds how many "$ref"s are followed within one another, so a
+// recursive schema fails instead of recursing forever.
+const maxRefDepth = 32
+
+// generateRef generates a value for the schema a "$ref" points to.
+func (g *Generator) generateRef(ref string) any {
+	if g.refDepth >= maxRefDepth {
+		g.failRef(fmt.Errorf("$ref %q: more than %d nested references; is the schema recursive?", ref, maxRefDepth))
+		return nil
+	}
+	resolved, err := resolveRef(ref, g.root)
+	if err != nil {
+		g.failRef(err)
+		return nil
+	}
+	g.refDepth++
+	defer func() { g.refDepth-- }()
+	return g.generate(resolved)
+}
+
+func (g *Generator) failRef(err error) {
+	if g.refErr == nil {
+		g.refErr = err
+	}
+}
+
+// resolveRef resolves a JSON Pointer "$ref" such as "#/definitions/Pokemon"
+// against the document root. References to other documents are not
+// supported.
+func resolveRef(ref string, root map[string]any) (map[string]any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("$ref %q: external references are not supported; only references within the schema (\"#/...\") are", ref)
+	}
+	var node any = root
+	if pointer != "" {
+		if !strings.HasPrefix(pointer, "/") {
+			return nil, fmt.Errorf("$ref %q: not a JSON Pointer", ref)
+		}
+		for _, token := range strings.Split(pointer[1:], "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			switch n := node.(type) {
+			case map[string]any:
+				node, ok = n[token]
+			case []any:
+				i, err := strconv.Atoi(token)
+				ok = err == nil && i >= 0 && i < len(n)
+				if ok {
+					node = n[i]
+				}
+			default:
+				ok = false
+			}
+			if !ok {
+				return nil, fmt.Errorf("$ref %q: %q not found", ref, token)
+			}
+		}
+	}
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("$ref %q: does not point to a schema", ref)
+	}
+	return schema, nil
+}
+
+// nullChance returns the probability of generating nil for schema: the
+// field's own "x-stub-null-prob" when set, otherwise nullableChance for
+// nullable types under WithNullableTypes. Fields marked "x-nullable": false
+// are never nil.
+func (g *Generator) nullChance(schema map[string]any) float64 {
+	if g.belowMinDepth() {
+		return 0
+	}
+	if p, ok := schema["x-stub-null-prob"].(float64); ok {
+		return g.nonNullChance(schema, p)
+	}
+	if g.nullableTypes && isNullable(schema) {
+		return g.nonNullChance(schema, nullableChance)
+	}
+	return 0
+}
+
+func (g *Generator) generate(schema map[string]any) any {
+	if schema == nil {
+		return nil
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		return g.generateRef(ref)
+	}
+
+	if path, ok := schema[sameAsKeyword].(string); ok {
+		if v, ok := g.values[path]; ok {
+			return v
+		}
+		g.warnf("%s %q: no value generated there before this field; generating a new one", sameAsKeyword, path)
+	}
+
+	if p := g.nullChance(schema); p > 0 && g.rand.Float64() < p {
+		return nil
+	}
+
+	t := schemaType(schema)
+
+	// "not" is honoured best-effort: excluded string enums are avoided, and a
+	// "not" that rules out the declared type is reported as a warning.
+	if not, ok := schema["not"].(map[string]any); ok {
+		if excluded, ok := not["enum"].([]any); ok {
+			if enum, ok := schema["enum"].([]any); ok {
+				allowed := slices.DeleteFunc(slices.Clone(enum), func(v any) bool {
+					return slices.Contains(excluded, v)
+				})
+				if len(allowed) > 0 {
+					return g.pickEnum(allowed)
+				}
+				g.warnf("\"not\" excludes every enum value; ignoring it")
+			} else if t == "string" || t == "" {
+				return g.generateStringExcluding(schema, excluded)
+			}
+		}
+		if nt := schemaType(not); nt != "" && nt == t {
+			g.warnf("\"not\" excludes the declared type %q; ignoring it", t)
+		}
+	}
+
+	if v, ok := schema["const"]; ok {
+		return v
+	}
+	if v, ok := schema["default"]; ok && g.useDefaults {
+		return v
+	}
+	if examples, ok := schema["examples"].([]any); ok && len(examples) > 0 && g.preferExamples {
+		return examples[g.rand.Intn(len(examples))]
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		return g.pickEnum(enum)
+	}
 
 	switch t {
 	case "object":
-		return generateObject(schema)
+		if !g.enter() {
+			return map[string]any{}
+		}
+		defer g.leave()
+		return g.generateObject(schema)
 	case "array":
-		return generateArray(schema)
+		if !g.enter() {
+			return []any{}
+		}
+		defer g.leave()
+		return g.generateArray(schema)
 	case "string":
-		return generateString(schema)
+		return g.generateString(schema)
 	case "integer":
-		return generateInteger(schema)
+		return g.generateInteger(schema)
 	case "number":
-		return generateNumber(schema)
+		return g.generateNumber(schema)
 	case "boolean":
-		return rand.Float64() < 0.5
+		return g.rand.Float64() < 0.5
 	case "null":
 		return nil
 	default:
 		return nil
 	}
 }
+
+// Generate produces a stub value matching the given JSON Schema using a
+// freshly seeded Generator. Unrecognised keywords are ignored.
+func Generate(schema map[string]any) any {
+	v, _ := NewGenerator().Generate(schema)
+	return v
+}
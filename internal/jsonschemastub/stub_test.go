@@ -1,9 +1,15 @@
 package jsonschemastub
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"math"
 	"regexp"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGenerate(t *testing.T) {
@@ -36,6 +42,35 @@ func TestGenerate(t *testing.T) {
 		}
 	})
 
+	t.Run("uses const when present", func(t *testing.T) {
+		if got := Generate(map[string]any{"type": "string", "const": "Pokemon"}); got != "Pokemon" {
+			t.Errorf("expected Pokemon, got %v", got)
+		}
+	})
+
+	t.Run("uses default values with WithUseDefaults", func(t *testing.T) {
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"limit":  map[string]any{"type": "integer", "default": 10.0},
+				"region": map[string]any{"type": "string", "default": nil},
+			},
+		}
+		stub, err := NewGenerator(WithUseDefaults()).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := stub.(map[string]any)
+		if got["limit"] != 10.0 || got["region"] != nil {
+			t.Errorf("expected the defaults, got %v", got)
+		}
+		for range 20 {
+			if stub, _ := NewGenerator().Generate(schema); stub.(map[string]any)["region"] == nil {
+				t.Fatal("expected defaults to be ignored without WithUseDefaults")
+			}
+		}
+	})
+
 	t.Run("handles union types, ignoring null", func(t *testing.T) {
 		for i := 0; i < 20; i++ {
 			val := Generate(map[string]any{"type": []any{"string", "null"}})
@@ -254,3 +289,988 @@ func TestGenerate(t *testing.T) {
 		})
 	})
 }
+
+func TestGenerator(t *testing.T) {
+	t.Run("produces identical stubs for the same seed", func(t *testing.T) {
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":  map[string]any{"type": "string"},
+				"score": map[string]any{"type": "integer"},
+				"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+		}
+		firstStub, _ := NewGenerator(WithSeed(42)).Generate(schema)
+		secondStub, _ := NewGenerator(WithSeed(42)).Generate(schema)
+		first, _ := json.Marshal(firstStub)
+		second, _ := json.Marshal(secondStub)
+		if string(first) != string(second) {
+			t.Errorf("expected identical output, got %s and %s", first, second)
+		}
+	})
+}
+
+func TestGenerateNot(t *testing.T) {
+	t.Run("never produces a string from an excluded enum", func(t *testing.T) {
+		excluded := []any{}
+		words := wordLists["en"]
+		for _, a := range words[:20] {
+			for _, b := range words {
+				excluded = append(excluded, a+"-"+b)
+			}
+		}
+		schema := map[string]any{"type": "string", "not": map[string]any{"enum": excluded}}
+		g := NewGenerator(WithSeed(1))
+		for i := 0; i < 50; i++ {
+			v, _ := g.Generate(schema)
+			val := v.(string)
+			for _, e := range excluded {
+				if val == e {
+					t.Fatalf("generated excluded value %q", val)
+				}
+			}
+		}
+	})
+
+	t.Run("falls back to a suffixed string when every candidate is excluded", func(t *testing.T) {
+		schema := map[string]any{
+			"type":   "string",
+			"format": "date",
+			"not":    map[string]any{"enum": []any{"2024-01-01"}},
+		}
+		if got := Generate(schema); got == "2024-01-01" {
+			t.Errorf("expected a value other than the excluded date, got %v", got)
+		}
+	})
+
+	t.Run("picks only enum values that are not excluded", func(t *testing.T) {
+		schema := map[string]any{
+			"enum": []any{"a", "b", "c"},
+			"not":  map[string]any{"enum": []any{"a", "c"}},
+		}
+		for i := 0; i < 20; i++ {
+			if got := Generate(schema); got != "b" {
+				t.Fatalf("got %v, want b", got)
+			}
+		}
+	})
+
+	t.Run("warns when not excludes the declared type", func(t *testing.T) {
+		g := NewGenerator()
+		val, _ := g.Generate(map[string]any{"type": "string", "not": map[string]any{"type": "string"}})
+		if _, ok := val.(string); !ok {
+			t.Errorf("expected string, got %T", val)
+		}
+		if len(g.Warnings()) != 1 {
+			t.Errorf("expected 1 warning, got %v", g.Warnings())
+		}
+	})
+}
+
+// pokemonStatsSchema mirrors the schema BuildSchema produces for
+// testdata/pokemon_stats.graphql in the graphqlschema package.
+func pokemonStatsSchema() map[string]any {
+	leaf := func(t string) map[string]any { return map[string]any{"type": t} }
+	object := func(props map[string]any) map[string]any {
+		return map[string]any{"type": "object", "properties": props}
+	}
+	list := func(items map[string]any) map[string]any {
+		return map[string]any{"type": "array", "items": items}
+	}
+	return object(map[string]any{
+		"data": object(map[string]any{
+			"pokemon_v2_pokemon": object(map[string]any{
+				"name":            leaf("string"),
+				"base_experience": leaf("integer"),
+				"height":          leaf("integer"),
+				"weight":          leaf("integer"),
+				"pokemon_v2_pokemonstats": list(object(map[string]any{
+					"base_stat":       leaf("integer"),
+					"effort":          leaf("integer"),
+					"pokemon_v2_stat": object(map[string]any{"name": leaf("string")}),
+				})),
+				"pokemon_v2_pokemontypes": list(object(map[string]any{
+					"pokemon_v2_type": object(map[string]any{"name": leaf("string")}),
+				})),
+				"pokemon_v2_pokemonabilities": list(object(map[string]any{
+					"pokemon_v2_ability": object(map[string]any{"name": leaf("string")}),
+					"is_hidden":          leaf("boolean"),
+				})),
+			}),
+		}),
+	})
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	schema := pokemonStatsSchema()
+	g := NewGenerator(WithSeed(1))
+	b.ReportAllocs()
+	for b.Loop() {
+		g.Generate(schema)
+	}
+}
+
+func BenchmarkGenerateWideObject(b *testing.B) {
+	properties := map[string]any{}
+	for i := range 500 {
+		properties[fmt.Sprintf("field_%d", i)] = map[string]any{"type": "string"}
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	g := NewGenerator(WithSeed(1))
+	b.ReportAllocs()
+	for b.Loop() {
+		g.Generate(schema)
+	}
+}
+
+func TestDetectDraft(t *testing.T) {
+	for uri, want := range map[string]string{
+		"https://json-schema.org/draft/2020-12/schema": "2020-12",
+		"http://json-schema.org/draft-07/schema#":      "draft-07",
+		"": "draft-07",
+	} {
+		if got := detectDraft(map[string]any{"$schema": uri}); got != want {
+			t.Errorf("%q: got %q, want %q", uri, got, want)
+		}
+	}
+}
+
+func TestGeneratePrefixItems(t *testing.T) {
+	tuple := []any{
+		map[string]any{"type": "string"},
+		map[string]any{"type": "integer"},
+		map[string]any{"type": "boolean"},
+	}
+
+	t.Run("generates one value per prefixItems schema in draft 2020-12", func(t *testing.T) {
+		val := Generate(map[string]any{
+			"$schema":     "https://json-schema.org/draft/2020-12/schema",
+			"type":        "array",
+			"prefixItems": tuple,
+		}).([]any)
+		if len(val) != 3 {
+			t.Fatalf("expected 3 items, got %v", val)
+		}
+		if _, ok := val[0].(string); !ok {
+			t.Errorf("item 0: expected string, got %T", val[0])
+		}
+		if _, ok := val[1].(int); !ok {
+			t.Errorf("item 1: expected int, got %T", val[1])
+		}
+		if _, ok := val[2].(bool); !ok {
+			t.Errorf("item 2: expected bool, got %T", val[2])
+		}
+	})
+
+	t.Run("fills positions after the prefix from items up to maxItems", func(t *testing.T) {
+		val := Generate(map[string]any{
+			"$schema":     "https://json-schema.org/draft/2020-12/schema",
+			"type":        "array",
+			"prefixItems": tuple,
+			"items":       map[string]any{"type": "number"},
+			"maxItems":    float64(5),
+		}).([]any)
+		if len(val) != 5 {
+			t.Fatalf("expected 5 items, got %v", val)
+		}
+		for _, item := range val[3:] {
+			if _, ok := item.(float64); !ok {
+				t.Errorf("expected float64 item, got %T", item)
+			}
+		}
+	})
+
+	t.Run("generates nested tuples of a draft-07 time series", func(t *testing.T) {
+		val := Generate(map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "array",
+				"prefixItems": []any{
+					map[string]any{"type": "string", "format": "date-time"},
+					map[string]any{"type": "number"},
+				},
+			},
+		}).([]any)
+		if len(val) == 0 {
+			t.Fatal("expected at least one point")
+		}
+		for _, item := range val {
+			point, ok := item.([]any)
+			if !ok || len(point) != 2 {
+				t.Fatalf("expected [timestamp, value] pairs, got %v", item)
+			}
+			if s, ok := point[0].(string); !ok {
+				t.Errorf("timestamp: expected a string, got %T", point[0])
+			} else if _, err := time.Parse(time.RFC3339, s); err != nil {
+				t.Errorf("timestamp: expected a date-time, got %q", s)
+			}
+			if _, ok := point[1].(float64); !ok {
+				t.Errorf("value: expected float64, got %T", point[1])
+			}
+		}
+	})
+}
+
+func TestGenerateHeterogeneousArray(t *testing.T) {
+	tuple := []any{
+		map[string]any{"type": "string"},
+		map[string]any{"type": "integer"},
+		map[string]any{"type": "boolean"},
+	}
+
+	t.Run("generates each position from its own items schema", func(t *testing.T) {
+		val := Generate(map[string]any{"type": "array", "items": tuple}).([]any)
+		if len(val) != 3 {
+			t.Fatalf("expected 3 items, got %v", val)
+		}
+		if _, ok := val[0].(string); !ok {
+			t.Errorf("item 0: expected string, got %T", val[0])
+		}
+		if _, ok := val[1].(int); !ok {
+			t.Errorf("item 1: expected int, got %T", val[1])
+		}
+		if _, ok := val[2].(bool); !ok {
+			t.Errorf("item 2: expected bool, got %T", val[2])
+		}
+	})
+
+	t.Run("fills extra positions from additionalItems", func(t *testing.T) {
+		val := NewGenerator().generateHeterogeneousArray(tuple, map[string]any{"type": "number"}, 5)
+		if len(val) != 5 {
+			t.Fatalf("expected 5 items, got %v", val)
+		}
+		for _, item := range val[3:] {
+			if _, ok := item.(float64); !ok {
+				t.Errorf("expected float64 item, got %T", item)
+			}
+		}
+	})
+
+	t.Run("stops at the tuple length without additionalItems", func(t *testing.T) {
+		if val := NewGenerator().generateHeterogeneousArray(tuple, nil, 5); len(val) != 3 {
+			t.Errorf("expected 3 items, got %v", val)
+		}
+	})
+}
+
+func TestGenerateKeywords(t *testing.T) {
+	schema := map[string]any{
+		"$schema":       "https://json-schema.org/draft/2020-12/schema",
+		"$vocabulary":   map[string]any{"https://example.com/vocab/future": true},
+		"type":          "string",
+		"futureKeyword": true,
+		"x-stub-note":   "extensions are always accepted",
+	}
+
+	t.Run("warns about unknown keywords and proceeds by default", func(t *testing.T) {
+		g := NewGenerator()
+		val, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := val.(string); !ok {
+			t.Errorf("expected string, got %T", val)
+		}
+		if len(g.Warnings()) != 1 || !strings.Contains(g.Warnings()[0], "futureKeyword") {
+			t.Errorf("expected a warning naming futureKeyword, got %v", g.Warnings())
+		}
+	})
+
+	t.Run("returns an error for unknown keywords with WithStrictKeywords", func(t *testing.T) {
+		_, err := NewGenerator(WithStrictKeywords()).Generate(schema)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "futureKeyword") || strings.Contains(err.Error(), "x-stub-note") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+	})
+
+	t.Run("accepts every draft-07 keyword", func(t *testing.T) {
+		schema := map[string]any{"type": "string"}
+		for _, key := range []string{
+			"readOnly", "writeOnly", "contentMediaType", "contentEncoding",
+			"allOf", "anyOf", "oneOf", "if", "then", "else",
+			"exclusiveMinimum", "exclusiveMaximum", "multipleOf", "minLength", "maxLength", "pattern",
+			"contains", "uniqueItems", "patternProperties", "minProperties", "maxProperties", "dependencies", "propertyNames",
+		} {
+			schema[key] = nil
+		}
+		if _, err := NewGenerator(WithStrictKeywords()).Generate(schema); err != nil {
+			t.Errorf("expected no unknown keywords, got %v", err)
+		}
+	})
+}
+
+func TestGenerateNullableTypes(t *testing.T) {
+	schema := map[string]any{
+		"type":     "array",
+		"items":    map[string]any{"type": []any{"integer", "null"}},
+		"minItems": float64(1000),
+		"maxItems": float64(1000),
+	}
+
+	t.Run("produces nil for about 30% of nullable items", func(t *testing.T) {
+		val, _ := NewGenerator(WithSeed(7), WithNullableTypes()).Generate(schema)
+		nulls := 0
+		for _, item := range val.([]any) {
+			switch item.(type) {
+			case nil:
+				nulls++
+			case int:
+			default:
+				t.Fatalf("expected int or nil, got %T", item)
+			}
+		}
+		if nulls < 230 || nulls > 370 {
+			t.Errorf("expected roughly 300 nulls out of 1000, got %d", nulls)
+		}
+	})
+
+	t.Run("never produces nil without the option", func(t *testing.T) {
+		val, _ := NewGenerator(WithSeed(7)).Generate(schema)
+		for _, item := range val.([]any) {
+			if item == nil {
+				t.Fatal("expected no nil items")
+			}
+		}
+	})
+}
+
+func TestGenerateFirstEnumValue(t *testing.T) {
+	enums := func(t *testing.T, enum []any, opts ...GenOption) map[any]bool {
+		t.Helper()
+		schema := map[string]any{
+			"type":     "array",
+			"items":    map[string]any{"enum": enum},
+			"minItems": float64(50),
+			"maxItems": float64(50),
+		}
+		val, err := NewGenerator(append(opts, WithSeed(1))...).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := map[any]bool{}
+		for _, item := range val.([]any) {
+			seen[item] = true
+		}
+		return seen
+	}
+
+	t.Run("always picks the first string value", func(t *testing.T) {
+		seen := enums(t, []any{"A", "B", "C"}, WithFirstEnumValue())
+		if len(seen) != 1 || !seen["A"] {
+			t.Errorf("expected only \"A\", got %v", seen)
+		}
+	})
+
+	t.Run("always picks the first numeric value", func(t *testing.T) {
+		seen := enums(t, []any{float64(3), float64(1), float64(2)}, WithFirstEnumValue())
+		if len(seen) != 1 || !seen[float64(3)] {
+			t.Errorf("expected only 3, got %v", seen)
+		}
+	})
+
+	t.Run("picks every value by default", func(t *testing.T) {
+		if seen := enums(t, []any{"A", "B", "C"}); len(seen) != 3 {
+			t.Errorf("expected all three values, got %v", seen)
+		}
+	})
+}
+
+func TestGenerateNullProb(t *testing.T) {
+	nulls := func(t *testing.T, p float64) int {
+		t.Helper()
+		schema := map[string]any{
+			"type":     "array",
+			"items":    map[string]any{"type": "string", "x-stub-null-prob": p},
+			"minItems": float64(1000),
+			"maxItems": float64(1000),
+		}
+		val, err := NewGenerator(WithSeed(3)).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, item := range val.([]any) {
+			if item == nil {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Run("produces nil at the field's null probability", func(t *testing.T) {
+		if n := nulls(t, 0.8); n < 750 || n > 850 {
+			t.Errorf("expected roughly 800 nulls out of 1000, got %d", n)
+		}
+	})
+
+	t.Run("never produces nil at probability 0", func(t *testing.T) {
+		if n := nulls(t, 0); n != 0 {
+			t.Errorf("expected no nulls, got %d", n)
+		}
+	})
+}
+
+func TestGenerateDateRange(t *testing.T) {
+	from := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 6, 30, 23, 59, 59, 0, time.UTC)
+
+	t.Run("generates dates within the range", func(t *testing.T) {
+		g := NewGenerator(WithSeed(1), WithDateRange(from, to))
+		for i := 0; i < 100; i++ {
+			v, _ := g.Generate(map[string]any{"type": "string", "format": "date"})
+			d, err := time.Parse(time.DateOnly, v.(string))
+			if err != nil {
+				t.Fatalf("not a date: %v", v)
+			}
+			if d.Before(from.Truncate(24*time.Hour)) || d.After(to) {
+				t.Errorf("date %v out of range", d)
+			}
+		}
+	})
+
+	t.Run("generates RFC 3339 date-times within the range", func(t *testing.T) {
+		g := NewGenerator(WithSeed(1), WithDateRange(from, to))
+		seen := map[string]bool{}
+		for i := 0; i < 100; i++ {
+			v, _ := g.Generate(map[string]any{"type": "string", "format": "date-time"})
+			d, err := time.Parse(time.RFC3339, v.(string))
+			if err != nil {
+				t.Fatalf("not an RFC 3339 date-time: %v", v)
+			}
+			if d.Before(from) || d.After(to) {
+				t.Errorf("date-time %v out of range", d)
+			}
+			seen[v.(string)] = true
+		}
+		if len(seen) < 50 {
+			t.Errorf("expected varied date-times, got %d distinct values", len(seen))
+		}
+	})
+
+	t.Run("returns an error for a reversed or half-open range", func(t *testing.T) {
+		schema := map[string]any{"type": "string", "format": "date"}
+		for name, opt := range map[string]GenOption{
+			"reversed": WithDateRange(to, from),
+			"no start": WithDateRange(time.Time{}, to),
+			"no end":   WithDateRange(from, time.Time{}),
+		} {
+			if _, err := NewGenerator(opt).Generate(schema); err == nil || !strings.Contains(err.Error(), "date range") {
+				t.Errorf("%s: expected a date range error, got %v", name, err)
+			}
+		}
+	})
+}
+
+func TestGenerateLocale(t *testing.T) {
+	schema := map[string]any{"type": "string"}
+
+	t.Run("builds strings from the French word list", func(t *testing.T) {
+		g := NewGenerator(WithSeed(1), WithLocale("fr"))
+		for i := 0; i < 100; i++ {
+			v, err := g.Generate(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, part := range strings.Split(v.(string), "-") {
+				if !slices.Contains(wordLists["fr"], part) {
+					t.Errorf("%q is not a French word", part)
+				}
+				for _, en := range wordLists["en"] {
+					if strings.Contains(part, en) {
+						t.Errorf("%q contains English word %q", part, en)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("produces different strings from English with the same seed", func(t *testing.T) {
+		en, _ := NewGenerator(WithSeed(1), WithLocale("en")).Generate(schema)
+		fr, _ := NewGenerator(WithSeed(1), WithLocale("fr")).Generate(schema)
+		if en == fr {
+			t.Errorf("expected different strings, both were %v", en)
+		}
+	})
+
+	t.Run("falls back from a regional variant to its language", func(t *testing.T) {
+		if _, err := NewGenerator(WithLocale("fr-CA")).Generate(schema); err != nil {
+			t.Errorf("expected fr-CA to be supported, got %v", err)
+		}
+	})
+
+	t.Run("returns an error for an unsupported locale", func(t *testing.T) {
+		if _, err := NewGenerator(WithLocale("tlh")).Generate(schema); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestGenerateDepth(t *testing.T) {
+	t.Run("stops recursing beyond WithMaxStubDepth", func(t *testing.T) {
+		v, err := NewGenerator(WithSeed(1), WithMaxStubDepth(2)).Generate(pokemonStatsSchema())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pokemon := v.(map[string]any)["data"].(map[string]any)["pokemon_v2_pokemon"].(map[string]any)
+		if _, ok := pokemon["name"].(string); !ok {
+			t.Errorf("name: expected string at depth 2, got %T", pokemon["name"])
+		}
+		stats, ok := pokemon["pokemon_v2_pokemonstats"].([]any)
+		if !ok {
+			t.Fatalf("pokemon_v2_pokemonstats: expected array, got %T", pokemon["pokemon_v2_pokemonstats"])
+		}
+		if len(stats) != 0 {
+			t.Errorf("expected empty pokemon_v2_pokemonstats beyond max depth, got %v", stats)
+		}
+	})
+
+	t.Run("generates nested content without a depth limit", func(t *testing.T) {
+		v, _ := NewGenerator(WithSeed(1)).Generate(pokemonStatsSchema())
+		pokemon := v.(map[string]any)["data"].(map[string]any)["pokemon_v2_pokemon"].(map[string]any)
+		if len(pokemon["pokemon_v2_pokemonstats"].([]any)) == 0 {
+			t.Error("expected pokemon_v2_pokemonstats items")
+		}
+	})
+
+	t.Run("WithMinStubDepth fills arrays and nullable values near the root", func(t *testing.T) {
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"moves": map[string]any{
+					"type":     "array",
+					"minItems": float64(0),
+					"maxItems": float64(0),
+					"items":    map[string]any{"type": []any{"object", "null"}, "properties": map[string]any{}},
+				},
+			},
+		}
+		g := NewGenerator(WithSeed(1), WithNullableTypes(), WithMinStubDepth(3))
+		for i := 0; i < 20; i++ {
+			v, _ := g.Generate(schema)
+			moves := v.(map[string]any)["moves"].([]any)
+			if len(moves) != 1 || moves[0] == nil {
+				t.Fatalf("expected one non-null move, got %v", moves)
+			}
+		}
+	})
+}
+
+func TestGenerateRef(t *testing.T) {
+	stat := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"base_stat": map[string]any{"type": "integer"}},
+	}
+
+	t.Run("resolves shared definitions from several properties", func(t *testing.T) {
+		schema := map[string]any{
+			"type":        "object",
+			"definitions": map[string]any{"Stat": stat},
+			"$defs":       map[string]any{"Name": map[string]any{"type": "string"}},
+			"properties": map[string]any{
+				"attack":  map[string]any{"$ref": "#/definitions/Stat"},
+				"defense": map[string]any{"$ref": "#/definitions/Stat"},
+				"name":    map[string]any{"$ref": "#/$defs/Name"},
+			},
+		}
+		val, err := NewGenerator(WithSeed(1)).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := val.(map[string]any)
+		for _, key := range []string{"attack", "defense"} {
+			if _, ok := obj[key].(map[string]any)["base_stat"].(int); !ok {
+				t.Errorf("%s: expected a Stat object, got %v", key, obj[key])
+			}
+		}
+		if _, ok := obj["name"].(string); !ok {
+			t.Errorf("name: expected string, got %T", obj["name"])
+		}
+	})
+
+	t.Run("rejects external and dangling references", func(t *testing.T) {
+		for _, ref := range []string{"https://example.com/pokemon.json", "pokemon.json#/Stat", "#/definitions/Missing"} {
+			schema := map[string]any{"type": "object", "properties": map[string]any{"stat": map[string]any{"$ref": ref}}}
+			_, err := NewGenerator().Generate(schema)
+			if err == nil || !strings.Contains(err.Error(), ref) {
+				t.Errorf("%s: expected an error naming the ref, got %v", ref, err)
+			}
+		}
+	})
+
+	t.Run("fails on recursive references", func(t *testing.T) {
+		schema := map[string]any{
+			"definitions": map[string]any{"Node": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"next": map[string]any{"$ref": "#/definitions/Node"}},
+			}},
+			"$ref": "#/definitions/Node",
+		}
+		if _, err := NewGenerator().Generate(schema); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestResolveRef(t *testing.T) {
+	target := map[string]any{"type": "string"}
+	root := map[string]any{
+		"definitions": map[string]any{"a/b": target},
+		"prefixItems": []any{map[string]any{}, target},
+	}
+	for _, ref := range []string{"#/definitions/a~1b", "#/prefixItems/1"} {
+		got, err := resolveRef(ref, root)
+		if err != nil {
+			t.Fatalf("%s: %v", ref, err)
+		}
+		if got["type"] != "string" {
+			t.Errorf("%s: expected the target schema, got %v", ref, got)
+		}
+	}
+	if got, err := resolveRef("#", root); err != nil || got["definitions"] == nil {
+		t.Errorf("#: expected the root, got %v, %v", got, err)
+	}
+}
+
+func TestGenerateNumericStringFormats(t *testing.T) {
+	cases := []struct {
+		format string
+		opts   []GenOption
+		re     *regexp.Regexp
+	}{
+		{"currency", nil, regexp.MustCompile(`^\$\d+\.\d{2}$`)},
+		{"currency", []GenOption{WithCurrencySymbol("€")}, regexp.MustCompile(`^€\d+\.\d{2}$`)},
+		{"percentage", nil, regexp.MustCompile(`^\d+(\.\d+)?%$`)},
+		{"formatted-number", nil, regexp.MustCompile(`^\d{1,3}(,\d{3})+$`)},
+		{"formatted-number", []GenOption{WithLocale("fr")}, regexp.MustCompile(`^\d{1,3}( \d{3})+$`)},
+	}
+	for _, tc := range cases {
+		g := NewGenerator(append([]GenOption{WithSeed(1)}, tc.opts...)...)
+		for i := 0; i < 50; i++ {
+			val, err := g.Generate(map[string]any{"type": "string", "format": tc.format})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tc.re.MatchString(val.(string)) {
+				t.Errorf("%s: %q does not match %s", tc.format, val, tc.re)
+			}
+		}
+	}
+}
+
+func TestGenerateCursor(t *testing.T) {
+	g := NewGenerator(WithSeed(1))
+	for range 20 {
+		val, err := g.Generate(map[string]any{"type": "string", "format": "cursor"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(val.(string))
+		if err != nil {
+			t.Fatalf("%q is not base64: %v", val, err)
+		}
+		if prefix, offset, ok := strings.Cut(string(decoded), ":"); !ok || prefix != "offset" || offset == "" {
+			t.Errorf("expected offset:<n>, got %q", decoded)
+		}
+	}
+}
+
+func TestGenerateNameAware(t *testing.T) {
+	cases := map[string]*regexp.Regexp{
+		"created_at":  regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`),
+		"start_time":  regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`),
+		"sprite_url":  regexp.MustCompile(`^https://`),
+		"profile_uri": regexp.MustCompile(`^https://`),
+		"email":       regexp.MustCompile(`^[^@]+@example\.com$`),
+		"end_cursor":  regexp.MustCompile(`^[A-Za-z0-9+/]+=*$`),
+		"after":       regexp.MustCompile(`^[A-Za-z0-9+/]+=*$`),
+		"name":        regexp.MustCompile(`^\w+-\w+$`),
+	}
+	for name, re := range cases {
+		schema := map[string]any{"type": "string", "x-field-name": name}
+		val, err := NewGenerator(WithSeed(1), WithNameAwareGeneration()).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !re.MatchString(val.(string)) {
+			t.Errorf("%s: %q does not match %s", name, val, re)
+		}
+	}
+
+	t.Run("ignores field names without the option", func(t *testing.T) {
+		val, _ := NewGenerator(WithSeed(1)).Generate(map[string]any{"type": "string", "x-field-name": "email"})
+		if strings.Contains(val.(string), "@") {
+			t.Errorf("expected a plain word pair, got %q", val)
+		}
+	})
+
+	t.Run("prefers an explicit format", func(t *testing.T) {
+		val, _ := NewGenerator(WithSeed(1), WithNameAwareGeneration()).Generate(map[string]any{"type": "string", "format": "date", "x-field-name": "created_at"})
+		if val != "2024-01-01" {
+			t.Errorf("expected the date format, got %q", val)
+		}
+	})
+}
+
+func TestGenerateStats(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pokemon": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+					"stats": map[string]any{
+						"type":     "array",
+						"minItems": float64(2),
+						"maxItems": float64(2),
+						"items": map[string]any{
+							"type":       "object",
+							"properties": map[string]any{"base_stat": map[string]any{"type": "integer"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	g := NewGenerator(WithSeed(1), WithGenerationStats())
+	if _, err := g.Generate(schema); err != nil {
+		t.Fatal(err)
+	}
+	stats := g.Stats()
+	want := map[string]GenerationStat{
+		"pokemon":                       {Count: 1, MaxDepth: 3},
+		"pokemon.name":                  {Count: 1},
+		"pokemon.stats":                 {Count: 1, MaxDepth: 2},
+		"pokemon.stats.items":           {Count: 2, MaxDepth: 1},
+		"pokemon.stats.items.base_stat": {Count: 2},
+	}
+	if len(stats) != len(want) {
+		t.Errorf("expected stats for %d paths, got %v", len(want), stats)
+	}
+	for path, w := range want {
+		got, ok := stats[path]
+		if !ok {
+			t.Errorf("%s: no stats", path)
+			continue
+		}
+		if got.Count != w.Count || got.MaxDepth != w.MaxDepth {
+			t.Errorf("%s: got count %d depth %d, want count %d depth %d", path, got.Count, got.MaxDepth, w.Count, w.MaxDepth)
+		}
+		if got.TotalNs < 0 {
+			t.Errorf("%s: negative time %d", path, got.TotalNs)
+		}
+	}
+
+	t.Run("returns nil without the option", func(t *testing.T) {
+		g := NewGenerator(WithSeed(1))
+		if _, err := g.Generate(schema); err != nil {
+			t.Fatal(err)
+		}
+		if stats := g.Stats(); stats != nil {
+			t.Errorf("expected nil, got %v", stats)
+		}
+	})
+}
+
+func TestGenerateAdditionalProperties(t *testing.T) {
+	schema := map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}}
+
+	t.Run("generates 2 to 5 entries of the additionalProperties type", func(t *testing.T) {
+		g := NewGenerator(WithSeed(1))
+		for range 20 {
+			val, err := g.Generate(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bag := val.(map[string]any)
+			if len(bag) < 2 || len(bag) > 5 {
+				t.Errorf("expected 2 to 5 entries, got %v", bag)
+			}
+			for key, v := range bag {
+				if !regexp.MustCompile(`^\w+-\w+`).MatchString(key) {
+					t.Errorf("expected a word-pair key, got %q", key)
+				}
+				if _, ok := v.(int); !ok {
+					t.Errorf("%s: expected an integer, got %T", key, v)
+				}
+			}
+		}
+	})
+
+	t.Run("WithAdditionalPropertiesCount sets the number of entries", func(t *testing.T) {
+		val, err := NewGenerator(WithSeed(1), WithAdditionalPropertiesCount(7, 7)).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(val.(map[string]any)); n != 7 {
+			t.Errorf("expected 7 entries, got %d", n)
+		}
+		if _, err := NewGenerator(WithAdditionalPropertiesCount(3, 1)).Generate(schema); err == nil {
+			t.Error("expected an error for a minimum above the maximum")
+		}
+	})
+
+	t.Run("ignores additionalProperties next to properties", func(t *testing.T) {
+		val, err := NewGenerator(WithSeed(1)).Generate(map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{"name": map[string]any{"type": "string"}},
+			"additionalProperties": map[string]any{"type": "integer"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(val.(map[string]any)) != 1 {
+			t.Errorf("expected only the declared property, got %v", val)
+		}
+	})
+}
+
+func TestGenerateSameAs(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"trainer": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					// Sorted first, so it is generated after pokemon_count.
+					"badges":        map[string]any{"type": "integer", "x-stub-same-as": "trainer.pokemon_count"},
+					"pokemon_count": map[string]any{"type": "integer"},
+					"pokemons": map[string]any{
+						"type":  "array",
+						"items": map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}},
+					},
+				},
+			},
+			"favourite": map[string]any{"type": "string", "x-stub-same-as": "trainer.pokemons.items.name"},
+		},
+	}
+
+	g := NewGenerator(WithSeed(1))
+	for range 20 {
+		val, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stub := val.(map[string]any)
+		trainer := stub["trainer"].(map[string]any)
+		if trainer["badges"] != trainer["pokemon_count"] {
+			t.Errorf("badges %v differs from pokemon_count %v", trainer["badges"], trainer["pokemon_count"])
+		}
+		pokemons := trainer["pokemons"].([]any)
+		last := pokemons[len(pokemons)-1].(map[string]any)["name"]
+		if stub["favourite"] != last {
+			t.Errorf("favourite %v differs from the last pokemon name %v", stub["favourite"], last)
+		}
+	}
+	if len(g.Warnings()) != 0 {
+		t.Errorf("unexpected warnings %v", g.Warnings())
+	}
+
+	t.Run("warns and generates a value for unknown paths", func(t *testing.T) {
+		g := NewGenerator(WithSeed(1))
+		val, err := g.Generate(map[string]any{"type": "integer", "x-stub-same-as": "missing"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := val.(int); !ok {
+			t.Errorf("expected an integer, got %v", val)
+		}
+		if len(g.Warnings()) != 1 {
+			t.Errorf("expected one warning, got %v", g.Warnings())
+		}
+	})
+}
+
+func TestGenerateMaxArrayItems(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"pokemons": map[string]any{
+				"type":     "array",
+				"maxItems": 10.0,
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"moves": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+	}
+	g := NewGenerator(WithMaxArrayItems(1))
+	for range 20 {
+		stub, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pokemons := stub.(map[string]any)["pokemons"].([]any)
+		if len(pokemons) != 1 {
+			t.Fatalf("expected 1 pokemon, got %d", len(pokemons))
+		}
+		if moves := pokemons[0].(map[string]any)["moves"].([]any); len(moves) != 1 {
+			t.Errorf("expected 1 move, got %d", len(moves))
+		}
+	}
+
+	t.Run("keeps a lower maxItems", func(t *testing.T) {
+		g := NewGenerator(WithMaxArrayItems(5))
+		for range 20 {
+			stub, err := g.Generate(map[string]any{"type": "array", "maxItems": 2.0, "items": map[string]any{"type": "string"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(stub.([]any)); n > 2 {
+				t.Fatalf("expected at most 2 items, got %d", n)
+			}
+		}
+	})
+
+	t.Run("fails when minItems exceeds the cap", func(t *testing.T) {
+		_, err := NewGenerator(WithMaxArrayItems(1)).Generate(map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"moves": map[string]any{"type": "array", "minItems": 2.0}},
+		})
+		if err == nil || !strings.Contains(err.Error(), `array at "moves": minItems 2`) {
+			t.Errorf("expected a minItems error, got %v", err)
+		}
+	})
+}
+
+func TestGeneratePreferExamples(t *testing.T) {
+	schema := map[string]any{"type": "string", "examples": []any{"Pikachu", "Charizard"}}
+
+	g := NewGenerator(WithPreferExamples())
+	for range 20 {
+		v, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "Pikachu" && v != "Charizard" {
+			t.Fatalf("expected one of the examples, got %v", v)
+		}
+	}
+
+	t.Run("ignores examples by default", func(t *testing.T) {
+		v, err := NewGenerator(WithSeed(1)).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := v.(string); s == "Pikachu" || s == "Charizard" || len(strings.Split(s, "-")) != 2 {
+			t.Errorf("expected a generated word pair, got %q", s)
+		}
+	})
+
+	t.Run("generates when examples are empty", func(t *testing.T) {
+		v, err := NewGenerator(WithPreferExamples()).Generate(map[string]any{"type": "integer", "examples": []any{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := v.(int); !ok {
+			t.Errorf("expected a generated integer, got %#v", v)
+		}
+	})
+}
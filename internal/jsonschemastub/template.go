@@ -0,0 +1,109 @@
+package jsonschemastub
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// templateKeyword holds a text/template rendered, with the object's other
+// properties as data, to produce a string property from its siblings.
+const templateKeyword = "x-stub-template"
+
+// templateFieldRE finds the fields a template refers to, such as ".first_name".
+var templateFieldRE = regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// templateDeps returns the sibling properties an "x-stub-template" property
+// refers to.
+func templateDeps(schema any, properties map[string]any) []string {
+	ps, ok := schema.(map[string]any)
+	if !ok {
+		return nil
+	}
+	text, ok := ps[templateKeyword].(string)
+	if !ok {
+		return nil
+	}
+	var deps []string
+	for _, m := range templateFieldRE.FindAllStringSubmatch(text, -1) {
+		if _, ok := properties[m[1]]; ok {
+			deps = append(deps, m[1])
+		}
+	}
+	return deps
+}
+
+// dependencyOrder reorders keys so every "x-stub-template" property comes
+// after the siblings it refers to, keeping the given order otherwise. Keys in
+// a cycle are generated in the given order with a warning, and their
+// templates see only the siblings generated before them. Objects without
+// templates, the common case, are returned as they are after one scan.
+func (g *Generator) dependencyOrder(keys []string, properties map[string]any) []string {
+	deps := map[string][]string{}
+	for _, key := range keys {
+		if d := templateDeps(properties[key], properties); len(d) > 0 {
+			deps[key] = d
+		}
+	}
+	if len(deps) == 0 {
+		return keys
+	}
+	done := make(map[string]bool, len(keys))
+	ordered := make([]string, 0, len(keys))
+	for len(ordered) < len(keys) {
+		next := ""
+		for _, key := range keys {
+			if done[key] {
+				continue
+			}
+			ready := true
+			for _, dep := range deps[key] {
+				ready = ready && (done[dep] || dep == key)
+			}
+			if ready {
+				next = key
+				break
+			}
+		}
+		if next == "" {
+			for _, key := range keys {
+				if !done[key] {
+					next = key
+					break
+				}
+			}
+			g.warnf("%s of %q refers to its own dependents; generating it first", templateKeyword, next)
+		}
+		done[next] = true
+		ordered = append(ordered, next)
+	}
+	return ordered
+}
+
+// renderTemplate renders text with the properties generated so far in the
+// enclosing object. It reports false, with a warning, when the template is
+// invalid or refers to a property that has no value.
+func (g *Generator) renderTemplate(text string) (string, bool) {
+	tmpl, ok := g.templates[text]
+	if !ok {
+		var err error
+		tmpl, err = template.New(templateKeyword).Option("missingkey=error").Parse(text)
+		if err != nil {
+			g.warnf("%s %q: %v", templateKeyword, text, err)
+		}
+		g.templates[text] = tmpl
+	}
+	if tmpl == nil {
+		return "", false
+	}
+	var data map[string]any
+	if n := len(g.objects); n > 0 {
+		data = g.objects[n-1]
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		g.warnf("%s %q: %v", templateKeyword, text, err)
+		return "", false
+	}
+	return sb.String(), true
+}
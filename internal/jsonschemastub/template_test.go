@@ -0,0 +1,87 @@
+package jsonschemastub
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStubTemplate(t *testing.T) {
+	t.Run("renders siblings generated before it", func(t *testing.T) {
+		// "a_full_name" sorts first, so it must be moved after its dependencies.
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"a_full_name": map[string]any{"type": "string", "x-stub-template": "{{.first_name}} {{.last_name}}"},
+				"first_name":  map[string]any{"type": "string"},
+				"last_name":   map[string]any{"type": "string"},
+			},
+		}
+		for seed := range int64(10) {
+			stub, err := NewGenerator(WithSeed(seed)).Generate(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := stub.(map[string]any)
+			if want := got["first_name"].(string) + " " + got["last_name"].(string); got["a_full_name"] != want {
+				t.Errorf("expected full name %q, got %q", want, got["a_full_name"])
+			}
+		}
+	})
+
+	t.Run("orders chains of templates", func(t *testing.T) {
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"a": map[string]any{"type": "string", "x-stub-template": "<{{.b}}>"},
+				"b": map[string]any{"type": "string", "x-stub-template": "{{.c}}!"},
+				"c": map[string]any{"type": "string", "x-stub-values": []any{"hi"}},
+			},
+		}
+		stub, err := NewGenerator().Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := stub.(map[string]any)["a"]; got != "<hi!>" {
+			t.Errorf("expected <hi!>, got %v", got)
+		}
+	})
+
+	t.Run("falls back to a generated string with a warning", func(t *testing.T) {
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"broken":  map[string]any{"type": "string", "x-stub-template": "{{.first_name"},
+				"missing": map[string]any{"type": "string", "x-stub-template": "{{.nickname}}"},
+			},
+		}
+		g := NewGenerator()
+		stub, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := stub.(map[string]any)
+		if got["broken"] == "" || got["missing"] == "" {
+			t.Errorf("expected generated strings, got %v", got)
+		}
+		if warnings := strings.Join(g.Warnings(), "\n"); !strings.Contains(warnings, "{{.first_name") || !strings.Contains(warnings, "nickname") {
+			t.Errorf("expected warnings for both templates, got %q", warnings)
+		}
+	})
+
+	t.Run("warns about cycles", func(t *testing.T) {
+		schema := map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"a": map[string]any{"type": "string", "x-stub-template": "{{.b}}"},
+				"b": map[string]any{"type": "string", "x-stub-template": "{{.a}}"},
+			},
+		}
+		g := NewGenerator()
+		if _, err := g.Generate(schema); err != nil {
+			t.Fatal(err)
+		}
+		if warnings := strings.Join(g.Warnings(), "\n"); !strings.Contains(warnings, "refers to its own dependents") {
+			t.Errorf("expected a cycle warning, got %q", warnings)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package jsonschemastub
+
+import (
+	"regexp"
+	"strings"
+)
+
+// idFieldRE matches the identity fields WithUniqueIds keeps unique, the ID
+// patterns graphqlschema uses to infer integers.
+var idFieldRE = regexp.MustCompile(`(?i)_id$|^id$`)
+
+// WithUniqueIds makes "id" and "*_id" fields differ between the items of each
+// array, so objects generated together do not collide. When a field's range
+// has too few values, duplicates are allowed with a warning.
+func WithUniqueIds() GenOption {
+	return func(g *Generator) {
+		g.uniqueIDs = true
+	}
+}
+
+// enterArray starts a scope of used IDs for an array's items and returns a
+// function ending it.
+func (g *Generator) enterArray() func() {
+	if !g.uniqueIDs {
+		return func() {}
+	}
+	g.usedIDs = append(g.usedIDs, map[string]map[any]bool{})
+	return func() { g.usedIDs = g.usedIDs[:len(g.usedIDs)-1] }
+}
+
+// uniqueID regenerates v, the value of the ID field at path, until it differs
+// from the values the field took in the other items of the enclosing array.
+func (g *Generator) uniqueID(path string, schema map[string]any, v any) any {
+	segment := path[strings.LastIndex(path, ".")+1:]
+	if !g.uniqueIDs || len(g.usedIDs) == 0 || !idFieldRE.MatchString(segment) {
+		return v
+	}
+	scope := g.usedIDs[len(g.usedIDs)-1]
+	used := scope[path]
+	if used == nil {
+		used = map[any]bool{}
+		scope[path] = used
+	}
+	for i := 0; i < maxExclusionAttempts && used[v]; i++ {
+		v = g.generate(schema)
+	}
+	if n, ok := v.(int); ok && used[v] {
+		v = firstUnusedInt(schema, used, n)
+	}
+	if used[v] {
+		g.warnf("%s: ran out of unique values; allowing a duplicate %v", path, v)
+	}
+	used[v] = true
+	return v
+}
+
+// firstUnusedInt returns the smallest integer within schema's bounds that is
+// not in used, or fallback when every one is. Random retries rarely find the
+// last few free values of a small range, so they are searched for directly.
+func firstUnusedInt(schema map[string]any, used map[any]bool, fallback int) int {
+	min, okMin := schema["minimum"].(float64)
+	max, okMax := schema["maximum"].(float64)
+	if !okMin || !okMax {
+		return fallback
+	}
+	for n := int(min); n <= int(max); n++ {
+		if !used[n] {
+			return n
+		}
+	}
+	return fallback
+}
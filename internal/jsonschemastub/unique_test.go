@@ -0,0 +1,58 @@
+package jsonschemastub
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUniqueIds(t *testing.T) {
+	items := func(n int) map[string]any {
+		id := map[string]any{"type": "integer", "minimum": 1.0, "maximum": 100.0}
+		return map[string]any{
+			"type":     "array",
+			"minItems": float64(n),
+			"maxItems": float64(n),
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":         id,
+					"trainer_id": id,
+				},
+			},
+		}
+	}
+
+	g := NewGenerator(WithSeed(1), WithUniqueIds())
+	stub, err := g.Generate(items(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"id", "trainer_id"} {
+		seen := map[any]bool{}
+		for _, item := range stub.([]any) {
+			v := item.(map[string]any)[field]
+			if seen[v] {
+				t.Errorf("%s %v is repeated", field, v)
+			}
+			seen[v] = true
+		}
+	}
+	if len(g.Warnings()) != 0 {
+		t.Errorf("expected no warnings, got %v", g.Warnings())
+	}
+
+	t.Run("warns when the range is exhausted", func(t *testing.T) {
+		g := NewGenerator(WithSeed(1), WithUniqueIds())
+		stub, err := g.Generate(items(101))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(stub.([]any)); n != 101 {
+			t.Fatalf("expected 101 items, got %d", n)
+		}
+		warnings := strings.Join(g.Warnings(), "\n")
+		if !strings.Contains(warnings, "ran out of unique values") {
+			t.Errorf("expected an exhaustion warning, got %q", warnings)
+		}
+	})
+}
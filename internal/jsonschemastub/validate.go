@@ -0,0 +1,144 @@
+package jsonschemastub
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// draft07MetaSchema compiles the draft-07 meta-schema bundled with the
+// validator once, on first use.
+var draft07MetaSchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	return jsonschema.NewCompiler().Compile("http://json-schema.org/draft-07/schema#")
+})
+
+// ValidateSchema checks a JSON Schema against the draft-07 meta-schema, so
+// typos such as "type": "strng" are reported instead of silently producing
+// nil stubs. The schema must be decoded JSON (numbers as float64).
+func ValidateSchema(schema map[string]any) error {
+	meta, err := draft07MetaSchema()
+	if err != nil {
+		return err
+	}
+	err = meta.Validate(schema)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+
+	return fmt.Errorf("schema validation failed: %s", describeErrors(ve))
+}
+
+// ValidateStub checks a stub against the JSON Schema it should match. Strings
+// with a "format" or "x-stub-format" that has a registered FormatValidator
+// are checked by it.
+func ValidateStub(schema map[string]any, stub any) error {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+	compiler.AssertFormat = true
+	formatValidatorsMu.RLock()
+	for format, v := range formatValidators {
+		compiler.Formats[format] = func(value any) bool {
+			s, ok := value.(string)
+			return !ok || v.Validate(format, s) == nil
+		}
+	}
+	formatValidatorsMu.RUnlock()
+	compiler.RegisterExtension(stubFormatKeyword, stubFormatMeta, stubFormatCompiler{})
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	if err := compiler.AddResource("schema.json", bytes.NewReader(data)); err != nil {
+		return err
+	}
+	compiled, err := compiler.Compile("schema.json")
+	if err != nil {
+		return fmt.Errorf("compiling schema: %w", err)
+	}
+
+	// Round-trip the stub so its numbers are float64s, as the validator expects.
+	data, err = json.Marshal(stub)
+	if err != nil {
+		return err
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	err = compiled.Validate(decoded)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+	return fmt.Errorf("stub validation failed: %s", describeErrors(ve))
+}
+
+// stubFormatKeyword names a custom format without asking generic validators,
+// which may reject unknown formats, to check it.
+const stubFormatKeyword = "x-stub-format"
+
+var stubFormatMeta = jsonschema.MustCompileString("x-stub-format.json", `{
+	"properties": {"x-stub-format": {"type": "string"}}
+}`)
+
+type stubFormatCompiler struct{}
+
+func (stubFormatCompiler) Compile(_ jsonschema.CompilerContext, m map[string]any) (jsonschema.ExtSchema, error) {
+	format, ok := m[stubFormatKeyword].(string)
+	if !ok {
+		return nil, nil
+	}
+	return stubFormatSchema(format), nil
+}
+
+// stubFormatSchema checks values against the validator for its format, if
+// one is registered.
+type stubFormatSchema string
+
+func (s stubFormatSchema) Validate(ctx jsonschema.ValidationContext, v any) error {
+	value, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	validator, ok := lookupFormatValidator(string(s))
+	if !ok {
+		return nil
+	}
+	if err := validator.Validate(string(s), value); err != nil {
+		return ctx.Error(stubFormatKeyword, "%s", err)
+	}
+	return nil
+}
+
+// describeErrors lists the most specific causes of a validation error with
+// their locations.
+func describeErrors(ve *jsonschema.ValidationError) string {
+	var problems []string
+	for _, leaf := range leafErrors(ve) {
+		location := leaf.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s", location, leaf.Message))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// leafErrors returns the most specific causes of a validation error.
+func leafErrors(ve *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(ve.Causes) == 0 {
+		return []*jsonschema.ValidationError{ve}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, c := range ve.Causes {
+		leaves = append(leaves, leafErrors(c)...)
+	}
+	return leaves
+}
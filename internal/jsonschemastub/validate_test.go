@@ -0,0 +1,95 @@
+package jsonschemastub
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	decode := func(t *testing.T, s string) map[string]any {
+		t.Helper()
+		var m map[string]any
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	t.Run("accepts a valid schema", func(t *testing.T) {
+		schema := decode(t, `{"type":"object","properties":{"name":{"type":"string"},"height":{"type":"integer","minimum":1}}}`)
+		if err := ValidateSchema(schema); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("reports a misspelled type with its location", func(t *testing.T) {
+		err := ValidateSchema(decode(t, `{"type":"object","properties":{"name":{"type":"strng"}}}`))
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.HasPrefix(err.Error(), "schema validation failed: /properties/name/type: ") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+	})
+
+	t.Run("reports a non-numeric minimum", func(t *testing.T) {
+		err := ValidateSchema(decode(t, `{"type":"integer","minimum":"1"}`))
+		if err == nil || !strings.Contains(err.Error(), "/minimum: expected number, but got string") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestValidateStub(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":   map[string]any{"type": "string", "format": "pokemon-name"},
+			"type":   map[string]any{"type": "string", "x-stub-format": "pokemon-type"},
+			"height": map[string]any{"type": "integer"},
+		},
+	}
+
+	t.Run("accepts a matching stub", func(t *testing.T) {
+		stub := map[string]any{"name": "mr-mime", "type": "psychic", "height": 13}
+		if err := ValidateStub(schema, stub); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("rejects invalid custom format values", func(t *testing.T) {
+		for field, value := range map[string]string{"name": "Mr. Mime", "type": "cosmic"} {
+			stub := map[string]any{"name": "pikachu", "type": "electric", "height": 4}
+			stub[field] = value
+			err := ValidateStub(schema, stub)
+			if err == nil || !strings.Contains(err.Error(), "/"+field+": ") {
+				t.Errorf("%s: expected an error at /%s, got %v", field, field, err)
+			}
+		}
+	})
+
+	t.Run("rejects type mismatches", func(t *testing.T) {
+		err := ValidateStub(schema, map[string]any{"name": "pikachu", "type": "electric", "height": "tall"})
+		if err == nil || !strings.HasPrefix(err.Error(), "stub validation failed: /height: ") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("uses registered validators", func(t *testing.T) {
+		RegisterFormatValidator("trainer-id", FormatValidatorFunc(func(format, value string) error {
+			if !strings.HasPrefix(value, "T-") {
+				return fmt.Errorf("%q is not a valid %s", value, format)
+			}
+			return nil
+		}))
+		idSchema := map[string]any{"type": "string", "x-stub-format": "trainer-id"}
+		if err := ValidateStub(idSchema, "T-1"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if err := ValidateStub(idSchema, "1"); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package jsonschemastub
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// valuesKeyword lists the values a string field cycles through instead of
+// being generated randomly.
+const valuesKeyword = "x-stub-values"
+
+// valueCycle is the position reached in the "x-stub-values" of one path.
+type valueCycle struct {
+	values []any
+	next   int
+}
+
+// WithShuffledValues shuffles each "x-stub-values" list once, before its
+// values are first used, instead of cycling through them in order.
+func WithShuffledValues() GenOption {
+	return func(g *Generator) {
+		g.shuffleValues = true
+	}
+}
+
+// nextValue returns the next of values for the field being generated. The
+// position is kept per path across calls to Generate, so successive stubs
+// take successive values and wrap around at the end of the list.
+func (g *Generator) nextValue(values []any) string {
+	path := strings.Join(g.path, ".")
+	cycle := g.cycles[path]
+	if cycle == nil {
+		cycle = &valueCycle{values: slices.Clone(values)}
+		if g.shuffleValues {
+			g.rand.Shuffle(len(cycle.values), func(i, j int) {
+				cycle.values[i], cycle.values[j] = cycle.values[j], cycle.values[i]
+			})
+		}
+		g.cycles[path] = cycle
+	}
+	v := cycle.values[cycle.next%len(cycle.values)]
+	cycle.next++
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// WithSequentialIntegers generates integers from a counter per field that
+// starts at 1 and goes up by 1 each time, instead of randomly, so that IDs are
+// unique across the stubs of one generator, as database fixtures need. A
+// field with a "minimum" starts there instead, and one with a "maximum"
+// wraps back to its start after reaching it.
+func WithSequentialIntegers() GenOption {
+	return func(g *Generator) {
+		g.sequences = map[string]int{}
+	}
+}
+
+// nextInSequence returns the next integer between min and max for the field
+// being generated.
+func (g *Generator) nextInSequence(min, max int, bounded bool) int {
+	path := strings.Join(g.path, ".")
+	n := g.sequences[path]
+	g.sequences[path]++
+	if bounded && max >= min {
+		n %= max - min + 1
+	}
+	return min + n
+}
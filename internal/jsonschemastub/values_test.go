@@ -0,0 +1,116 @@
+package jsonschemastub
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStubValues(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string", "x-stub-values": []any{"Pikachu", "Charizard", "Bulbasaur"}},
+		},
+	}
+	generateNames := func(opts ...GenOption) []string {
+		g := NewGenerator(append(opts, WithSeed(1))...)
+		var names []string
+		for range 6 {
+			stub, err := g.Generate(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, stub.(map[string]any)["name"].(string))
+		}
+		return names
+	}
+
+	t.Run("cycles through the values across stubs", func(t *testing.T) {
+		want := []string{"Pikachu", "Charizard", "Bulbasaur", "Pikachu", "Charizard", "Bulbasaur"}
+		if got := generateNames(); !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("uses each value equally often when shuffled", func(t *testing.T) {
+		names := generateNames(WithShuffledValues())
+		counts := map[string]int{}
+		for _, name := range names {
+			counts[name]++
+		}
+		for _, name := range []string{"Pikachu", "Charizard", "Bulbasaur"} {
+			if counts[name] != 2 {
+				t.Errorf("expected %s twice, got %d in %v", name, counts[name], names)
+			}
+		}
+		if !slices.Equal(names[:3], names[3:]) {
+			t.Errorf("expected the shuffled order to repeat, got %v", names)
+		}
+	})
+
+	t.Run("cycles through array items", func(t *testing.T) {
+		g := NewGenerator(WithSeed(1))
+		items := g.generate(map[string]any{
+			"type": "array", "minItems": 4.0, "maxItems": 4.0,
+			"items": map[string]any{"type": "string", "x-stub-values": []any{"a", "b"}},
+		}).([]any)
+		if !slices.Equal(items, []any{"a", "b", "a", "b"}) {
+			t.Errorf("expected a, b, a, b, got %v", items)
+		}
+	})
+}
+
+func TestSequentialIntegers(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":    map[string]any{"type": "integer"},
+			"order": map[string]any{"type": "integer"},
+		},
+	}
+	g := NewGenerator(WithSequentialIntegers())
+	for want := 1; want <= 5; want++ {
+		stub, err := g.Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range []string{"id", "order"} {
+			if got := stub.(map[string]any)[field]; got != want {
+				t.Errorf("stub %d: expected %s %d, got %v", want, field, want, got)
+			}
+		}
+	}
+
+	t.Run("restarts for a new generator", func(t *testing.T) {
+		stub, err := NewGenerator(WithSequentialIntegers()).Generate(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := stub.(map[string]any)["id"]; got != 1 {
+			t.Errorf("expected id 1, got %v", got)
+		}
+	})
+	t.Run("stays within minimum and maximum", func(t *testing.T) {
+		schema := map[string]any{"type": "integer", "minimum": float64(1), "maximum": float64(3)}
+		g := NewGenerator(WithSequentialIntegers())
+		for i, want := range []int{1, 2, 3, 1, 2} {
+			stub, err := g.Generate(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stub != want {
+				t.Errorf("stub %d: expected %d, got %v", i, want, stub)
+			}
+			if err := ValidateStub(schema, stub); err != nil {
+				t.Error(err)
+			}
+		}
+		stub, err := NewGenerator(WithSequentialIntegers()).Generate(map[string]any{"type": "integer", "minimum": float64(100)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stub != 100 {
+			t.Errorf("expected the sequence to start at the minimum, got %v", stub)
+		}
+	})
+}
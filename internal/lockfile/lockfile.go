@@ -0,0 +1,77 @@
+// Package lockfile records what stubs were generated from, so they can be
+// regenerated identically later.
+package lockfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the default name of a lock file.
+const FileName = "graphql-stubs.lock"
+
+// Lock records the inputs of a stub generation run. Paths are relative to
+// the directory containing the lock file, with forward slashes, so the file
+// can be committed and shared.
+type Lock struct {
+	Seed           int64    `json:"seed"`
+	Overrides      string   `json:"overrides,omitempty"`
+	GraphQLSchemas []string `json:"graphql_schemas,omitempty"`
+	Queries        []Query  `json:"queries"`
+}
+
+// Query is a locked query file and the fingerprint it had when locked.
+type Query struct {
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Load reads a lock file.
+func Load(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// Save writes lock to path.
+func Save(path string, lock *Lock) error {
+	out, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0o644)
+}
+
+// Rel returns target relative to dir in the form stored in a lock file.
+func Rel(dir, target string) (string, error) {
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Resolve returns the path to a lock file entry, given the directory
+// containing the lock file.
+func Resolve(dir, path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(dir, filepath.FromSlash(path))
+}
@@ -0,0 +1,45 @@
+package lockfile
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	want := &Lock{
+		Seed:      42,
+		Overrides: "overrides.json",
+		Queries:   []Query{{Path: "queries/pokemon.graphql", Fingerprint: "abc"}},
+	}
+	if err := Save(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestRelResolve(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "queries", "pokemon.graphql")
+
+	rel, err := Rel(dir, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel != "queries/pokemon.graphql" {
+		t.Errorf("Rel: got %q", rel)
+	}
+	if got := Resolve(dir, rel); got != target {
+		t.Errorf("Resolve: got %q, want %q", got, target)
+	}
+	if got := Resolve(dir, ""); got != "" {
+		t.Errorf("Resolve of an empty path: got %q", got)
+	}
+}
@@ -0,0 +1,136 @@
+// Package manifest records the options of a generate run, so the run can be
+// replayed with identical options later.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/lockfile"
+)
+
+// Manifest records the inputs and options of a generate run. Like lock file
+// paths, paths are relative to the directory containing the manifest, with
+// forward slashes, so the file can be committed and shared.
+type Manifest struct {
+	Query              File           `json:"query"`
+	Overrides          *File          `json:"overrides,omitempty"`
+	StrictOverrides    bool           `json:"strict_overrides,omitempty"`
+	OperationAliases   *File          `json:"operation_aliases,omitempty"`
+	GraphQLSchemas     []File         `json:"graphql_schemas,omitempty"`
+	IntrospectionFile  *File          `json:"introspection_file,omitempty"`
+	ReferenceCSVs      []ReferenceCSV `json:"reference_csvs,omitempty"`
+	Seed               int64          `json:"seed"`
+	SubscriptionEvents int            `json:"subscription_events,omitempty"`
+	MutationInput      bool           `json:"mutation_input,omitempty"`
+	FirstEnum          bool           `json:"first_enum,omitempty"`
+	NameAware          bool           `json:"name_aware,omitempty"`
+	ShuffleValues      bool           `json:"shuffle_values,omitempty"`
+	SequentialIntegers bool           `json:"sequential_integers,omitempty"`
+	UniqueIDs          bool           `json:"unique_ids,omitempty"`
+	TypenameVariants   bool           `json:"typename_discriminator,omitempty"`
+	UseDefaults        bool           `json:"use_defaults,omitempty"`
+	Metadata           bool           `json:"metadata,omitempty"`
+	Output             string         `json:"output,omitempty"`
+	SchemaOut          string         `json:"schema_out,omitempty"`
+	SchemaDraft        string         `json:"schema_draft"`
+}
+
+// File is an input file and the fingerprint of its content when the
+// manifest was saved.
+type File struct {
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// ReferenceCSV is a field whose values are taken from a column of a CSV file.
+type ReferenceCSV struct {
+	Field  string `json:"field"`
+	File   File   `json:"file"`
+	Column string `json:"column"`
+}
+
+// NewFile fingerprints the file at path and records it relative to dir.
+func NewFile(dir, path string) (File, error) {
+	fingerprint, err := fingerprintFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	rel, err := lockfile.Rel(dir, path)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Path: rel, Fingerprint: fingerprint}, nil
+}
+
+// Files returns every input file recorded in m.
+func (m *Manifest) Files() []File {
+	files := []File{m.Query}
+	if m.Overrides != nil {
+		files = append(files, *m.Overrides)
+	}
+	if m.OperationAliases != nil {
+		files = append(files, *m.OperationAliases)
+	}
+	files = append(files, m.GraphQLSchemas...)
+	if m.IntrospectionFile != nil {
+		files = append(files, *m.IntrospectionFile)
+	}
+	for _, ref := range m.ReferenceCSVs {
+		files = append(files, ref.File)
+	}
+	return files
+}
+
+// Changed returns the resolved paths of the input files whose content no
+// longer matches their fingerprint, given the directory containing the
+// manifest.
+func (m *Manifest) Changed(dir string) ([]string, error) {
+	var changed []string
+	for _, f := range m.Files() {
+		path := lockfile.Resolve(dir, f.Path)
+		fingerprint, err := fingerprintFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if fingerprint != f.Fingerprint {
+			changed = append(changed, path)
+		}
+	}
+	return changed, nil
+}
+
+// Load reads a manifest.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Save writes m to path.
+func Save(path string, m *Manifest) error {
+	out, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0o644)
+}
+
+func fingerprintFile(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,68 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	query := filepath.Join(dir, "queries", "pokemon.graphql")
+	overrides := filepath.Join(dir, "overrides.json")
+	if err := os.MkdirAll(filepath.Dir(query), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{query: "query Q { pokemon { name } }", overrides: "{}"} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	queryFile, err := NewFile(dir, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overridesFile, err := NewFile(dir, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queryFile.Path != "queries/pokemon.graphql" || len(queryFile.Fingerprint) != 64 {
+		t.Errorf("unexpected query file %+v", queryFile)
+	}
+	want := &Manifest{Query: queryFile, Overrides: &overridesFile, Seed: 42, FirstEnum: true, Output: "queries/pokemon.json"}
+
+	path := filepath.Join(dir, "manifest.json")
+	if err := Save(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	t.Run("reports changed input files", func(t *testing.T) {
+		changed, err := got.Changed(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changed) != 0 {
+			t.Errorf("expected no changes, got %v", changed)
+		}
+
+		if err := os.WriteFile(overrides, []byte(`{"data.pokemon.name": "string"}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		changed, err = got.Changed(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(changed, []string{overrides}) {
+			t.Errorf("got %v, want %v", changed, []string{overrides})
+		}
+	})
+}
@@ -0,0 +1,53 @@
+// Package openapiexamples renders stubs as an OpenAPI 3.0 examples object, so
+// they can be embedded under a response's "examples" in an OpenAPI document.
+package openapiexamples
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Example is an OpenAPI example object.
+type Example struct {
+	Summary string `json:"summary"`
+	Value   any    `json:"value"`
+}
+
+// Examples maps example names to examples.
+type Examples map[string]Example
+
+// New returns an examples object holding one example per stub. Examples are
+// named by names, which must then have a unique name per stub, or Example1,
+// Example2 and so on when names is empty. Summaries mention operationName when
+// it is not empty.
+func New(stubs []any, names []string, operationName string) (Examples, error) {
+	if len(names) > 0 && len(names) != len(stubs) {
+		return nil, fmt.Errorf("got %d example names for %d stubs", len(names), len(stubs))
+	}
+	examples := make(Examples, len(stubs))
+	for i, stub := range stubs {
+		name := "Example" + strconv.Itoa(i+1)
+		if len(names) > 0 {
+			name = names[i]
+		}
+		if name == "" {
+			return nil, fmt.Errorf("example %d has an empty name", i+1)
+		}
+		if _, ok := examples[name]; ok {
+			return nil, fmt.Errorf("duplicate example name %q", name)
+		}
+		examples[name] = Example{Summary: summary(operationName, i+1, len(stubs)), Value: stub}
+	}
+	return examples, nil
+}
+
+func summary(operationName string, n, total int) string {
+	s := "Generated stub"
+	if operationName != "" {
+		s = "Generated " + operationName + " response"
+	}
+	if total > 1 {
+		s += fmt.Sprintf(" %d of %d", n, total)
+	}
+	return s
+}
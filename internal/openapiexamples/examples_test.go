@@ -0,0 +1,70 @@
+package openapiexamples
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	stubs := []any{
+		map[string]any{"data": map[string]any{"pokemon": map[string]any{"name": "pikachu"}}},
+		map[string]any{"data": map[string]any{"pokemon": map[string]any{"name": "eevee"}}},
+	}
+
+	decode := func(t *testing.T, names []string, operationName string) map[string]any {
+		t.Helper()
+		examples, err := New(stubs, names, operationName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(examples)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(out, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		return decoded
+	}
+
+	t.Run("numbers examples by default", func(t *testing.T) {
+		examples := decode(t, nil, "")
+		if len(examples) != 2 {
+			t.Fatalf("expected 2 examples, got %v", examples)
+		}
+		for i, name := range []string{"Example1", "Example2"} {
+			example, ok := examples[name].(map[string]any)
+			if !ok {
+				t.Fatalf("missing %s in %v", name, examples)
+			}
+			if len(example) != 2 || example["summary"] == "" {
+				t.Errorf("%s: expected summary and value only, got %v", name, example)
+			}
+			got := example["value"].(map[string]any)["data"].(map[string]any)["pokemon"].(map[string]any)["name"]
+			want := stubs[i].(map[string]any)["data"].(map[string]any)["pokemon"].(map[string]any)["name"]
+			if got != want {
+				t.Errorf("%s value: got %v, want %v", name, got, want)
+			}
+		}
+	})
+
+	t.Run("uses the given names and operation name", func(t *testing.T) {
+		examples := decode(t, []string{"Pikachu", "Eevee"}, "GetPokemon")
+		example, ok := examples["Eevee"].(map[string]any)
+		if !ok {
+			t.Fatalf("missing Eevee in %v", examples)
+		}
+		if example["summary"] != "Generated GetPokemon response 2 of 2" {
+			t.Errorf("summary: got %v", example["summary"])
+		}
+	})
+
+	t.Run("rejects names that do not match the stubs", func(t *testing.T) {
+		for _, names := range [][]string{{"Only"}, {"Same", "Same"}, {"", "Other"}} {
+			if _, err := New(stubs, names, ""); err == nil {
+				t.Errorf("%q: expected error, got nil", names)
+			}
+		}
+	})
+}
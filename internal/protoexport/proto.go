@@ -0,0 +1,203 @@
+// Package protoexport converts the JSON Schemas built from GraphQL queries into
+// proto3 message definitions, for teams bridging GraphQL and gRPC.
+package protoexport
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// scalarTypes maps JSON Schema types to proto3 scalar types. Integers become
+// int64 instead when the schema has "x-stub-format": "int64".
+var scalarTypes = map[string]string{
+	"string":  "string",
+	"integer": "int32",
+	"number":  "double",
+	"boolean": "bool",
+}
+
+// Convert returns a proto3 file declaring a message named name for a JSON
+// Schema object. Object properties become fields numbered from 1 in sorted
+// order, arrays become repeated fields and nullable scalars become optional
+// fields. With nested, each object's message is declared inside its parent's
+// and named after its field, e.g. PokemonV2Pokemon; otherwise every message is
+// top-level and named after its whole path, e.g. ResponseDataPokemonV2Pokemon.
+// "$defs" referenced by "$ref" are always top-level messages.
+func Convert(schema map[string]any, name string, nested bool) (string, error) {
+	c := &converter{nested: nested, defs: map[string]any{}, defined: map[string]bool{}}
+	if defs, ok := schema["$defs"].(map[string]any); ok {
+		c.defs = defs
+	}
+	root, err := c.message(schema, messageName(name), 0)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("syntax = \"proto3\";\n")
+	for _, m := range append([]string{root}, c.topLevel...) {
+		b.WriteString("\n" + m)
+	}
+	return b.String(), nil
+}
+
+type converter struct {
+	nested bool
+	// defs holds the root "$defs" that "$ref"s point into.
+	defs map[string]any
+	// defined records the "$defs" already declared as messages.
+	defined map[string]bool
+	// topLevel holds the declarations of top-level messages other than the
+	// root, in the order they were converted.
+	topLevel []string
+}
+
+// message declares a message named name for an object schema, indented by
+// depth levels.
+func (c *converter) message(schema map[string]any, name string, depth int) (string, error) {
+	indent := strings.Repeat("  ", depth)
+	var fields, inner []string
+	properties, _ := schema["properties"].(map[string]any)
+	for i, key := range slices.Sorted(maps.Keys(properties)) {
+		ps, ok := properties[key].(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("%s.%s: property schema is not an object", name, key)
+		}
+		f, err := c.field(ps, name, key, depth+1)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, fmt.Sprintf("%s  %s%s %s = %d;\n", indent, f.label, f.typ, key, i+1))
+		if f.message != "" {
+			inner = append(inner, f.message)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%smessage %s {\n", indent, name)
+	for _, f := range fields {
+		b.WriteString(f)
+	}
+	for _, m := range inner {
+		b.WriteString("\n" + m)
+	}
+	fmt.Fprintf(&b, "%s}\n", indent)
+	return b.String(), nil
+}
+
+// field describes a message field: its label ("repeated " or "optional "),
+// its type, and the declaration of its message type when it is nested.
+type field struct {
+	label, typ, message string
+}
+
+// field converts the property key of the message parent.
+func (c *converter) field(schema map[string]any, parent, key string, depth int) (field, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		typ, err := c.ref(ref)
+		return field{typ: typ}, err
+	}
+
+	t, nullable, err := schemaType(schema)
+	if err != nil {
+		return field{}, fmt.Errorf("%s.%s: %w", parent, key, err)
+	}
+	switch t {
+	case "object":
+		if c.nested {
+			name := messageName(key)
+			m, err := c.message(schema, name, depth)
+			return field{typ: name, message: m}, err
+		}
+		name := parent + messageName(key)
+		// Reserve the slot first so a message is declared before its fields'.
+		i := len(c.topLevel)
+		c.topLevel = append(c.topLevel, "")
+		m, err := c.message(schema, name, 0)
+		c.topLevel[i] = m
+		return field{typ: name}, err
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return field{}, fmt.Errorf("%s.%s: array has no items schema", parent, key)
+		}
+		f, err := c.field(items, parent, key, depth)
+		if err != nil {
+			return field{}, err
+		}
+		if f.label == "repeated " {
+			return field{}, fmt.Errorf("%s.%s: nested lists cannot be represented in proto3", parent, key)
+		}
+		f.label = "repeated "
+		return f, nil
+	}
+
+	typ, ok := scalarTypes[t]
+	if !ok {
+		return field{}, fmt.Errorf("%s.%s: cannot convert type %q to proto3", parent, key, t)
+	}
+	if t == "integer" && schema["x-stub-format"] == "int64" {
+		typ = "int64"
+	}
+	f := field{typ: typ}
+	if nullable {
+		f.label = "optional "
+	}
+	return f, nil
+}
+
+// ref resolves a "#/$defs/..." reference to a top-level message, declaring
+// it on first use.
+func (c *converter) ref(ref string) (string, error) {
+	def, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return "", fmt.Errorf("unsupported $ref %q", ref)
+	}
+	name := messageName(def)
+	if c.defined[def] {
+		return name, nil
+	}
+	schema, ok := c.defs[def].(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("$ref %q has no definition", ref)
+	}
+	c.defined[def] = true
+	i := len(c.topLevel)
+	c.topLevel = append(c.topLevel, "")
+	m, err := c.message(schema, name, 0)
+	c.topLevel[i] = m
+	return name, err
+}
+
+// schemaType returns the single non-null type of schema and whether it also
+// allows null.
+func schemaType(schema map[string]any) (string, bool, error) {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	nullable := slices.Contains(types, "null")
+	types = slices.DeleteFunc(types, func(t string) bool { return t == "null" })
+	if len(types) != 1 {
+		return "", false, fmt.Errorf("cannot convert type %v to proto3", schema["type"])
+	}
+	return types[0], nullable, nil
+}
+
+// messageName converts a field or definition name such as pokemon_v2_stat to
+// a message name such as PokemonV2Stat.
+func messageName(name string) string {
+	var b strings.Builder
+	for part := range strings.FieldsFuncSeq(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
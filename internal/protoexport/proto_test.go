@@ -0,0 +1,119 @@
+package protoexport
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+)
+
+func TestConvert(t *testing.T) {
+	query, err := os.ReadFile("../graphqlschema/testdata/pokemon_stats.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := graphqlschema.BuildSchema(string(query), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messageLine := regexp.MustCompile(`^\s*message [A-Z]\w* \{$`)
+	fieldLine := regexp.MustCompile(`^\s*(repeated |optional )?\w+ \w+ = (\d+);$`)
+	// checkSyntax checks every line is a syntax, message, field or closing
+	// line, and that fields are numbered from 1 within each message.
+	checkSyntax := func(t *testing.T, proto string) {
+		t.Helper()
+		if !strings.HasPrefix(proto, "syntax = \"proto3\";\n") {
+			t.Errorf("missing syntax line:\n%s", proto)
+		}
+		var numbers []int
+		for _, line := range strings.Split(proto, "\n")[1:] {
+			switch {
+			case strings.TrimSpace(line) == "":
+			case messageLine.MatchString(line):
+				numbers = append(numbers, 0)
+			case strings.TrimSpace(line) == "}":
+				numbers = numbers[:len(numbers)-1]
+			case fieldLine.MatchString(line):
+				n := fieldLine.FindStringSubmatch(line)[2]
+				numbers[len(numbers)-1]++
+				if want := numbers[len(numbers)-1]; n != strconv.Itoa(want) {
+					t.Errorf("field %q: want number %d", line, want)
+				}
+			default:
+				t.Errorf("unexpected line %q", line)
+			}
+		}
+		if len(numbers) != 0 {
+			t.Errorf("unbalanced braces:\n%s", proto)
+		}
+	}
+
+	t.Run("declares top-level messages named after their path", func(t *testing.T) {
+		proto, err := Convert(schema, "Response", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSyntax(t, proto)
+		for _, want := range []string{
+			"message Response {\n  ResponseData data = 1;\n}\n\nmessage ResponseData {",
+			"message ResponseData {\n  ResponseDataPokemonV2Pokemon pokemon_v2_pokemon = 1;\n}",
+			"int32 base_experience = 1;",
+			"string name = 3;",
+			"message ResponseDataPokemonV2PokemonPokemonV2Pokemonstats {",
+		} {
+			if !strings.Contains(proto, want) {
+				t.Errorf("expected %q in:\n%s", want, proto)
+			}
+		}
+	})
+
+	t.Run("nests messages with --proto-nested", func(t *testing.T) {
+		proto, err := Convert(schema, "Response", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSyntax(t, proto)
+		if !strings.Contains(proto, "\n  message Data {\n    PokemonV2Pokemon pokemon_v2_pokemon = 1;\n") {
+			t.Errorf("expected Data nested in Response:\n%s", proto)
+		}
+		if strings.Count(proto, "\nmessage ") != 1 {
+			t.Errorf("expected a single top-level message:\n%s", proto)
+		}
+	})
+
+	t.Run("maps scalar types", func(t *testing.T) {
+		proto, err := Convert(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"a": map[string]any{"type": "boolean"},
+				"b": map[string]any{"type": "integer", "x-stub-format": "int64"},
+				"c": map[string]any{"type": "number"},
+				"d": map[string]any{"type": []any{"string", "null"}},
+				"e": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+		}, "Row", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "message Row {\n  bool a = 1;\n  int64 b = 2;\n  double c = 3;\n  optional string d = 4;\n  repeated string e = 5;\n}\n"
+		if !strings.HasSuffix(proto, want) {
+			t.Errorf("got:\n%s\nwant:\n%s", proto, want)
+		}
+	})
+
+	t.Run("rejects nested lists", func(t *testing.T) {
+		_, err := Convert(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"grid": map[string]any{"type": "array", "items": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}}},
+			},
+		}, "Row", false)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
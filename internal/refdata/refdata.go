@@ -0,0 +1,72 @@
+// Package refdata loads reference data, such as lists of valid names from a
+// CSV file, that stub values can be drawn from.
+package refdata
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// CSVValuePool loads CSV files and caches their contents, so several fields
+// drawing from one file read it once. It is safe for concurrent use.
+type CSVValuePool struct {
+	mu    sync.Mutex
+	files map[string][][]string
+}
+
+// NewCSVValuePool returns an empty pool.
+func NewCSVValuePool() *CSVValuePool {
+	return &CSVValuePool{files: map[string][][]string{}}
+}
+
+// Column returns the non-empty values of the named column of a CSV file
+// whose first row is a header.
+func (p *CSVValuePool) Column(file, column string) ([]string, error) {
+	rows, err := p.load(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: no header row", file)
+	}
+	index := slices.Index(rows[0], column)
+	if index < 0 {
+		return nil, fmt.Errorf("%s: no column %q (have %v)", file, column, rows[0])
+	}
+	var values []string
+	for _, row := range rows[1:] {
+		if index < len(row) && row[index] != "" {
+			values = append(values, row[index])
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s: column %q has no values", file, column)
+	}
+	return values, nil
+}
+
+// load returns the rows of file, reading it on first use.
+func (p *CSVValuePool) load(file string) ([][]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if rows, ok := p.files[file]; ok {
+		return rows, nil
+	}
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	p.files[file] = rows
+	return rows, nil
+}
@@ -0,0 +1,53 @@
+package refdata
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCSVValuePool(t *testing.T) {
+	pool := NewCSVValuePool()
+
+	t.Run("returns a column's values", func(t *testing.T) {
+		got, err := pool.Column("testdata/pokemon.csv", "name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"Pikachu", "Charizard", "Bulbasaur", "Squirtle", "Eevee"}
+		if !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("caches files", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "moves.csv")
+		if err := os.WriteFile(file, []byte("move\nthunderbolt\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pool.Column(file, "move"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(file); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := pool.Column(file, "move"); err != nil || !slices.Equal(got, []string{"thunderbolt"}) {
+			t.Errorf("expected the cached column, got %v, %v", got, err)
+		}
+	})
+
+	t.Run("reports unknown columns", func(t *testing.T) {
+		_, err := pool.Column("testdata/pokemon.csv", "height")
+		if err == nil || !strings.Contains(err.Error(), `"height"`) {
+			t.Errorf("expected an unknown column error, got %v", err)
+		}
+	})
+
+	t.Run("reports missing files", func(t *testing.T) {
+		if _, err := pool.Column("testdata/missing.csv", "name"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
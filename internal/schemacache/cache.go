@@ -0,0 +1,85 @@
+// Package schemacache keeps fetched GraphQL SDL schemas on disk, so commands
+// given a schema URL can run without network access once it is cached.
+package schemacache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache stores schemas in Dir, one file per URL named after the URL's hash.
+// Entries older than TTL are treated as missing; a TTL of 0 never expires.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+
+	// now returns the current time; tests replace it.
+	now func() time.Time
+}
+
+// New returns a cache storing schemas in dir for ttl.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl, now: time.Now}
+}
+
+// Get returns the cached schema for url. ok is false when there is no entry
+// or it has expired.
+func (c *Cache) Get(url string) (sdl []byte, ok bool, err error) {
+	path := c.path(url)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if c.TTL > 0 && c.now().Sub(info.ModTime()) > c.TTL {
+		return nil, false, nil
+	}
+	sdl, err = os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return sdl, true, nil
+}
+
+// Set stores sdl as the schema for url, creating Dir when needed.
+func (c *Cache) Set(url string, sdl []byte) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	// Write through a temporary file so a concurrent Get never reads a
+	// partial schema.
+	tmp, err := os.CreateTemp(c.Dir, ".schema-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(sdl); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(url))
+}
+
+// Invalidate removes the cached schema for url, if any.
+func (c *Cache) Invalidate(url string) error {
+	err := os.Remove(c.path(url))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".graphqls")
+}
@@ -0,0 +1,52 @@
+package schemacache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	const url = "https://example.com/schema.graphqls"
+	c := New(filepath.Join(t.TempDir(), "cache"), time.Hour)
+
+	if _, ok, err := c.Get(url); err != nil || ok {
+		t.Fatalf("expected a miss on an empty cache, got ok=%v err=%v", ok, err)
+	}
+	if err := c.Set(url, []byte("type Query { a: Int }")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("returns stored schemas", func(t *testing.T) {
+		sdl, ok, err := c.Get(url)
+		if err != nil || !ok {
+			t.Fatalf("expected a hit, got ok=%v err=%v", ok, err)
+		}
+		if string(sdl) != "type Query { a: Int }" {
+			t.Errorf("got %q", sdl)
+		}
+		if _, ok, _ := c.Get("https://example.com/other.graphqls"); ok {
+			t.Error("expected a miss for another URL")
+		}
+	})
+
+	t.Run("misses expired entries", func(t *testing.T) {
+		c.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		defer func() { c.now = time.Now }()
+		if _, ok, err := c.Get(url); err != nil || ok {
+			t.Errorf("expected an expired entry to miss, got ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("Invalidate removes entries", func(t *testing.T) {
+		if err := c.Invalidate(url); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, _ := c.Get(url); ok {
+			t.Error("expected a miss after Invalidate")
+		}
+		if err := c.Invalidate(url); err != nil {
+			t.Errorf("invalidating a missing entry: %v", err)
+		}
+	})
+}
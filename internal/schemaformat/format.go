@@ -0,0 +1,144 @@
+// Package schemaformat writes the JSON Schemas built from GraphQL queries in
+// the output formats the CLI supports.
+package schemaformat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/avroexport"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/graphqlschema"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/protoexport"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/schemastoreexport"
+)
+
+// Formatter writes a schema in one output format.
+type Formatter interface {
+	Format(schema map[string]any, w io.Writer) error
+}
+
+// JSONSchema writes the schema itself as indented JSON, or with Canonical
+// as RFC 8785 canonical JSON for content hashing.
+type JSONSchema struct {
+	Canonical bool
+}
+
+// Format implements Formatter.
+func (j JSONSchema) Format(schema map[string]any, w io.Writer) error {
+	if !j.Canonical {
+		return writeJSON(schema, w)
+	}
+	out, err := graphqlschema.MarshalCanonical(schema)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// Avro writes the schema as an Avro record named Name.
+type Avro struct {
+	Name string
+}
+
+// Format implements Formatter.
+func (a Avro) Format(schema map[string]any, w io.Writer) error {
+	record, err := avroexport.Convert(schema, a.Name)
+	if err != nil {
+		return err
+	}
+	return writeJSON(record, w)
+}
+
+// Proto3 writes the schema as proto3 messages, the root one named Name. With
+// Nested, each object's message is declared inside its parent's.
+type Proto3 struct {
+	Name   string
+	Nested bool
+}
+
+// Format implements Formatter.
+func (p Proto3) Format(schema map[string]any, w io.Writer) error {
+	proto, err := protoexport.Convert(schema, p.Name, p.Nested)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, proto)
+	return err
+}
+
+// TypeMap writes the flat path-to-type map from graphqlschema.SchemaToTypeMap,
+// which doubles as a starting overrides file.
+type TypeMap struct{}
+
+// Format implements Formatter.
+func (TypeMap) Format(schema map[string]any, w io.Writer) error {
+	return writeJSON(graphqlschema.SchemaToTypeMap(schema), w)
+}
+
+// JSONLines writes each leaf of the schema as a line of JSON, using
+// graphqlschema.StreamSchema.
+type JSONLines struct{}
+
+// Format implements Formatter.
+func (JSONLines) Format(schema map[string]any, w io.Writer) error {
+	return graphqlschema.StreamSchema(schema, "", w)
+}
+
+// SchemaStore writes a JSON Schema Store catalog pointing at the schema
+// published at URL, for the operation named OperationName (or the schema's
+// "x-operation-name" when empty).
+type SchemaStore struct {
+	OperationName string
+	URL           string
+}
+
+// Format implements Formatter.
+func (s SchemaStore) Format(schema map[string]any, w io.Writer) error {
+	if s.URL == "" {
+		return errors.New("schemastore output needs the URL the schema is published at")
+	}
+	name := s.OperationName
+	if name == "" {
+		name, _ = schema["x-operation-name"].(string)
+	}
+	return writeJSON(schemastoreexport.NewCatalog(name, s.URL, nil), w)
+}
+
+func writeJSON(v any, w io.Writer) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// formatters maps format names to their Formatter.
+var formatters = map[string]Formatter{
+	"json-schema": JSONSchema{},
+	"avro":        Avro{Name: "Response"},
+	"proto3":      Proto3{Name: "Response"},
+	"type-map":    TypeMap{},
+	"jsonl":       JSONLines{},
+	"schemastore": SchemaStore{},
+}
+
+// Lookup returns the Formatter for a format name.
+func Lookup(name string) (Formatter, error) {
+	f, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %q (want %s)", name, strings.Join(Names(), ", "))
+	}
+	return f, nil
+}
+
+// Names returns the supported format names, sorted.
+func Names() []string {
+	return slices.Sorted(maps.Keys(formatters))
+}
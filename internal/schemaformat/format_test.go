@@ -0,0 +1,97 @@
+package schemaformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"name": map[string]any{"type": "string"}},
+	}
+
+	t.Run("formats with each supported formatter", func(t *testing.T) {
+		for name, wantType := range map[string]string{"json-schema": "object", "avro": "record"} {
+			f, err := Lookup(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(schema, &buf); err != nil {
+				t.Fatal(err)
+			}
+			var out map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("%s: output is not valid JSON: %v", name, err)
+			}
+			if out["type"] != wantType {
+				t.Errorf("%s: expected type %q, got %v", name, wantType, out["type"])
+			}
+		}
+	})
+
+	t.Run("lists supported formats for unknown names", func(t *testing.T) {
+		_, err := Lookup("typescript")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "avro, json-schema") {
+			t.Errorf("expected supported formats in error, got %v", err)
+		}
+	})
+}
+
+func TestTypeMap(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"data": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":   map[string]any{"type": "string"},
+					"height": map[string]any{"type": "integer"},
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := (TypeMap{}).Format(schema, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"data.height\": \"integer\",\n  \"data.name\": \"string\"\n}\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSchemaStore(t *testing.T) {
+	schema := map[string]any{"type": "object", "x-operation-name": "GetPokemon"}
+
+	t.Run("writes a catalog entry for the schema URL", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := (SchemaStore{URL: "https://example.com/schema.json"}).Format(schema, &buf); err != nil {
+			t.Fatal(err)
+		}
+		var catalog struct {
+			Schemas []struct {
+				Name string `json:"name"`
+				URL  string `json:"url"`
+			} `json:"schemas"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &catalog); err != nil {
+			t.Fatal(err)
+		}
+		if len(catalog.Schemas) != 1 || catalog.Schemas[0].Name != "GetPokemon Response" || catalog.Schemas[0].URL != "https://example.com/schema.json" {
+			t.Errorf("unexpected catalog %+v", catalog)
+		}
+	})
+
+	t.Run("requires a URL", func(t *testing.T) {
+		if err := (SchemaStore{}).Format(schema, &bytes.Buffer{}); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
@@ -0,0 +1,48 @@
+// Package schemastoreexport describes generated schemas as JSON Schema Store
+// catalogs, so IDEs can offer completion and validation in stub files.
+package schemastoreexport
+
+// CatalogSchema is the JSON Schema of a schema catalog.
+const CatalogSchema = "https://json.schemastore.org/schema-catalog.json"
+
+// DefaultFileMatch is the glob of stub files an entry applies to by default.
+const DefaultFileMatch = "*.stub.json"
+
+// Catalog is a JSON Schema Store catalog, as served at
+// https://www.schemastore.org/api/json/catalog.json.
+type Catalog struct {
+	Schema  string  `json:"$schema"`
+	Version float64 `json:"version"`
+	Schemas []Entry `json:"schemas"`
+}
+
+// Entry is one schema in a catalog.
+type Entry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	FileMatch   []string `json:"fileMatch"`
+	URL         string   `json:"url"`
+}
+
+// NewCatalog returns a catalog with one entry pointing at the schema
+// published at url for the named operation's responses. An empty
+// operationName describes the responses of the query as a whole.
+func NewCatalog(operationName, url string, fileMatch []string) Catalog {
+	if len(fileMatch) == 0 {
+		fileMatch = []string{DefaultFileMatch}
+	}
+	name, subject := "GraphQL Response", "a GraphQL query"
+	if operationName != "" {
+		name, subject = operationName+" Response", "the "+operationName+" GraphQL operation"
+	}
+	return Catalog{
+		Schema:  CatalogSchema,
+		Version: 1,
+		Schemas: []Entry{{
+			Name:        name,
+			Description: "Stub responses for " + subject,
+			FileMatch:   fileMatch,
+			URL:         url,
+		}},
+	}
+}
@@ -0,0 +1,43 @@
+package schemastoreexport
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestNewCatalog(t *testing.T) {
+	t.Run("matches the catalog structure", func(t *testing.T) {
+		out, err := json.Marshal(NewCatalog("GetPokemon", "https://example.com/get-pokemon.schema.json", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var catalog map[string]any
+		if err := json.Unmarshal(out, &catalog); err != nil {
+			t.Fatal(err)
+		}
+		if catalog["$schema"] != CatalogSchema || catalog["version"] != float64(1) {
+			t.Errorf("unexpected catalog header %v", catalog)
+		}
+		schemas := catalog["schemas"].([]any)
+		if len(schemas) != 1 {
+			t.Fatalf("expected one entry, got %v", schemas)
+		}
+		entry := schemas[0].(map[string]any)
+		for _, key := range []string{"name", "description", "fileMatch", "url"} {
+			if _, ok := entry[key]; !ok {
+				t.Errorf("entry is missing %q: %v", key, entry)
+			}
+		}
+		if entry["name"] != "GetPokemon Response" || entry["url"] != "https://example.com/get-pokemon.schema.json" {
+			t.Errorf("unexpected entry %v", entry)
+		}
+	})
+
+	t.Run("defaults the name and file match", func(t *testing.T) {
+		entry := NewCatalog("", "schema.json", nil).Schemas[0]
+		if entry.Name != "GraphQL Response" || !slices.Equal(entry.FileMatch, []string{"*.stub.json"}) {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+	})
+}
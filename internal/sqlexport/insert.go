@@ -0,0 +1,51 @@
+// Package sqlexport renders stubs as SQL fixture data.
+package sqlexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GenerateInsert returns an INSERT statement adding stub as one row of table.
+// The stub's top-level fields become columns, in sorted order. Strings are
+// single-quoted, booleans become TRUE or FALSE and nil becomes NULL; nested
+// objects and arrays are inserted as JSON text.
+func GenerateInsert(table string, stub map[string]any) string {
+	columns := slices.Sorted(maps.Keys(stub))
+	values := make([]string, len(columns))
+	for i, c := range columns {
+		values[i] = literal(stub[c])
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", table, strings.Join(columns, ", "), strings.Join(values, ", "))
+}
+
+// literal returns the SQL literal for a stub value.
+func literal(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return quote(v)
+	default:
+		out, _ := json.Marshal(v)
+		return quote(string(out))
+	}
+}
+
+// quote single-quotes s, doubling embedded quotes.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
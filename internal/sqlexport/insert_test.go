@@ -0,0 +1,44 @@
+package sqlexport
+
+import "testing"
+
+func TestGenerateInsert(t *testing.T) {
+	cases := []struct {
+		name string
+		stub map[string]any
+		want string
+	}{
+		{
+			name: "orders columns and quotes strings",
+			stub: map[string]any{"name": "azure-blaze", "base_experience": 42, "height": 12},
+			want: "INSERT INTO pokemon (base_experience, height, name) VALUES (42, 12, 'azure-blaze');",
+		},
+		{
+			name: "escapes single quotes",
+			stub: map[string]any{"name": "farfetch'd"},
+			want: "INSERT INTO pokemon (name) VALUES ('farfetch''d');",
+		},
+		{
+			name: "writes floats without exponents",
+			stub: map[string]any{"rate": 0.25, "weight": float64(1000000)},
+			want: "INSERT INTO pokemon (rate, weight) VALUES (0.25, 1000000);",
+		},
+		{
+			name: "maps booleans and null",
+			stub: map[string]any{"is_hidden": true, "is_legendary": false, "description": nil},
+			want: "INSERT INTO pokemon (description, is_hidden, is_legendary) VALUES (NULL, TRUE, FALSE);",
+		},
+		{
+			name: "inserts nested values as JSON text",
+			stub: map[string]any{"stats": []any{map[string]any{"base_stat": 1}}},
+			want: `INSERT INTO pokemon (stats) VALUES ('[{"base_stat":1}]');`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := GenerateInsert("pokemon", tc.stub); got != tc.want {
+				t.Errorf("expected\n%s\ngot\n%s", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package sqlexport
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Drivers are the database/sql driver names Seed supports. Each is compiled
+// into the CLI only with the build tag of the same name.
+var Drivers = []string{"mysql", "postgres", "sqlite3"}
+
+// Seed inserts each stub as a row of table in one transaction, which is
+// rolled back if any insert fails. driver selects the placeholder syntax.
+func Seed(ctx context.Context, db *sql.DB, driver, table string, stubs []map[string]any) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for i, stub := range stubs {
+		query, args := parameterizedInsert(driver, table, stub)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("inserting stub %d: %w", i+1, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// parameterizedInsert is GenerateInsert with the values passed as arguments
+// instead of literals.
+func parameterizedInsert(driver, table string, stub map[string]any) (string, []any) {
+	columns := slices.Sorted(maps.Keys(stub))
+	placeholders := make([]string, len(columns))
+	args := make([]any, len(columns))
+	for i, c := range columns {
+		placeholders[i] = "?"
+		if driver == "postgres" {
+			placeholders[i] = "$" + strconv.Itoa(i+1)
+		}
+		args[i] = arg(stub[c])
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	return query, args
+}
+
+// arg returns the query argument for a stub value; like literal, nested
+// objects and arrays are passed as JSON text.
+func arg(v any) any {
+	switch v.(type) {
+	case nil, bool, int, float64, string:
+		return v
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
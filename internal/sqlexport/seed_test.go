@@ -0,0 +1,121 @@
+package sqlexport
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder is a database/sql driver that records the statements executed
+// through it, failing those containing "fail".
+type recorder struct {
+	mu       sync.Mutex
+	log      []string
+	lastArgs []driver.Value
+}
+
+func (r *recorder) record(s string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.log = append(r.log, s)
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return &recorderConn{r}, nil }
+
+type recorderConn struct{ r *recorder }
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return &recorderStmt{c.r, query}, nil
+}
+func (c *recorderConn) Close() error { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error) {
+	c.r.record("BEGIN")
+	return recorderTx{c.r}, nil
+}
+
+type recorderTx struct{ r *recorder }
+
+func (tx recorderTx) Commit() error   { tx.r.record("COMMIT"); return nil }
+func (tx recorderTx) Rollback() error { tx.r.record("ROLLBACK"); return nil }
+
+type recorderStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recorderStmt) Close() error  { return nil }
+func (s *recorderStmt) NumInput() int { return -1 }
+func (s *recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errors.New("no such table")
+	}
+	s.r.record(s.query)
+	s.r.lastArgs = args
+	return driver.RowsAffected(1), nil
+}
+func (s *recorderStmt) Query([]driver.Value) (driver.Rows, error) { return nil, io.EOF }
+
+func openRecorder(t *testing.T) (*sql.DB, *recorder) {
+	t.Helper()
+	r := &recorder{}
+	name := "recorder-" + t.Name()
+	sql.Register(name, r)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, r
+}
+
+func TestSeed(t *testing.T) {
+	stubs := []map[string]any{
+		{"name": "pikachu", "height": 4},
+		{"name": "eevee", "height": 3, "stats": []any{map[string]any{"base_stat": 55}}},
+	}
+
+	t.Run("inserts every stub in one transaction", func(t *testing.T) {
+		db, r := openRecorder(t)
+		if err := Seed(context.Background(), db, "sqlite3", "pokemon", stubs); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"BEGIN",
+			"INSERT INTO pokemon (height, name) VALUES (?, ?)",
+			"INSERT INTO pokemon (height, name, stats) VALUES (?, ?, ?)",
+			"COMMIT",
+		}
+		if !reflect.DeepEqual(r.log, want) {
+			t.Errorf("got %q, want %q", r.log, want)
+		}
+		if want := []driver.Value{int64(3), "eevee", `[{"base_stat":55}]`}; !reflect.DeepEqual(r.lastArgs, want) {
+			t.Errorf("args: got %v, want %v", r.lastArgs, want)
+		}
+	})
+
+	t.Run("numbers postgres placeholders", func(t *testing.T) {
+		db, r := openRecorder(t)
+		if err := Seed(context.Background(), db, "postgres", "pokemon", stubs[:1]); err != nil {
+			t.Fatal(err)
+		}
+		if r.log[1] != "INSERT INTO pokemon (height, name) VALUES ($1, $2)" {
+			t.Errorf("got %q", r.log[1])
+		}
+	})
+
+	t.Run("rolls back when an insert fails", func(t *testing.T) {
+		db, r := openRecorder(t)
+		if err := Seed(context.Background(), db, "sqlite3", "fail", stubs); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if want := []string{"BEGIN", "ROLLBACK"}; !reflect.DeepEqual(r.log, want) {
+			t.Errorf("got %q, want %q", r.log, want)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package stubdiff
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Kind classifies a structural difference between two stubs.
+type Kind string
+
+const (
+	// Missing means a key present in the expected stub is absent from the actual one.
+	Missing Kind = "missing"
+	// Extra means a key present in the actual stub is absent from the expected one.
+	Extra Kind = "extra"
+	// TypeChanged means both stubs have the key but with incompatible JSON types.
+	TypeChanged Kind = "type changed"
+)
+
+// Difference describes a single structural divergence at a dot-path.
+// Array elements are addressed with an "items" segment, matching the paths
+// used in overrides files.
+type Difference struct {
+	Kind Kind
+	Path string
+	Want string
+	Got  string
+}
+
+func (d Difference) String() string {
+	if d.Kind == TypeChanged {
+		return fmt.Sprintf("%s: %s (want %s, got %s)", d.Kind, d.Path, d.Want, d.Got)
+	}
+	return fmt.Sprintf("%s: %s", d.Kind, d.Path)
+}
+
+// kindOf returns the JSON type of a decoded or generated stub value.
+func kindOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// Compare diffs two stubs by shape rather than by value: keys must match and
+// values must share a JSON type. Integers and floats are both numbers, null
+// is compatible with any type, and array lengths are ignored, so differences
+// caused purely by random generation are not reported.
+func Compare(want, got any) []Difference {
+	return compare(want, got, "")
+}
+
+func compare(want, got any, path string) []Difference {
+	wantKind, gotKind := kindOf(want), kindOf(got)
+	if wantKind == "null" || gotKind == "null" {
+		return nil
+	}
+	if wantKind != gotKind {
+		return []Difference{{Kind: TypeChanged, Path: path, Want: wantKind, Got: gotKind}}
+	}
+
+	var diffs []Difference
+	switch w := want.(type) {
+	case map[string]any:
+		g := got.(map[string]any)
+		for _, key := range slices.Sorted(maps.Keys(w)) {
+			childPath := join(path, key)
+			if _, ok := g[key]; !ok {
+				diffs = append(diffs, Difference{Kind: Missing, Path: childPath})
+				continue
+			}
+			diffs = append(diffs, compare(w[key], g[key], childPath)...)
+		}
+		for _, key := range slices.Sorted(maps.Keys(g)) {
+			if _, ok := w[key]; !ok {
+				diffs = append(diffs, Difference{Kind: Extra, Path: join(path, key)})
+			}
+		}
+	case []any:
+		g := got.([]any)
+		seen := map[string]bool{}
+		for i := range min(len(w), len(g)) {
+			for _, d := range compare(w[i], g[i], join(path, "items")) {
+				if !seen[d.String()] {
+					seen[d.String()] = true
+					diffs = append(diffs, d)
+				}
+			}
+		}
+	}
+	return diffs
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
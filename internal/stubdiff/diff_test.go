@@ -0,0 +1,69 @@
+package stubdiff
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("decoding %s: %v", s, err)
+	}
+	return v
+}
+
+func TestCompare(t *testing.T) {
+	t.Run("reports nothing for stubs that differ only in values", func(t *testing.T) {
+		want := decode(t, `{"data":{"name":"azure-blaze","height":12,"rate":1.5,"tags":[{"id":1}]}}`)
+		got := decode(t, `{"data":{"name":"mist-pine","height":200,"rate":0.3,"tags":[{"id":7},{"id":9}]}}`)
+		if diffs := Compare(want, got); len(diffs) != 0 {
+			t.Errorf("expected no differences, got %v", diffs)
+		}
+	})
+
+	t.Run("treats generated ints and decoded floats as the same type", func(t *testing.T) {
+		want := decode(t, `{"height":12}`)
+		got := map[string]any{"height": 3}
+		if diffs := Compare(want, got); len(diffs) != 0 {
+			t.Errorf("expected no differences, got %v", diffs)
+		}
+	})
+
+	t.Run("treats null as compatible with any type", func(t *testing.T) {
+		want := decode(t, `{"name":null}`)
+		got := decode(t, `{"name":"azure-blaze"}`)
+		if diffs := Compare(want, got); len(diffs) != 0 {
+			t.Errorf("expected no differences, got %v", diffs)
+		}
+	})
+
+	t.Run("lists missing keys, extra keys, and type changes", func(t *testing.T) {
+		want := decode(t, `{"data":{"name":"a","weight":1,"stats":[{"base_stat":1}]}}`)
+		got := decode(t, `{"data":{"weight":"heavy","height":2,"stats":[{"base_stat":true}]}}`)
+		diffs := Compare(want, got)
+		expected := []Difference{
+			{Kind: Missing, Path: "data.name"},
+			{Kind: TypeChanged, Path: "data.stats.items.base_stat", Want: "number", Got: "boolean"},
+			{Kind: TypeChanged, Path: "data.weight", Want: "number", Got: "string"},
+			{Kind: Extra, Path: "data.height"},
+		}
+		if len(diffs) != len(expected) {
+			t.Fatalf("expected %d differences, got %v", len(expected), diffs)
+		}
+		for i := range expected {
+			if diffs[i] != expected[i] {
+				t.Errorf("difference %d: got %v, want %v", i, diffs[i], expected[i])
+			}
+		}
+	})
+
+	t.Run("reports a repeated array item difference once", func(t *testing.T) {
+		want := decode(t, `{"stats":[{"id":1},{"id":2}]}`)
+		got := decode(t, `{"stats":[{"id":"a"},{"id":"b"}]}`)
+		if diffs := Compare(want, got); len(diffs) != 1 {
+			t.Errorf("expected 1 difference, got %v", diffs)
+		}
+	})
+}
@@ -0,0 +1,249 @@
+// Package stubformat writes the stubs generated in one run in the output
+// formats the stub command supports. Every format is written from the same
+// stubs, so outputs requested together always agree.
+package stubformat
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/harexport"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/openapiexamples"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/sqlexport"
+	"github.com/ohdyno/generate-graphql-query-stubs/internal/wiremockexport"
+	"gopkg.in/yaml.v3"
+)
+
+// Formatter writes stubs in one output format.
+type Formatter interface {
+	Format(stubs []any, w io.Writer) error
+}
+
+// Stub is a generated object stub. A schema's root need not be an object, so
+// formatters take stubs of any JSON type; those that need objects, such as
+// SQL, type-assert them to Stub.
+type Stub map[string]any
+
+// Wrap returns stubs with every object converted to a Stub.
+func Wrap(stubs []any) []any {
+	wrapped := make([]any, len(stubs))
+	for i, stub := range stubs {
+		if object, ok := stub.(map[string]any); ok {
+			stub = Stub(object)
+		}
+		wrapped[i] = stub
+	}
+	return wrapped
+}
+
+// asStub type-asserts stub to a Stub, accepting an object that was not
+// passed through Wrap too.
+func asStub(stub any) (Stub, bool) {
+	switch s := stub.(type) {
+	case Stub:
+		return s, true
+	case map[string]any:
+		return s, true
+	}
+	return nil, false
+}
+
+// JSON writes a single stub as indented JSON, and several as a JSON array.
+type JSON struct{}
+
+// Format implements Formatter.
+func (JSON) Format(stubs []any, w io.Writer) error {
+	return writeJSON(single(stubs), w)
+}
+
+// YAML writes a single stub as a YAML document, and several as a sequence.
+type YAML struct{}
+
+// Format implements Formatter.
+func (YAML) Format(stubs []any, w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(single(stubs)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// SQL writes one INSERT statement into Table per stub. Each stub must be an
+// object.
+type SQL struct {
+	Table string
+}
+
+// Format implements Formatter.
+func (s SQL) Format(stubs []any, w io.Writer) error {
+	if s.Table == "" {
+		return errors.New("sql output needs a table name")
+	}
+	for _, stub := range stubs {
+		row, ok := asStub(stub)
+		if !ok {
+			return fmt.Errorf("sql output needs object stubs, got %T", stub)
+		}
+		if _, err := fmt.Fprintln(w, sqlexport.GenerateInsert(s.Table, row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SQLSeed inserts one row per stub into Table of a database, through the
+// database/sql Driver connected to DSN, and writes a summary. With DryRun it
+// writes the INSERT statements instead, like SQL. Each stub must be an object.
+type SQLSeed struct {
+	Table  string
+	Driver string
+	DSN    string
+	DryRun bool
+}
+
+// Format implements Formatter.
+func (s SQLSeed) Format(stubs []any, w io.Writer) error {
+	if s.DryRun {
+		return SQL{Table: s.Table}.Format(stubs, w)
+	}
+	if s.Table == "" {
+		return errors.New("sql-seed output needs a table name")
+	}
+	if !slices.Contains(sqlexport.Drivers, s.Driver) {
+		return fmt.Errorf("unsupported database driver %q (want %s)", s.Driver, strings.Join(sqlexport.Drivers, ", "))
+	}
+	if !slices.Contains(sql.Drivers(), s.Driver) {
+		return fmt.Errorf("database driver %q is not compiled in; build with -tags %s", s.Driver, s.Driver)
+	}
+	rows := make([]map[string]any, len(stubs))
+	for i, stub := range stubs {
+		row, ok := asStub(stub)
+		if !ok {
+			return fmt.Errorf("sql-seed output needs object stubs, got %T", stub)
+		}
+		rows[i] = row
+	}
+
+	db, err := sql.Open(s.Driver, s.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := sqlexport.Seed(context.Background(), db, s.Driver, s.Table, rows); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "inserted %d rows into %s\n", len(rows), s.Table)
+	return err
+}
+
+// WireMock writes a WireMock mapping for a single stub, and a mappings file
+// for several. Mappings match requests for OperationName, or every request
+// when it is empty.
+type WireMock struct {
+	OperationName string
+}
+
+// Format implements Formatter.
+func (m WireMock) Format(stubs []any, w io.Writer) error {
+	mappings := make([]wiremockexport.Mapping, len(stubs))
+	for i, stub := range stubs {
+		mapping, err := wiremockexport.NewMapping(stub, m.OperationName)
+		if err != nil {
+			return err
+		}
+		mappings[i] = mapping
+	}
+	if len(mappings) == 1 {
+		return writeJSON(mappings[0], w)
+	}
+	return writeJSON(wiremockexport.Mappings{Mappings: mappings}, w)
+}
+
+// HAR writes the stubs as a HAR document with one entry per stub, recording
+// a GraphQL POST for OperationName, when set, that the stub answered. Entries
+// are stamped with Started, or the Unix epoch when it is zero.
+type HAR struct {
+	OperationName string
+	Started       time.Time
+}
+
+// Format implements Formatter.
+func (h HAR) Format(stubs []any, w io.Writer) error {
+	started := h.Started
+	if started.IsZero() {
+		started = time.Unix(0, 0)
+	}
+	har, err := harexport.New(stubs, h.OperationName, started)
+	if err != nil {
+		return err
+	}
+	return writeJSON(har, w)
+}
+
+// OpenAPIExamples writes the stubs as an OpenAPI examples object, one example
+// per stub. Examples are named by Names, or Example1, Example2 and so on when
+// it is empty; summaries mention OperationName when it is set.
+type OpenAPIExamples struct {
+	Names         []string
+	OperationName string
+}
+
+// Format implements Formatter.
+func (o OpenAPIExamples) Format(stubs []any, w io.Writer) error {
+	examples, err := openapiexamples.New(stubs, o.Names, o.OperationName)
+	if err != nil {
+		return err
+	}
+	return writeJSON(examples, w)
+}
+
+// single unwraps a lone stub so it is not written as a one-element list.
+func single(stubs []any) any {
+	if len(stubs) == 1 {
+		return stubs[0]
+	}
+	return stubs
+}
+
+func writeJSON(v any, w io.Writer) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// formatters maps format names to their Formatter.
+var formatters = map[string]Formatter{
+	"json":             JSON{},
+	"yaml":             YAML{},
+	"sql":              SQL{},
+	"sql-seed":         SQLSeed{},
+	"wiremock":         WireMock{},
+	"har":              HAR{},
+	"openapi-examples": OpenAPIExamples{},
+}
+
+// Lookup returns the Formatter for a format name.
+func Lookup(name string) (Formatter, error) {
+	f, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %q (want %s)", name, strings.Join(Names(), ", "))
+	}
+	return f, nil
+}
+
+// Names returns the supported format names, sorted.
+func Names() []string {
+	return slices.Sorted(maps.Keys(formatters))
+}
@@ -0,0 +1,108 @@
+package stubformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFormatters(t *testing.T) {
+	stubs := []any{map[string]any{"name": "pikachu", "height": 4, "types": []any{"electric"}}}
+
+	format := func(t *testing.T, f Formatter, stubs []any) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := f.Format(stubs, &buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	t.Run("JSON and YAML represent the same data", func(t *testing.T) {
+		var fromJSON, fromYAML any
+		if err := json.Unmarshal([]byte(format(t, JSON{}, stubs)), &fromJSON); err != nil {
+			t.Fatal(err)
+		}
+		if err := yaml.Unmarshal([]byte(format(t, YAML{}, stubs)), &fromYAML); err != nil {
+			t.Fatal(err)
+		}
+		// YAML decodes integers as int; normalise through JSON to compare.
+		normalised, _ := json.Marshal(fromYAML)
+		var want any
+		_ = json.Unmarshal(normalised, &want)
+		if !reflect.DeepEqual(fromJSON, want) {
+			t.Errorf("JSON %v differs from YAML %v", fromJSON, want)
+		}
+	})
+
+	t.Run("writes several stubs as a list", func(t *testing.T) {
+		var list []any
+		if err := json.Unmarshal([]byte(format(t, JSON{}, append(stubs, stubs[0]))), &list); err != nil || len(list) != 2 {
+			t.Errorf("expected a two-element array, got %v, %v", list, err)
+		}
+	})
+
+	t.Run("writes one INSERT per stub", func(t *testing.T) {
+		out := format(t, SQL{Table: "pokemon"}, append(stubs, stubs[0]))
+		if n := strings.Count(out, "INSERT INTO pokemon "); n != 2 {
+			t.Errorf("expected 2 statements, got %q", out)
+		}
+		if err := (SQL{}).Format(stubs, &bytes.Buffer{}); err == nil {
+			t.Error("expected an error without a table")
+		}
+	})
+
+	t.Run("prints the SQL of a dry-run seed", func(t *testing.T) {
+		out := format(t, SQLSeed{Table: "pokemon", Driver: "sqlite3", DryRun: true}, stubs)
+		if !strings.HasPrefix(out, "INSERT INTO pokemon ") {
+			t.Errorf("expected an INSERT statement, got %q", out)
+		}
+	})
+
+	t.Run("rejects seeding through unknown or missing drivers", func(t *testing.T) {
+		for _, driver := range []string{"oracle", "sqlite3"} {
+			err := (SQLSeed{Table: "pokemon", Driver: driver}).Format(stubs, &bytes.Buffer{})
+			if err == nil {
+				t.Errorf("%s: expected an error", driver)
+			}
+		}
+	})
+
+	t.Run("formats wrapped stubs like plain objects", func(t *testing.T) {
+		wrapped := Wrap(append(stubs, "not an object"))
+		if _, ok := wrapped[0].(Stub); !ok {
+			t.Errorf("expected an object to be wrapped as a Stub, got %T", wrapped[0])
+		}
+		if wrapped[1] != "not an object" {
+			t.Errorf("expected other values to be kept, got %v", wrapped[1])
+		}
+		for _, f := range []Formatter{JSON{}, YAML{}, SQL{Table: "pokemon"}, WireMock{}} {
+			if got, want := format(t, f, wrapped[:1]), format(t, f, stubs); got != want {
+				t.Errorf("%T: got %q, want %q", f, got, want)
+			}
+		}
+	})
+
+	t.Run("writes a mappings file for several WireMock stubs", func(t *testing.T) {
+		var file struct {
+			Mappings []any `json:"mappings"`
+		}
+		if err := json.Unmarshal([]byte(format(t, WireMock{}, append(stubs, stubs[0]))), &file); err != nil || len(file.Mappings) != 2 {
+			t.Errorf("expected two mappings, got %v, %v", file, err)
+		}
+	})
+}
+
+func TestLookup(t *testing.T) {
+	if _, err := Lookup("yaml"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Lookup("xml")
+	if err == nil || !strings.Contains(err.Error(), "har, json, openapi-examples, sql, sql-seed, wiremock, yaml") {
+		t.Errorf("expected supported formats in error, got %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package stubtemplate
+
+import (
+	"io"
+	"text/template"
+)
+
+// Render executes tmpl against a generated stub, which templates refer to as .Data.
+func Render(tmpl *template.Template, stub any, w io.Writer) error {
+	return tmpl.Execute(w, struct{ Data any }{Data: stub})
+}
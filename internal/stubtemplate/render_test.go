@@ -0,0 +1,27 @@
+package stubtemplate
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRender(t *testing.T) {
+	t.Run("exposes the stub as .Data", func(t *testing.T) {
+		tmpl := template.Must(template.New("t").Parse(`{{.Data.name}} is {{.Data.height}}m`))
+		var out strings.Builder
+		if err := Render(tmpl, map[string]any{"name": "azure-blaze", "height": 7}, &out); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != "azure-blaze is 7m" {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("returns template execution errors", func(t *testing.T) {
+		tmpl := template.Must(template.New("t").Option("missingkey=error").Parse(`{{.Data.missing}}`))
+		if err := Render(tmpl, map[string]any{}, &strings.Builder{}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+// Package wiremockexport renders stubs as WireMock stub mappings, so a
+// WireMock server can answer GraphQL requests with them.
+package wiremockexport
+
+import "encoding/json"
+
+// Endpoint is the URL path the mappings match GraphQL requests on.
+const Endpoint = "/graphql"
+
+// Mapping is a WireMock stub mapping.
+type Mapping struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request matches the GraphQL requests a mapping answers.
+type Request struct {
+	Method       string        `json:"method"`
+	URLPath      string        `json:"urlPath"`
+	BodyPatterns []BodyPattern `json:"bodyPatterns,omitempty"`
+}
+
+// BodyPattern matches the value at a JSON path in the request body.
+type BodyPattern struct {
+	MatchesJSONPath JSONPathMatch `json:"matchesJsonPath"`
+}
+
+// JSONPathMatch requires the value at Expression to equal EqualTo.
+type JSONPathMatch struct {
+	Expression string `json:"expression"`
+	EqualTo    string `json:"equalTo"`
+}
+
+// Response is the canned response of a mapping.
+type Response struct {
+	Status  int               `json:"status"`
+	Body    string            `json:"body"`
+	Headers map[string]string `json:"headers"`
+}
+
+// Mappings is a WireMock mappings file holding several mappings.
+type Mappings struct {
+	Mappings []Mapping `json:"mappings"`
+}
+
+// NewMapping returns a mapping answering POSTs to Endpoint with stub as the
+// JSON body. When operationName is not empty, only requests whose
+// "operationName" equals it are matched.
+func NewMapping(stub any, operationName string) (Mapping, error) {
+	body, err := json.Marshal(stub)
+	if err != nil {
+		return Mapping{}, err
+	}
+	m := Mapping{
+		Request: Request{Method: "POST", URLPath: Endpoint},
+		Response: Response{
+			Status:  200,
+			Body:    string(body),
+			Headers: map[string]string{"Content-Type": "application/json"},
+		},
+	}
+	if operationName != "" {
+		m.Request.BodyPatterns = []BodyPattern{{
+			MatchesJSONPath: JSONPathMatch{Expression: "$.operationName", EqualTo: operationName},
+		}}
+	}
+	return m, nil
+}
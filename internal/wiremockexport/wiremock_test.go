@@ -0,0 +1,61 @@
+package wiremockexport
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewMapping(t *testing.T) {
+	stub := map[string]any{"data": map[string]any{"pokemon": map[string]any{"name": "pikachu"}}}
+
+	decode := func(t *testing.T, operationName string) map[string]any {
+		t.Helper()
+		m, err := NewMapping(stub, operationName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mapping map[string]any
+		if err := json.Unmarshal(out, &mapping); err != nil {
+			t.Fatal(err)
+		}
+		return mapping
+	}
+
+	t.Run("has the required WireMock keys", func(t *testing.T) {
+		mapping := decode(t, "GetPokemon")
+		request := mapping["request"].(map[string]any)
+		if request["method"] != "POST" || request["urlPath"] != "/graphql" {
+			t.Errorf("unexpected request %v", request)
+		}
+		pattern := request["bodyPatterns"].([]any)[0].(map[string]any)["matchesJsonPath"].(map[string]any)
+		if pattern["expression"] != "$.operationName" || pattern["equalTo"] != "GetPokemon" {
+			t.Errorf("unexpected body pattern %v", pattern)
+		}
+
+		response := mapping["response"].(map[string]any)
+		if response["status"] != float64(200) {
+			t.Errorf("status: got %v", response["status"])
+		}
+		if response["headers"].(map[string]any)["Content-Type"] != "application/json" {
+			t.Errorf("headers: got %v", response["headers"])
+		}
+		var body map[string]any
+		if err := json.Unmarshal([]byte(response["body"].(string)), &body); err != nil {
+			t.Fatalf("body is not JSON-encoded: %v", err)
+		}
+		if body["data"] == nil {
+			t.Errorf("expected the stub as body, got %v", body)
+		}
+	})
+
+	t.Run("matches every request without an operation name", func(t *testing.T) {
+		request := decode(t, "")["request"].(map[string]any)
+		if _, ok := request["bodyPatterns"]; ok {
+			t.Errorf("expected no body patterns, got %v", request["bodyPatterns"])
+		}
+	})
+}